@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+)
+
+// concurrencyCooldown is the minimum time between two concurrency reductions,
+// so a burst of errors from uploads already in flight only counts once
+const concurrencyCooldown = 5 * time.Second
+
+// concurrencyLimiter adapts the number of in-flight uploads to VTEX's health:
+// it halves the limit when throttling or server errors appear and raises it
+// by one after a full round of healthy uploads, never above the configured
+// maximum
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	max      int
+	inFlight int
+
+	healthy       int   // healthy uploads since the limit last changed
+	seenCongested int64 // congested responses already acted upon
+	lastDecrease  time.Time
+}
+
+// newConcurrencyLimiter creates a limiter starting at max in-flight uploads
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	_, congested := client.DefaultHealthMonitor.Snapshot()
+	l := &concurrencyLimiter{limit: max, max: max, seenCongested: congested}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire waits until another upload may start; a nil limiter never waits
+func (l *concurrencyLimiter) acquire() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+// release marks an upload as finished and adjusts the limit from the
+// responses observed since the last adjustment
+func (l *concurrencyLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+
+	_, congested := client.DefaultHealthMonitor.Snapshot()
+	switch {
+	case congested > l.seenCongested:
+		l.seenCongested = congested
+		l.healthy = 0
+		if l.limit > 1 && time.Since(l.lastDecrease) >= concurrencyCooldown {
+			l.limit = max(1, l.limit/2)
+			l.lastDecrease = time.Now()
+			color.Yellow(i18n.T("VTEX is throttling or failing requests: reducing concurrency to %d"), l.limit)
+		}
+	case l.limit < l.max:
+		l.healthy++
+		if l.healthy >= l.limit {
+			l.healthy = 0
+			l.limit++
+			slog.Info("requests are healthy, raising concurrency", "concurrency", l.limit)
+		}
+	}
+
+	l.cond.Broadcast()
+}
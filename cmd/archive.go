@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// isZipArchive reports whether a batch source is a zip file
+func isZipArchive(source string) bool {
+	if !strings.EqualFold(filepath.Ext(source), ".zip") {
+		return false
+	}
+	info, err := os.Stat(source)
+	return err == nil && !info.IsDir()
+}
+
+// extractArchive extracts the supported files of a zip archive into a
+// temporary staging directory, keeping its folder structure. It returns the
+// directory to upload from, which is the archive's single top-level folder
+// when it has one, and the staging directory to remove afterwards. macOS
+// metadata, hidden files and entries escaping the archive root are skipped.
+func extractArchive(archivePath string) (string, string, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	stagingDir, err := os.MkdirTemp("", "vfm-archive-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	count := 0
+	for _, entry := range reader.File {
+		name := path.Clean(strings.ReplaceAll(entry.Name, `\`, "/"))
+		if entry.FileInfo().IsDir() || !isArchiveFileWanted(name) {
+			continue
+		}
+		if limit := client.FileSizeLimit(); entry.UncompressedSize64 > uint64(limit) {
+			color.Yellow("Skipping %s: larger than %s", name, client.FormatMB(limit))
+			continue
+		}
+
+		if err := extractArchiveEntry(entry, filepath.Join(stagingDir, filepath.FromSlash(name))); err != nil {
+			os.RemoveAll(stagingDir)
+			return "", "", fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		count++
+	}
+
+	slog.Debug("extracted archive", "files", count, "dir", stagingDir)
+
+	// Asset packs are often zipped as a single folder
+	root := stagingDir
+	if entries, err := os.ReadDir(stagingDir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(stagingDir, entries[0].Name())
+	}
+	return root, stagingDir, nil
+}
+
+// isArchiveFileWanted reports whether an archive entry should be extracted
+func isArchiveFileWanted(name string) bool {
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return client.ExtensionAllowed("", path.Ext(name))
+}
+
+// extractArchiveEntry writes a single archive entry to dest
+func extractArchiveEntry(entry *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// The declared size can lie; never write more than the upload limit
+	limit := client.FileSizeLimit()
+	written, err := io.Copy(out, io.LimitReader(src, limit+1))
+	if err != nil {
+		return err
+	}
+	if written > limit {
+		return fmt.Errorf("larger than %s", client.FormatMB(limit))
+	}
+	return out.Close()
+}
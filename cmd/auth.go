@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/credentials"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage App Key credentials stored in the OS keyring",
+	Long: `Store VTEX App Key/App Token pairs in the OS keyring (macOS Keychain,
+Windows Credential Manager or the Secret Service on Linux) instead of
+plaintext files or environment variables.
+
+Stored credentials are used whenever --account (or a profile) selects an
+account that has them, unless --app-key/--app-token are given.
+
+Examples:
+  vfm auth login --account mystore --app-key vtexappkey-mystore-ABCDEF
+  vfm auth list
+  vfm auth logout mystore`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store an App Key and App Token for an account",
+	Long: `Store an App Key and App Token for the account given by --account.
+
+The App Token is read from VTEX_APP_TOKEN or --app-token when set, otherwise
+it is prompted for without echo (or read from stdin when it is not a terminal).
+The credentials are checked with VTEX ID before they are stored.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogin,
+}
+
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List accounts with stored credentials",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthList,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout <account>",
+	Short: "Remove the credentials stored for an account",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAuthLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authLogoutCmd)
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	account := firstNonEmpty(sessionAccount, os.Getenv("VTEX_ACCOUNT"))
+	key := firstNonEmpty(appKey, os.Getenv("VTEX_APP_KEY"))
+	if account == "" || key == "" {
+		return fmt.Errorf("--account and --app-key are required")
+	}
+
+	token := firstNonEmpty(appToken, os.Getenv("VTEX_APP_TOKEN"))
+	if token == "" {
+		var err error
+		if token, err = readSecret("App Token: "); err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		return fmt.Errorf("no App Token given")
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), sessionCheckTimeout)
+	defer cancel()
+	if err := client.VerifyAppKey(ctx, account, key, token); err != nil {
+		if errors.Is(err, client.ErrAuthFailed) {
+			return fmt.Errorf("VTEX rejected App Key %s for account %s: %w", key, account, err)
+		}
+		color.Yellow(symbols("⚠️  Could not verify the credentials: %v"), err)
+	}
+
+	if err := credentials.Save(account, key, token); err != nil {
+		return err
+	}
+	color.Green(symbols("✓ Stored App Key %s for %s in the OS keyring"), key, account)
+	return nil
+}
+
+func runAuthList(cmd *cobra.Command, args []string) error {
+	stored, err := credentials.List()
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		fmt.Println("No stored credentials. Add some with 'vfm auth login'.")
+		return nil
+	}
+
+	for _, s := range stored {
+		fmt.Printf("%-24s %-40s saved %s\n", s.Account, s.AppKey, s.SavedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	if err := credentials.Delete(args[0]); err != nil {
+		return err
+	}
+	color.Green(symbols("✓ Removed the credentials stored for %s"), args[0])
+	return nil
+}
+
+// readSecret prompts for a secret without echoing it, or reads a line from
+// stdin when it is not a terminal
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
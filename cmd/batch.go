@@ -1,28 +1,89 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/fatih/color"
 	"github.com/glinharesb/vtex-files-manager/pkg/auth"
 	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+	"github.com/glinharesb/vtex-files-manager/pkg/metrics"
+	"github.com/glinharesb/vtex-files-manager/pkg/receipt"
+	"github.com/glinharesb/vtex-files-manager/pkg/source"
+	"github.com/glinharesb/vtex-files-manager/pkg/state"
 	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 var (
-	concurrency      int
-	recursive        bool
-	batchMethod      string
-	batchSkipConfirm bool
+	concurrency        int
+	recursive          bool
+	batchMethod        string
+	batchSkipConfirm   bool
+	batchMappings      []string
+	batchDryRun        bool
+	batchOnConflict    string
+	batchIfExists      string
+	batchFailFast      bool
+	batchManifest      string
+	batchExcludes      []string
+	batchReceipt       string
+	batchExtensions    []string
+	batchPreserveDir   bool
+	batchDirSep        string
+	batchRehearse      string
+	batchFileType      string
+	batchBucket        string
+	batchSlugify       bool
+	batchVersion       string
+	batchHashNames     bool
+	batchHashOutput    string
+	batchOnDuplicate   string
+	batchIncremental   bool
+	batchSkipSame      bool
+	batchVerify        bool
+	batchPurgeList     string
+	batchResume        bool
+	batchReport        string
+	batchNotifyURL     string
+	batchDesktopNotify bool
+	batchOutput        string
+	batchPorcelain     string
+	batchFormat        string
+	batchRewriteRefs   bool
+	batchEmitManifest  string
+	batchSnippet       string
+	batchOptimize      string
+	batchConvert       string
+	batchResize        string
+	batchMaxWidth      int
+	batchMinify        bool
+	batchAdaptive      bool
 )
 
+// batchFile is a local file queued for upload together with its remote name
+type batchFile struct {
+	Path       string
+	RelPath    string // path relative to the batch directory
+	RemoteName string
+	Method     string
+}
+
 var batchCmd = &cobra.Command{
-	Use:   "batch [directory]",
+	Use:   "batch [directory|glob|archive.zip|shared-link|s3://bucket/prefix|gs://bucket/prefix|az://container/prefix|-] | --manifest file.csv",
 	Short: "Upload multiple files from a directory",
 	Long: `Upload all image files from a directory to your VTEX account.
 
@@ -32,129 +93,670 @@ Authentication:
 Supported file types:
   - Universal (both methods): jpg, jpeg, png, gif, svg, webp
   - CMS only: bmp, pdf, txt, json, xml, css, js
-Maximum file size: 5MB per file
+Maximum file size: 5MB per file (change it with --max-file-size)
 
 Upload Methods:
   graphql: Official GraphQL API - URLs: account.vtexassets.com/assets/.../uuid___hash.ext
   cms:     Legacy CMS FilePicker - URLs: account.vtexassets.com/arquivos/filename.ext
 
-Note: You must specify the --method flag. There is no default value.
+Note: You must specify the --method flag unless a default method is set
+for the account in the config file (accounts.<account>.method).
+
+Glob patterns:
+  A quoted glob pattern can be used instead of a directory. Patterns support
+  "**" to match any number of subdirectories and are expanded by vfm itself,
+  so they behave the same on Windows and Unix shells.
+
+Standard input:
+  Use "-" to read newline-separated file paths from stdin, e.g. from find.
+  Requires --yes (or --dry-run) since the confirmation prompt also uses stdin.
+
+Excluding files:
+  --exclude skips files and directories matching a glob relative to the
+  batch directory. Patterns without a slash match at any depth, so
+  --exclude '*.psd' skips every PSD file and --exclude 'node_modules/**'
+  skips the whole tree.
+
+Subdirectories:
+  The CMS file area (/arquivos) is a single flat namespace and the FilePicker
+  offers no folder API, so recursive batches flatten files into it by default.
+  --preserve-dirs keeps the local tree in the remote name instead, e.g.
+  icons/social/x.svg is uploaded as icons_social_x.svg (see --dir-separator).
+
+Rewriting stylesheet references:
+  --rewrite-refs uploads CSS files after all other files, replacing relative
+  url(...) references to files of the same batch with their final VTEX URLs.
+  Files skipped because they are already published (--on-conflict skip,
+  --skip-identical) or unchanged (--incremental) resolve to their published
+  URLs. Local files are not modified.
+
+CDN cache:
+  Overwritten CMS files keep their URL and may be served from the CDN cache
+  for a while. --purge-list writes their URLs to a file for purge tooling.
+
+Rehearsal:
+  --rehearse <workspace> performs the real uploads against a disposable
+  target and prints the same report as a production run. GraphQL uploads go
+  to the workspace; CMS files are not workspace-scoped, so CMS uploads go to
+  the staging account set in accounts.<account>.rehearsalAccount.
+
+Manifest:
+  --manifest reads a CSV file with the columns path, method and name instead
+  of a directory. Only path is required; method overrides the default upload
+  method and name sets the destination file name for that row. The manifest
+  is checked before VTEX is contacted, and errors name the line of the file.
+
+Shared links:
+  Google Drive and Dropbox file or folder links can be used instead of a
+  directory. Files are streamed into the batch without a manual download.
+  Credentials are read from the vfm config file (sources.googleDrive.apiKey,
+  sources.dropbox.accessToken) or from VFM_GDRIVE_API_KEY / VFM_DROPBOX_TOKEN.
+
+S3:
+  s3://bucket/prefix uploads the objects under a key prefix (or a single
+  object) of an S3 bucket, for migrations from S3-hosted asset libraries; use
+  -r to include nested prefixes. Credentials come from the AWS SDK default
+  chain (AWS_PROFILE, AWS_ACCESS_KEY_ID, ~/.aws/credentials, SSO, instance
+  roles). The profile and region can be set in the vfm config (sources.s3).
+
+Google Cloud Storage:
+  gs://bucket/prefix works the same way for Cloud Storage buckets.
+  Credentials come from Application Default Credentials
+  (GOOGLE_APPLICATION_CREDENTIALS, 'gcloud auth application-default login',
+  the metadata server on GCP), or from a service account key file set in the
+  vfm config (sources.gcs.credentialsFile).
+
+Azure Blob Storage:
+  az://container/prefix works the same way for containers of the storage
+  account set in the vfm config (sources.azure.account) or in
+  AZURE_STORAGE_ACCOUNT. Credentials come from the Azure SDK default chain
+  (AZURE_CLIENT_ID and friends, workload and managed identities, 'az login').
+
+Zip archives:
+  A .zip file is extracted to a temporary directory and its contents are
+  uploaded with the normal filters. An archive holding a single folder is
+  uploaded from inside that folder; use -r to include nested folders.
+
+Concurrency adapts to VTEX's health: -c is the maximum number of uploads in
+flight, halved when VTEX throttles (429), fails (5xx) or stalls, and raised
+by one after each healthy round. Use --adaptive=false for a fixed pace.
+
+The batch stops automatically after 3 consecutive authentication failures
+(expired session). Use --fail-fast to stop on the first failure of any kind.
+Progress is checkpointed, so an interrupted or partly failed batch can be
+continued with --resume without confirming or re-uploading finished files.
 
 Examples:
   vtex-files-manager batch ./images -m cms
   vtex-files-manager batch ./assets -m graphql -c 5 -y
   vtex-files-manager batch ./photos -m cms -r
-  vtex-files-manager batch ./images -m graphql -c 3 -v`,
-	Args: cobra.ExactArgs(1),
+  vtex-files-manager batch ./images -m graphql -c 3 -v
+  vtex-files-manager batch ./assets -m cms -r --map "icons/*=icn-" --map "banners/*=bnr-"
+  vtex-files-manager batch ./images -m cms --dry-run
+  vtex-files-manager batch assets.zip -m cms -r
+  vtex-files-manager batch './images/**/*.png' -m cms
+  vtex-files-manager batch ./theme -m cms -r --exclude 'node_modules/**' --exclude '*.psd'
+  vtex-files-manager batch ./assets -m cms --ext svg
+  vtex-files-manager batch ./assets -m cms -r --preserve-dirs
+  vtex-files-manager batch ./campaign -m cms --slugify
+  vtex-files-manager batch ./dist -m cms --ext css,js --version-suffix v42
+  vtex-files-manager batch ./dist -m cms --hash-names --hash-manifest dist/assets.json
+  find . -newer marker -name '*.png' | vtex-files-manager batch - -m cms -y
+  vtex-files-manager batch --manifest assets.csv -y
+  vtex-files-manager batch ./images -m cms --on-conflict skip -y
+  vtex-files-manager batch ./campaign -m cms -r --on-duplicate skip
+  vtex-files-manager batch ./theme -m cms -r --incremental -y
+  vtex-files-manager batch ./theme -m cms -r --skip-identical -y
+  vtex-files-manager batch ./images -m graphql --fail-fast
+  vtex-files-manager batch --resume
+  vtex-files-manager batch ./images -m graphql --bucket my-app-assets
+  vtex-files-manager batch ./images -m graphql --rehearse release-check -y
+  vtex-files-manager batch ./images -m cms -y --receipt receipt.json
+  vtex-files-manager batch ./images -m cms -y --report report.json
+  vtex-files-manager batch ./images -m cms -y --notify
+  vtex-files-manager batch ./dist -m cms -r -y --emit-manifest
+  vtex-files-manager batch ./images -m cms -y -o markdown
+  vtex-files-manager batch ./banners -m cms -y --snippet picture
+  vtex-files-manager batch ./exports -m cms -y --optimize lossy:80
+  vtex-files-manager batch ./photos -m graphql -y --convert webp:75
+  vtex-files-manager batch ./camera -m cms -y --max-width 1600
+  vtex-files-manager batch ./theme -m cms -y --ext css,js --minify
+  vtex-files-manager batch ./landing -m cms -r --rewrite-refs
+  vtex-files-manager batch "https://drive.google.com/drive/folders/<id>" -m cms -r`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runBatch,
 }
 
 func init() {
 	rootCmd.AddCommand(batchCmd)
 
-	batchCmd.Flags().StringVarP(&batchMethod, "method", "m", "", "upload method: graphql or cms (required)")
-	batchCmd.Flags().IntVarP(&concurrency, "concurrent", "c", 3, "number of concurrent uploads")
+	batchCmd.Flags().StringVarP(&batchMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
+	batchCmd.Flags().IntVarP(&concurrency, "concurrent", "c", 3, "maximum number of concurrent uploads")
+	batchCmd.Flags().BoolVar(&batchAdaptive, "adaptive", true, "reduce concurrency when VTEX throttles or fails requests and raise it back when healthy")
 	batchCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "recursively search subdirectories")
 	batchCmd.Flags().BoolVarP(&batchSkipConfirm, "yes", "y", false, "skip confirmation prompt")
+	batchCmd.Flags().StringArrayVar(&batchMappings, "map", nil, "prefix remote names of files matching a glob, as \"glob=prefix\" (repeatable)")
+	batchCmd.Flags().StringVar(&batchReceipt, "receipt", "", "write a signed receipt of the uploaded files to this path")
+	batchCmd.Flags().StringArrayVar(&batchExcludes, "exclude", nil, "skip files matching a glob (repeatable)")
+	batchCmd.Flags().BoolVar(&batchPreserveDir, "preserve-dirs", false, "keep subdirectories in remote names instead of flattening them")
+	batchCmd.Flags().StringVar(&batchDirSep, "dir-separator", "_", "separator between directories in remote names (with --preserve-dirs)")
+	batchCmd.Flags().BoolVar(&batchSlugify, "slugify", false, "normalize destination names (lowercase, no accents, dashes instead of spaces)")
+	batchCmd.Flags().StringVar(&batchVersion, "version-suffix", "", "append a version tag to destination names for cache busting (\"timestamp\" for the current time)")
+	batchCmd.Flags().BoolVar(&batchHashNames, "hash-names", false, "add a content hash to destination names (name.<sha256-8>.ext)")
+	batchCmd.Flags().StringVar(&batchHashOutput, "hash-manifest", "asset-manifest.json", "where --hash-names writes the original name → URL mapping")
+	batchCmd.Flags().StringVar(&batchOnDuplicate, "on-duplicate", "warn", "what to do with files whose content repeats in the batch: warn, skip or upload")
+	batchCmd.Flags().BoolVar(&batchIncremental, "incremental", false, "only upload files that changed since the last upload (tracked in "+state.FileName+")")
+	batchCmd.Flags().BoolVar(&batchSkipSame, "skip-identical", false, "skip files whose published copy already has the same content (cms only)")
+	batchCmd.Flags().StringVar(&batchPurgeList, "purge-list", "", "write the URLs of overwritten CMS files to this path, to purge them from the CDN cache")
+	batchCmd.Flags().BoolVar(&batchVerify, "verify", false, "download each uploaded file and fail the upload if it differs from the local file")
+	batchCmd.Flags().StringVarP(&batchOutput, "output", "o", "text", "result output format: text or markdown")
+	batchCmd.Flags().StringVar(&batchPorcelain, "porcelain", "", "print stable tab-separated results on stdout for scripts (format version: v1), other output goes to stderr")
+	batchCmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	batchCmd.Flags().StringVar(&batchFormat, "format", "", "print each result with a Go template on stdout, e.g. '{{.FileName}} {{.FileURL}}'; other output goes to stderr")
+	batchCmd.Flags().StringVar(&batchOptimize, "optimize", "", "compress images before upload: lossless (PNG) or lossy[:quality] (PNG and JPEG)")
+	batchCmd.Flags().Lookup("optimize").NoOptDefVal = "lossless"
+	batchCmd.Flags().StringVar(&batchResize, "resize", "", "downscale images to fit within WIDTHxHEIGHT before upload, keeping the aspect ratio")
+	batchCmd.Flags().IntVar(&batchMaxWidth, "max-width", 0, "downscale images wider than this many pixels before upload")
+	batchCmd.Flags().BoolVar(&batchMinify, "minify", false, "minify CSS and JS files before upload")
+	batchCmd.Flags().StringVar(&batchConvert, "convert", "", "transcode jpg/png files before upload: webp[:quality] (requires cwebp; banner.jpg is uploaded as banner.webp)")
+	batchCmd.Flags().StringVar(&batchSnippet, "snippet", "", "print a ready-to-paste HTML block for each uploaded image: html (<img srcset>) or picture")
+	batchCmd.Flags().BoolVar(&batchRewriteRefs, "rewrite-refs", false, "upload stylesheets last with relative url(...) references rewritten to the uploaded URLs")
+	batchCmd.Flags().StringVar(&batchEmitManifest, "emit-manifest", "", "write a JSON manifest mapping local paths to URLs and hashes (default path vfm-manifest.json)")
+	batchCmd.Flags().Lookup("emit-manifest").NoOptDefVal = "vfm-manifest.json"
+	batchCmd.Flags().StringVar(&batchReport, "report", "", "write the per-file outcome of the batch as JSON to this path")
+	batchCmd.Flags().StringVar(&batchNotifyURL, "notify-url", "", "POST a JSON summary of the batch to this webhook URL when it completes")
+	batchCmd.Flags().BoolVar(&batchDesktopNotify, "notify", false, "show a desktop notification when the batch completes")
+	batchCmd.Flags().BoolVar(&batchResume, "resume", false, "continue the last interrupted batch")
+	batchCmd.Flags().StringVar(&batchBucket, "bucket", "", "GraphQL bucket to upload into (default from config, or images)")
+	batchCmd.Flags().StringVar(&batchFileType, "file-type", client.DefaultCMSFileType, "CMS file area (fileType): auto, images, others or files (served from /files) (cms only)")
+	batchCmd.Flags().StringVar(&batchRehearse, "rehearse", "", "perform the real uploads against this disposable workspace (or the configured rehearsal account for cms)")
+	batchCmd.Flags().StringSliceVar(&batchExtensions, "ext", nil, "only upload files with these extensions (e.g. jpg,png,svg)")
+	batchCmd.Flags().BoolVar(&batchDryRun, "dry-run", false, "show what would be uploaded without uploading")
+	batchCmd.Flags().StringVar(&batchOnConflict, "on-conflict", "overwrite", "what to do with files that already exist: skip, overwrite, rename or fail (cms only)")
+	batchCmd.Flags().StringVar(&batchIfExists, "if-exists", "", "alias of --on-conflict")
+	batchCmd.Flags().MarkDeprecated("if-exists", "use --on-conflict instead")
+	batchCmd.Flags().BoolVar(&batchFailFast, "fail-fast", false, "stop the batch on the first failed upload")
+	batchCmd.Flags().StringVar(&batchManifest, "manifest", "", "CSV file listing files to upload (columns: path, method, name)")
 }
 
 func runBatch(cmd *cobra.Command, args []string) error {
-	directory := args[0]
+	if err := validateOutputFormat(batchOutput); err != nil {
+		return err
+	}
+	if err := validateSnippetFormat(batchSnippet); err != nil {
+		return err
+	}
+	if err := validatePorcelain(batchPorcelain); err != nil {
+		return err
+	}
+	if err := validateNotifyURL(batchNotifyURL); err != nil {
+		return err
+	}
+	optimize, err := parseOptimize(batchOptimize)
+	if err != nil {
+		return err
+	}
+	convert, err := parseConvert(batchConvert)
+	if err != nil {
+		return err
+	}
+	resize, err := parseResize(batchResize, batchMaxWidth)
+	if err != nil {
+		return err
+	}
+	stage := stageOptions{Resize: resize, Convert: convert, Optimize: optimize, Minify: batchMinify}
+	if err := startFormat(batchFormat, batchPorcelain); err != nil {
+		return err
+	}
+	if batchPorcelain != "" {
+		startPorcelain()
+	}
+
+	if batchResume {
+		if len(args) > 0 || batchManifest != "" {
+			return i18n.Errorf("--resume takes no directory or --manifest")
+		}
+		return resumeBatch(cmd.Context())
+	}
+
+	// Either a directory argument or a manifest selects the files
+	if (len(args) == 0) == (batchManifest == "") {
+		return i18n.Errorf("specify either a directory argument or --manifest")
+	}
+	directory := ""
+	if len(args) == 1 {
+		directory = args[0]
+	}
+
+	var manifest []manifestEntry
+	if batchManifest != "" {
+		entries, err := readManifest(batchManifest)
+		if err != nil {
+			return err
+		}
+		manifest = entries
+	}
 
-	// Validate method is specified
-	if batchMethod == "" {
-		return fmt.Errorf("--method flag is required (must be 'graphql' or 'cms')")
+	// Validate conflict policy
+	onConflict := batchOnConflict
+	if batchIfExists != "" {
+		onConflict = batchIfExists
 	}
+	if onConflict != "skip" && onConflict != "overwrite" && onConflict != "rename" && onConflict != "fail" {
+		return i18n.Errorf("invalid --on-conflict value: %s (must be 'skip', 'overwrite', 'rename' or 'fail')", onConflict)
+	}
+	// Skip and rename resolve conflicts per file inside the upload workers
+	resolveInWorker := onConflict == "skip" || onConflict == "rename"
 
-	// Validate method value
-	if batchMethod != "graphql" && batchMethod != "cms" {
-		return fmt.Errorf("invalid method: %s (must be 'graphql' or 'cms')", batchMethod)
+	// The confirmation prompt reads stdin, so it can't be used with a stdin file list
+	readStdin := directory == "-"
+	if readStdin && !batchSkipConfirm && !batchDryRun {
+		return i18n.Errorf("reading the file list from stdin requires --yes or --dry-run")
 	}
 
-	// Load VTEX CLI session
-	session, err := vtexcli.LoadSession()
+	// Parse remote name prefix mappings
+	mappings, err := parsePrefixMappings(batchMappings)
 	if err != nil {
 		return err
 	}
 
-	// Validate token before proceeding
-	if err := session.ValidateToken(); err != nil {
-		return fmt.Errorf("authentication failed: %w. Please run 'vtex login' and try again", err)
+	// Parse exclude patterns
+	excludes, err := parseExcludePatterns(batchExcludes)
+	if err != nil {
+		return err
 	}
 
-	// Find all image files
-	files, err := findImageFiles(directory, recursive)
+	// Resolve the cache-busting suffix once so every file shares it
+	versionSuffix, err := resolveVersionSuffix(batchVersion)
 	if err != nil {
-		return fmt.Errorf("failed to find files: %w", err)
+		return err
 	}
 
-	if len(files) == 0 {
-		color.Yellow("No image files found in %s", directory)
+	// Validate duplicate handling
+	if batchOnDuplicate != "warn" && batchOnDuplicate != "skip" && batchOnDuplicate != "upload" {
+		return i18n.Errorf("invalid --on-duplicate value: %s (must be 'warn', 'skip' or 'upload')", batchOnDuplicate)
+	}
+
+	// Parse extension filter
+	extensions, err := parseExtensionFilter(batchExtensions)
+	if err != nil {
+		return err
+	}
+
+	// Resolve upload method and bucket from flags or account defaults in config;
+	// a manifest that specifies the method on every row needs no default
+	method, bucket, err := resolveUploadDefaults(batchMethod, currentAccount())
+	if err != nil && !(errors.Is(err, errMethodRequired) && manifestHasMethods(manifest)) {
+		return err
+	}
+
+	// Staged files are kept while the checkpoint of an interrupted batch
+	// refers to them
+	var stagingDir string
+	var checkpoint *batchCheckpoint
+	defer func() { removeStagingDir(stagingDir, checkpoint) }()
+
+	// Stream files from a shared link (Google Drive, Dropbox), S3, Cloud Storage or Azure into a staging directory
+	sourceLabel := directory
+	if source.IsRemote(directory) {
+		if batchIncremental {
+			return i18n.Errorf("--incremental requires a local directory")
+		}
+		downloadCtx, stop := notifyInterrupt(cmd.Context())
+		stagingDir, err = downloadRemoteSource(downloadCtx, directory, recursive)
+		stop()
+		if err != nil {
+			return err
+		}
+		directory = stagingDir
+	}
+
+	// Extract a zip archive (e.g. an asset pack) into a staging directory
+	if isZipArchive(directory) {
+		if batchIncremental {
+			return i18n.Errorf("--incremental requires a local directory")
+		}
+		var root string
+		root, stagingDir, err = extractArchive(directory)
+		if err != nil {
+			return err
+		}
+		directory = root
+	}
+
+	// Find all image files, expanding glob patterns inside the tool so
+	// selection behaves the same on every shell
+	var paths []string
+	if manifest != nil {
+		sourceLabel = batchManifest
+		directory = filepath.Dir(batchManifest)
+		for _, e := range manifest {
+			paths = append(paths, e.Path)
+		}
+	} else if readStdin {
+		sourceLabel = "(stdin)"
+		directory = "."
+		paths, err = readFileList(os.Stdin)
+	} else if isGlobPattern(directory) {
+		directory, paths, err = findGlobFiles(directory)
+	} else {
+		paths, err = findImageFiles(directory, recursive, excludes)
+	}
+	if err != nil {
+		return i18n.Errorf("failed to find files: %w", err)
+	}
+
+	// Drop excluded and filtered-out files (manifest rows are always uploaded as listed)
+	if manifest == nil {
+		kept := paths[:0]
+		for _, p := range paths {
+			if extensions != nil && !extensions[strings.ToLower(filepath.Ext(p))] {
+				continue
+			}
+			if filepath.Base(p) == state.FileName {
+				continue
+			}
+			relPath, err := filepath.Rel(directory, p)
+			if err != nil || !isExcluded(excludes, relPath) {
+				kept = append(kept, p)
+			}
+		}
+		paths = kept
+	}
+
+	if len(paths) == 0 {
+		color.Yellow(i18n.T("No image files found in %s"), sourceLabel)
 		return nil
 	}
 
+	// --hash-names hashes the transformed content, staged here while names are resolved
+	hashStagingDir := ""
+	if batchHashNames {
+		var cleanup func()
+		if hashStagingDir, cleanup, err = newStagingDir(stage); err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	// Resolve remote names and methods
+	dirSeparator := ""
+	if batchPreserveDir {
+		dirSeparator = batchDirSep
+	}
+	files := make([]batchFile, 0, len(paths))
+	var unslugged, slugs []string // names of CMS files before and after --slugify
+	hashOriginals := map[string]string{}
+	for i, p := range paths {
+		relPath, err := filepath.Rel(directory, p)
+		if err != nil {
+			relPath = filepath.Base(p)
+		}
+		f := batchFile{
+			Path:       p,
+			RelPath:    relPath,
+			RemoteName: applyPrefixMappings(mappings, relPath, dirSeparator),
+			Method:     method,
+		}
+		if manifest != nil {
+			if manifest[i].Method != "" {
+				f.Method = manifest[i].Method
+			}
+			if manifest[i].Name != "" {
+				f.RemoteName = manifest[i].Name
+			}
+		}
+		if batchSlugify {
+			if f.Method == "cms" {
+				unslugged = append(unslugged, f.RemoteName)
+				slugs = append(slugs, slugify(f.RemoteName))
+			}
+			f.RemoteName = slugify(f.RemoteName)
+		}
+		f.RemoteName = convertedName(f.Path, f.RemoteName, convert)
+		f.RemoteName = appendVersionSuffix(f.RemoteName, versionSuffix)
+		if batchHashNames {
+			original := f.RemoteName
+			if f.RemoteName, err = hashedName(f.Path, f.RemoteName, hashStagingDir, stage); err != nil {
+				return i18n.Errorf("failed to hash %s: %w", f.Path, err)
+			}
+			hashOriginals[f.RemoteName] = original
+		}
+		files = append(files, f)
+	}
+
+	if err := checkSlugCollisions(unslugged, slugs); err != nil {
+		return err
+	}
+
+	// The CMS file area only applies to CMS uploads
+	if cmd.Flags().Changed("file-type") {
+		for _, f := range files {
+			if f.Method != "cms" {
+				return i18n.Errorf("--file-type requires --method cms")
+			}
+		}
+	}
+	if err := client.ValidateCMSFileType(batchFileType); err != nil {
+		return err
+	}
+
+	// Published content can only be compared for CMS files
+	if batchSkipSame && describeMethods(files) == "graphql" {
+		return i18n.Errorf("--skip-identical requires --method cms")
+	}
+
+	// The bucket only applies to GraphQL uploads
+	if batchBucket != "" {
+		if describeMethods(files) == "cms" {
+			return i18n.Errorf("--bucket requires --method graphql")
+		}
+		bucket = batchBucket
+	}
+
+	// Conflict policies other than overwrite only make sense for CMS file names
+	if onConflict != "overwrite" {
+		for _, f := range files {
+			if f.Method != "cms" {
+				return i18n.Errorf("--on-conflict %s requires --method cms (GraphQL generates unique file names)", onConflict)
+			}
+		}
+	}
+
+	// Detect files with identical content selected from different paths
+	if batchOnDuplicate != "upload" {
+		groups, err := findDuplicateFiles(files)
+		if err != nil {
+			return err
+		}
+		if len(groups) > 0 {
+			action := i18n.T("will all be uploaded")
+			if batchOnDuplicate == "skip" {
+				action = i18n.T("only the first of each group will be uploaded")
+			}
+			color.Yellow(symbols(i18n.T("⚠️  %d group(s) of files have identical content (%s):")), len(groups), action)
+			for _, group := range groups {
+				for j, idx := range group {
+					if j == 0 {
+						fmt.Printf("  • %s\n", files[idx].Path)
+					} else {
+						fmt.Printf("    = %s\n", files[idx].Path)
+					}
+				}
+			}
+			fmt.Println()
+		}
+		if batchOnDuplicate == "skip" {
+			files = dropDuplicateFiles(files, groups)
+		}
+	}
+
+	// Load the VTEX CLI or App Key session once the files and flags are known
+	// to be valid, so mistakes are reported without contacting VTEX
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	// Redirect uploads to the rehearsal target
+	if batchRehearse != "" {
+		if batchDryRun {
+			return i18n.Errorf("--rehearse and --dry-run cannot be used together")
+		}
+		usesCMS := false
+		for _, f := range files {
+			usesCMS = usesCMS || f.Method == "cms"
+		}
+		if err := applyRehearsal(session, batchRehearse, usesCMS); err != nil {
+			return err
+		}
+	}
+
+	// Skip files that are unchanged since they were last uploaded
+	var uploadState *state.State
+	if batchIncremental {
+		uploadState, err = state.Load(directory)
+		if err != nil {
+			return err
+		}
+		changed := make([]batchFile, 0, len(files))
+		for _, f := range files {
+			unchanged, err := uploadState.Unchanged(f.RelPath, f.Path, session.Account, f.Method, f.RemoteName)
+			if err != nil {
+				slog.Warn("could not compare file with the last upload", "file", f.Path, "error", err)
+			}
+			if !unchanged {
+				changed = append(changed, f)
+			}
+		}
+		if skipped := len(files) - len(changed); skipped > 0 {
+			color.Yellow(i18n.T("%d unchanged file(s) skipped (--incremental)"), skipped)
+		}
+		if len(changed) == 0 {
+			color.Green(symbols(i18n.T("✓ Everything is up to date.")))
+			return nil
+		}
+		files = changed
+	}
+
 	// Calculate total size
 	var totalSize int64
 	for _, f := range files {
-		info, err := os.Stat(f)
+		info, err := os.Stat(f.Path)
 		if err == nil {
 			totalSize += info.Size()
 		}
 	}
 
 	// Create authenticator (needed for both checking and uploading)
-	authenticator := auth.NewAuthenticator(session.Token)
+	authenticator := newAuthenticator(session)
 
 	// Check which files already exist (only for CMS method)
+	// When conflicts are resolved per file, the check is done by each worker
+	// right before uploading instead, unless this is a dry run
 	existingFiles := []string{}
-	if batchMethod == "cms" {
-		cmsClient := client.NewCMSFilePickerClient(session.Account, session.Workspace, authenticator, verbose)
-
-		for _, f := range files {
-			fileName := filepath.Base(f)
-			exists, err := cmsClient.CheckFileExists(fileName)
-			if err != nil && verbose {
-				fmt.Printf("Warning: Could not check if %s exists: %v\n", fileName, err)
-			}
-			if exists {
-				existingFiles = append(existingFiles, fileName)
-			}
-		}
+	if !resolveInWorker || batchDryRun {
+		existingFiles = findExistingFiles(cmd.Context(), files, session.Account, session.Workspace, authenticator, concurrency)
 	}
 
 	// Print upload info
 	infoColor := color.New(color.FgCyan, color.Bold)
 	fmt.Println()
-	infoColor.Println("=== VTEX Batch Upload ===")
-	fmt.Printf("Account:       %s\n", session.Account)
-	fmt.Printf("Workspace:     %s\n", session.Workspace)
-	fmt.Printf("User:          %s\n", session.Login)
-	fmt.Printf("Method:        %s\n", batchMethod)
-	fmt.Printf("Directory:     %s\n", directory)
-	fmt.Printf("Files found:   %d (%.2f MB total)\n", len(files), float64(totalSize)/(1024*1024))
-	fmt.Printf("Concurrency:   %d workers\n", concurrency)
+	infoColor.Println(i18n.T("=== VTEX Batch Upload ==="))
+	if rehearsing {
+		printRehearsalNotice(session)
+	}
+	fmt.Printf(i18n.T("Account:       %s\n"), session.Account)
+	fmt.Printf(i18n.T("Workspace:     %s\n"), session.Workspace)
+	fmt.Printf(i18n.T("User:          %s\n"), session.Login)
+	fmt.Printf(i18n.T("Method:        %s\n"), describeMethods(files))
+	if manifest != nil {
+		fmt.Printf(i18n.T("Manifest:      %s\n"), sourceLabel)
+	} else {
+		fmt.Printf(i18n.T("Directory:     %s\n"), sourceLabel)
+	}
+	fmt.Printf(i18n.T("Files found:   %d (%.2f MB total)\n"), len(files), float64(totalSize)/(1024*1024))
+	if batchAdaptive {
+		fmt.Printf(i18n.T("Concurrency:   up to %d workers (adaptive)\n"), concurrency)
+	} else {
+		fmt.Printf(i18n.T("Concurrency:   %d workers\n"), concurrency)
+	}
 	fmt.Println()
 
-	// Show file list (max 10 files)
-	fmt.Println("Files to upload:")
+	// Show file list (max 10 files, all files in dry-run mode)
+	fmt.Println(i18n.T("Files to upload:"))
 	displayLimit := 10
+	if batchDryRun {
+		displayLimit = len(files)
+	}
 	for i, f := range files {
 		if i >= displayLimit {
-			fmt.Printf("  ... (%d more)\n", len(files)-displayLimit)
+			fmt.Printf(i18n.T("  ... (%d more)\n"), len(files)-displayLimit)
 			break
 		}
-		info, _ := os.Stat(f)
-		fmt.Printf("  %d. %s (%.2f KB)\n", i+1, filepath.Base(f), float64(info.Size())/1024)
+		info, _ := os.Stat(f.Path)
+		if f.RemoteName != filepath.Base(f.Path) {
+			fmt.Printf("  %d. %s → %s (%.2f KB)\n", i+1, filepath.Base(f.Path), f.RemoteName, float64(info.Size())/1024)
+		} else {
+			fmt.Printf("  %d. %s (%.2f KB)\n", i+1, f.RemoteName, float64(info.Size())/1024)
+		}
+		if batchDryRun {
+			fmt.Printf("     → %s\n", destinationURL(session.Account, f.Method, batchFileType, f.RemoteName))
+		}
 	}
 	fmt.Println()
 
+	// Manifests are rewritten from scratch, so say so before confirming
+	if batchEmitManifest != "" {
+		if _, err := os.Stat(batchEmitManifest); err == nil {
+			color.Yellow(symbols(i18n.T("⚠️  %s already exists and will be replaced by --emit-manifest")), batchEmitManifest)
+			fmt.Println()
+		}
+	}
+	if batchHashNames {
+		if _, err := os.Stat(batchHashOutput); err == nil {
+			color.Yellow(symbols(i18n.T("⚠️  %s already exists and will be replaced by --hash-names")), batchHashOutput)
+			fmt.Println()
+		}
+	}
+
+	// Show existing files that will be skipped or renamed
+	if resolveInWorker {
+		action := i18n.T("SKIPPED")
+		if onConflict == "rename" {
+			action = i18n.T("RENAMED")
+		}
+		if len(existingFiles) > 0 {
+			color.Yellow(i18n.T("%d file(s) already exist and will be %s:"), len(existingFiles), action)
+			for _, f := range existingFiles {
+				fmt.Printf("  • %s\n", f)
+			}
+		} else {
+			fmt.Printf(i18n.T("Existing files will be checked and %s during upload.\n"), strings.ToLower(action))
+		}
+		fmt.Println()
+	}
+
+	// Refuse to continue when conflicts are not allowed
+	if onConflict == "fail" && len(existingFiles) > 0 {
+		color.Red(symbols(i18n.T("✗ %d file(s) already exist:")), len(existingFiles))
+		for _, f := range existingFiles {
+			fmt.Printf("  • %s\n", f)
+		}
+		fmt.Println()
+		return i18n.Errorf("%d file(s) already exist (--on-conflict fail)", len(existingFiles))
+	}
+
 	// Show warning if files already exist
-	if len(existingFiles) > 0 {
-		color.Yellow("⚠️  WARNING: %d file(s) already exist and will be OVERWRITTEN:", len(existingFiles))
+	if !resolveInWorker && len(existingFiles) > 0 {
+		color.Yellow(symbols(i18n.T("⚠️  WARNING: %d file(s) already exist and will be OVERWRITTEN:")), len(existingFiles))
 		displayLimit := 5
+		if batchDryRun {
+			displayLimit = len(existingFiles)
+		}
 		for i, f := range existingFiles {
 			if i >= displayLimit {
-				fmt.Printf("  ... and %d more\n", len(existingFiles)-displayLimit)
+				fmt.Printf(i18n.T("  ... and %d more\n"), len(existingFiles)-displayLimit)
 				break
 			}
 			fmt.Printf("  • %s\n", f)
@@ -162,29 +764,486 @@ func runBatch(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	// Stop before uploading in dry-run mode
+	if batchDryRun {
+		color.Yellow(i18n.T("Dry run: no files were uploaded."))
+		return nil
+	}
+
+	if err := checkSessionOutlivesBatch(session, len(files), concurrency); err != nil {
+		return err
+	}
+
 	// Ask for confirmation unless --yes flag is set
 	if !batchSkipConfirm {
-		promptMsg := "Proceed with upload?"
-		if len(existingFiles) > 0 {
-			promptMsg = fmt.Sprintf("%d file(s) will be overwritten. Continue?", len(existingFiles))
+		promptMsg := i18n.T("Proceed with upload?")
+		if !resolveInWorker && len(existingFiles) > 0 {
+			promptMsg = i18n.Sprintf("%d file(s) will be overwritten. Continue?", len(existingFiles))
 		}
 		if !askConfirmation(promptMsg) {
-			color.Yellow("Upload cancelled.")
+			color.Yellow(i18n.T("Upload cancelled."))
 			return nil
 		}
 		fmt.Println()
 	}
 
+	opts := batchOptions{
+		Account:       session.Account,
+		Workspace:     session.Workspace,
+		Authenticator: authenticator,
+		Bucket:        bucket,
+		Concurrency:   concurrency,
+		Adaptive:      batchAdaptive,
+		OnConflict:    onConflict,
+		FailFast:      batchFailFast,
+		FileType:      batchFileType,
+		SkipIdentical: batchSkipSame,
+		Verify:        batchVerify,
+		Stage:         stage,
+	}
+
+	// Record progress so an interrupted batch can be resumed
+	outputs := batchOutputs{
+		RewriteRefs:  batchRewriteRefs,
+		Report:       batchReport,
+		Receipt:      batchReceipt,
+		EmitManifest: batchEmitManifest,
+		PurgeList:    batchPurgeList,
+	}
+	if batchPurgeList != "" && !resolveInWorker {
+		outputs.Overwritten = existingFiles
+	}
+	if batchHashNames {
+		outputs.HashOutput = batchHashOutput
+		outputs.HashOriginals = hashOriginals
+	}
+	if uploadState != nil {
+		if outputs.StateDir, err = filepath.Abs(directory); err != nil {
+			return err
+		}
+	}
+	checkpoint, err = newBatchCheckpoint(sourceLabel, stagingDir, files, opts, outputs)
+	if err != nil {
+		return err
+	}
+	opts.OnResult = checkpoint.markDone
+
+	// Ctrl-C from here on cancels the uploads in flight instead of killing the process
+	ctx, stop := notifyInterrupt(cmd.Context())
+	defer stop()
+
 	// Upload files concurrently
-	results := uploadFilesWithConcurrency(session.Account, session.Workspace, authenticator, files, concurrency, batchMethod)
+	startedAt := time.Now()
+	var results []*client.UploadResult
+	var abortErr error
+	if batchRewriteRefs {
+		var knownURLs map[string]string
+		if uploadState != nil {
+			knownURLs = publishedStateURLs(uploadState, directory, session.Account)
+		}
+		results, abortErr = uploadRewritingRefs(ctx, files, opts, knownURLs)
+	} else {
+		results, abortErr = uploadFilesWithConcurrency(ctx, files, opts)
+	}
 
 	// Print summary
-	printBatchSummary(results)
+	printBatchSummary(results, len(files), time.Since(startedAt))
+	if opts.Stage.enabled() {
+		printStageSavings(results)
+	}
+	checkpoint.finish()
+	reportToGitHubActions("VTEX batch upload", results)
+
+	if batchOutput == "markdown" {
+		printMarkdownMapping(results)
+	}
+	if porcelainOutput != nil {
+		writePorcelainResults(porcelainOutput, results)
+		writePorcelainSummary(porcelainOutput, len(files), results)
+	}
+	if formatTemplate != nil {
+		if err := writeFormatted(results); err != nil {
+			return err
+		}
+	}
+	if batchSnippet != "" {
+		printSnippets(batchSnippet, results)
+	}
+
+	if err := writeBatchOutputs(outputs, session, startedAt, files, results); err != nil {
+		return err
+	}
+	report := newBatchRunReport(session, startedAt, files, results)
+	notifyCompletion(batchNotifyURL, "batch", report)
+	if batchDesktopNotify {
+		notifyDesktop("batch", report)
+	}
+
+	return abortErr
+}
+
+// downloadRemoteSource downloads the supported files of a remote source (a
+// shared link, s3://, gs:// or az:// URL) into a temporary staging directory
+// and returns its path. The directory is removed if ctx is cancelled.
+func downloadRemoteSource(ctx context.Context, rawURL string, recursive bool) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	src, err := source.Resolve(ctx, rawURL, cfg)
+	if err != nil {
+		return "", err
+	}
+	// Cloud storage clients hold connections until they are closed
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	stagingDir, err := os.MkdirTemp("", "vfm-source-")
+	if err != nil {
+		return "", i18n.Errorf("failed to create staging directory: %w", err)
+	}
+
+	fmt.Printf(i18n.T("Fetching files from %s...\n"), src.Name())
+	paths, err := source.Download(ctx, src, stagingDir, func(relPath string) bool {
+		if !recursive && strings.Contains(relPath, "/") {
+			return false
+		}
+		return client.ExtensionAllowed("", filepath.Ext(relPath))
+	})
+	if err != nil {
+		os.RemoveAll(stagingDir)
+		if isCancellation(ctx, err) {
+			return "", errInterrupted
+		}
+		return "", err
+	}
+
+	slog.Debug("downloaded remote source", "files", len(paths), "dir", stagingDir)
+
+	return stagingDir, nil
+}
+
+// resumeBatch continues the last interrupted batch from its checkpoint
+func resumeBatch(parent context.Context) error {
+	checkpoint, err := loadBatchCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	// Uploads must go to the account the batch was started for; rehearsals
+	// may target a staging account different from the session's
+	rehearsing = checkpoint.Options.Rehearsal
+	if session.Account != checkpoint.Account {
+		if !rehearsing {
+			return i18n.Errorf("the interrupted batch uploads to account %s, but the VTEX CLI session is for %s", checkpoint.Account, session.Account)
+		}
+		if session, err = rehearsalSession(checkpoint.Account, checkpoint.Workspace); err != nil {
+			return err
+		}
+	}
+
+	defer removeStagingDir(checkpoint.StagingDir, checkpoint)
+
+	files := checkpoint.pending()
+	if len(files) == 0 {
+		color.Green(symbols(i18n.T("✓ The last batch already finished.")))
+		checkpoint.finish()
+		return nil
+	}
+	if checkpoint.StagingDir != "" {
+		if _, err := os.Stat(checkpoint.StagingDir); err != nil {
+			return i18n.Errorf("the staged files of the interrupted batch are gone (%s); run the batch again", checkpoint.StagingDir)
+		}
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println(i18n.T("=== Resuming VTEX Batch Upload ==="))
+	if rehearsing {
+		color.Yellow(i18n.T("REHEARSAL: real uploads to account %s, workspace %s. Production is not touched."), checkpoint.Account, checkpoint.Workspace)
+	}
+	fmt.Printf(i18n.T("Account:       %s\n"), checkpoint.Account)
+	fmt.Printf(i18n.T("Workspace:     %s\n"), checkpoint.Workspace)
+	fmt.Printf(i18n.T("Source:        %s\n"), checkpoint.Source)
+	fmt.Printf(i18n.T("Started:       %s\n"), checkpoint.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf(i18n.T("Remaining:     %d of %d files\n"), len(files), len(checkpoint.Files))
+	fmt.Println()
+
+	if err := checkSessionOutlivesBatch(session, len(files), checkpoint.Options.Concurrency); err != nil {
+		return err
+	}
+
+	// The batch was already confirmed when it started
+	opts := batchOptions{
+		Account:       checkpoint.Account,
+		Workspace:     checkpoint.Workspace,
+		Authenticator: newAuthenticator(session),
+		Bucket:        checkpoint.Options.Bucket,
+		Concurrency:   checkpoint.Options.Concurrency,
+		Adaptive:      checkpoint.Options.Adaptive,
+		OnConflict:    checkpoint.Options.OnConflict,
+		FailFast:      checkpoint.Options.FailFast,
+		FileType:      checkpoint.Options.FileType,
+		SkipIdentical: checkpoint.Options.SkipIdentical,
+		Verify:        checkpoint.Options.Verify,
+		Stage:         checkpoint.Options.Stage,
+		OnResult:      checkpoint.markDone,
+	}
+
+	ctx, stop := notifyInterrupt(parent)
+	defer stop()
+
+	// Outputs cover the files handled before the interruption too
+	earlierResults := checkpoint.handledResults()
 
+	startedAt := time.Now()
+	var results []*client.UploadResult
+	var abortErr error
+	if checkpoint.Options.RewriteRefs {
+		knownURLs := checkpoint.uploadedURLs()
+		if checkpoint.Options.StateDir != "" {
+			uploadState, err := state.Load(checkpoint.Options.StateDir)
+			if err != nil {
+				return err
+			}
+			for path, url := range publishedStateURLs(uploadState, checkpoint.Options.StateDir, session.Account) {
+				if _, ok := knownURLs[path]; !ok {
+					knownURLs[path] = url
+				}
+			}
+		}
+		results, abortErr = uploadRewritingRefs(ctx, files, opts, knownURLs)
+	} else {
+		results, abortErr = uploadFilesWithConcurrency(ctx, files, opts)
+	}
+
+	printBatchSummary(results, len(files), time.Since(startedAt))
+	if opts.Stage.enabled() {
+		printStageSavings(results)
+	}
+	checkpoint.finish()
+	reportToGitHubActions("VTEX batch upload", results)
+
+	if batchOutput == "markdown" {
+		printMarkdownMapping(results)
+	}
+	if porcelainOutput != nil {
+		writePorcelainResults(porcelainOutput, results)
+		writePorcelainSummary(porcelainOutput, len(files), results)
+	}
+	if formatTemplate != nil {
+		if err := writeFormatted(results); err != nil {
+			return err
+		}
+	}
+	if batchSnippet != "" {
+		printSnippets(batchSnippet, results)
+	}
+	allResults := append(earlierResults, results...)
+	if err := writeBatchOutputs(checkpoint.Options.batchOutputs, session, checkpoint.CreatedAt, checkpoint.files(), allResults); err != nil {
+		return err
+	}
+	report := newBatchRunReport(session, startedAt, files, results)
+	notifyCompletion(batchNotifyURL, "batch", report)
+	if batchDesktopNotify {
+		notifyDesktop("batch", report)
+	}
+
+	return abortErr
+}
+
+// writeBatchOutputs writes the --emit-manifest, --report, --receipt,
+// --purge-list and --hash-manifest files and the --incremental state of a batch
+func writeBatchOutputs(outputs batchOutputs, session *vtexcli.VTEXSession, startedAt time.Time, files []batchFile, results []*client.UploadResult) error {
+	if outputs.EmitManifest != "" {
+		if err := writeURLManifest(outputs.EmitManifest, files, results); err != nil {
+			return err
+		}
+	}
+	if outputs.Report != "" {
+		if err := writeBatchReport(outputs.Report, session, startedAt, files, results); err != nil {
+			return err
+		}
+	}
+	if outputs.Receipt != "" {
+		if err := writeReceipt(outputs.Receipt, session, results); err != nil {
+			return err
+		}
+	}
+	if outputs.StateDir != "" {
+		uploadState, err := state.Load(outputs.StateDir)
+		if err != nil {
+			return err
+		}
+		if err := saveUploadState(uploadState, session.Account, files, results); err != nil {
+			return err
+		}
+	}
+	if outputs.PurgeList != "" {
+		overwritten := make(map[string]bool, len(outputs.Overwritten))
+		for _, name := range outputs.Overwritten {
+			overwritten[name] = true
+		}
+		if err := writePurgeList(outputs.PurgeList, overwritten, results); err != nil {
+			return err
+		}
+	}
+	if outputs.HashOutput != "" {
+		if err := writeHashManifest(outputs.HashOutput, outputs.HashOriginals, results); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func findImageFiles(directory string, recursive bool) ([]string, error) {
+// saveUploadState records the successful uploads of a batch in the directory state file
+func saveUploadState(s *state.State, account string, files []batchFile, results []*client.UploadResult) error {
+	byPath := make(map[string]batchFile, len(files))
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+
+	for _, result := range results {
+		if result == nil || !result.Success {
+			continue
+		}
+		f, ok := byPath[result.FilePath]
+		if !ok {
+			continue
+		}
+		if err := s.Record(f.RelPath, f.Path, account, f.Method, f.RemoteName, result.FileURL); err != nil {
+			slog.Warn("could not record upload state", "file", f.Path, "state", state.FileName, "error", err)
+		}
+	}
+
+	return s.Save()
+}
+
+// findDuplicateFiles hashes every file and returns groups of indexes of files
+// with identical content, in batch order
+func findDuplicateFiles(files []batchFile) ([][]int, error) {
+	byHash := make(map[string][]int, len(files))
+	var order []string
+	for i, f := range files {
+		hash, _, err := receipt.HashFile(f.Path)
+		if err != nil {
+			return nil, i18n.Errorf("failed to hash %s: %w", f.Path, err)
+		}
+		if _, seen := byHash[hash]; !seen {
+			order = append(order, hash)
+		}
+		byHash[hash] = append(byHash[hash], i)
+	}
+
+	var groups [][]int
+	for _, hash := range order {
+		if len(byHash[hash]) > 1 {
+			groups = append(groups, byHash[hash])
+		}
+	}
+	return groups, nil
+}
+
+// dropDuplicateFiles removes all but the first file of each duplicate group
+func dropDuplicateFiles(files []batchFile, groups [][]int) []batchFile {
+	drop := map[int]bool{}
+	for _, group := range groups {
+		for _, idx := range group[1:] {
+			drop[idx] = true
+		}
+	}
+
+	kept := make([]batchFile, 0, len(files)-len(drop))
+	for i, f := range files {
+		if !drop[i] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// describeMethods returns the upload method shared by all files, or "mixed"
+func describeMethods(files []batchFile) string {
+	counts := map[string]int{}
+	for _, f := range files {
+		counts[f.Method]++
+	}
+	if len(counts) == 1 {
+		return files[0].Method
+	}
+	return fmt.Sprintf("mixed (cms: %d, graphql: %d)", counts["cms"], counts["graphql"])
+}
+
+// readFileList reads newline-separated file paths, keeping only supported files
+func readFileList(r io.Reader) ([]string, error) {
+	var files []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		info, err := os.Stat(line)
+		if err != nil {
+			return nil, i18n.Errorf("failed to access %s: %w", line, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		if !client.ExtensionAllowed("", filepath.Ext(line)) {
+			color.Yellow(i18n.T("Skipping unsupported file: %s"), line)
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files, scanner.Err()
+}
+
+// isGlobPattern reports whether the batch argument is a glob pattern rather than a directory
+func isGlobPattern(arg string) bool {
+	if _, err := os.Stat(arg); err == nil {
+		return false
+	}
+	return strings.ContainsAny(arg, "*?[{")
+}
+
+// findGlobFiles expands a doublestar glob pattern (e.g. "./images/**/*.png") and
+// returns the pattern's base directory together with the matching supported files
+func findGlobFiles(pattern string) (string, []string, error) {
+	base, rest := doublestar.SplitPattern(filepath.ToSlash(pattern))
+	if !doublestar.ValidatePattern(rest) {
+		return "", nil, i18n.Errorf("invalid glob pattern: %s", pattern)
+	}
+
+	matches, err := doublestar.Glob(os.DirFS(base), rest, doublestar.WithFilesOnly())
+	if err != nil {
+		return "", nil, err
+	}
+
+	var files []string
+	for _, m := range matches {
+		if client.ExtensionAllowed("", filepath.Ext(m)) {
+			files = append(files, filepath.Join(filepath.FromSlash(base), filepath.FromSlash(m)))
+		}
+	}
+
+	return filepath.FromSlash(base), files, nil
+}
+
+// findImageFiles lists the supported files in a directory. Subdirectories
+// matching an exclude pattern are not descended into.
+func findImageFiles(directory string, recursive bool, excludes []string) ([]string, error) {
 	var files []string
 
 	if recursive {
@@ -192,9 +1251,14 @@ func findImageFiles(directory string, recursive bool) ([]string, error) {
 			if err != nil {
 				return err
 			}
+			if info.IsDir() && path != directory {
+				if relPath, err := filepath.Rel(directory, path); err == nil && isExcluded(excludes, relPath) {
+					return filepath.SkipDir
+				}
+			}
 			if !info.IsDir() {
 				ext := filepath.Ext(path)
-				if client.ValidExtensions[ext] {
+				if client.ExtensionAllowed("", ext) {
 					files = append(files, path)
 				}
 			}
@@ -212,7 +1276,7 @@ func findImageFiles(directory string, recursive bool) ([]string, error) {
 		for _, entry := range entries {
 			if !entry.IsDir() {
 				ext := filepath.Ext(entry.Name())
-				if client.ValidExtensions[ext] {
+				if client.ExtensionAllowed("", ext) {
 					files = append(files, filepath.Join(directory, entry.Name()))
 				}
 			}
@@ -222,47 +1286,347 @@ func findImageFiles(directory string, recursive bool) ([]string, error) {
 	return files, nil
 }
 
-func uploadFilesWithConcurrency(account, workspace string, authenticator *auth.Authenticator, files []string, concurrency int, method string) []*client.UploadResult {
+// maxConsecutiveAuthFailures is the number of consecutive authentication
+// failures after which a batch is aborted even without --fail-fast
+const maxConsecutiveAuthFailures = 3
+
+// printSessionExpired prints the single message shown when the authentication
+// circuit breaker stops a run
+func printSessionExpired(failures, remaining int, nextStep string) {
+	fmt.Println()
+	color.New(color.FgRed, color.Bold).Printf(symbols(i18n.T("✗ Your VTEX session has expired: %d uploads in a row were rejected (HTTP 401/403).\n")), failures)
+	fmt.Printf(i18n.T("  Stopped before the %d remaining file(s). Run 'vtex login', then %s.\n\n"), remaining, nextStep)
+}
+
+// findExistingFiles returns the remote names of the CMS files that are already
+// published, in batch order. The whole batch is checked with a single
+// FileExists request; if it fails, the files are checked one by one.
+func findExistingFiles(ctx context.Context, files []batchFile, account, workspace string, authenticator *auth.Authenticator, concurrency int) []string {
+	var names []string
+	for _, f := range files {
+		if f.Method == "cms" {
+			names = append(names, f.RemoteName)
+		}
+	}
+	if len(names) == 0 {
+		return []string{}
+	}
+
+	cmsClient := client.NewCMSFilePickerClient(account, workspace, authenticator)
+	existing, err := cmsClient.ExistingFiles(ctx, names)
+	if err != nil {
+		slog.Warn("could not check existing files in bulk, checking them one by one", "error", err)
+		existing = checkFilesExist(ctx, names, account, workspace, authenticator, concurrency)
+	}
+
+	existingFiles := []string{}
+	for _, name := range names {
+		if existing[name] {
+			existingFiles = append(existingFiles, name)
+		}
+	}
+	return existingFiles
+}
+
+// checkFilesExist checks which CMS files exist with one request per file, run
+// concurrently by a pool of workers and showing a progress bar
+func checkFilesExist(ctx context.Context, names []string, account, workspace string, authenticator *auth.Authenticator, concurrency int) map[string]bool {
+	bar := progressbar.Default(int64(len(names)), "Checking existing files")
+	existing := make(map[string]bool)
+	var existingMutex sync.Mutex
+
+	nameChan := make(chan string, len(names))
+	for _, name := range names {
+		nameChan <- name
+	}
+	close(nameChan)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(concurrency, len(names)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Each worker has its own client, like the upload workers
+			cmsClient := client.NewCMSFilePickerClient(account, workspace, authenticator)
+			for fileName := range nameChan {
+				if ctx.Err() != nil {
+					continue
+				}
+				exists, err := cmsClient.CheckFileExists(ctx, fileName)
+				if err != nil {
+					slog.Warn("could not check if file exists", "file", fileName, "error", err)
+				}
+				existingMutex.Lock()
+				existing[fileName] = exists
+				existingMutex.Unlock()
+				bar.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	bar.Finish()
+
+	return existing
+}
+
+// batchOptions configures how a batch of files is uploaded
+type batchOptions struct {
+	Account       string
+	Workspace     string
+	Authenticator *auth.Authenticator
+	Bucket        string // GraphQL bucket
+	Concurrency   int    // maximum in-flight uploads
+	Adaptive      bool   // scale in-flight uploads down on throttling and back up when healthy
+	OnConflict    string
+	FailFast      bool
+	FileType      string       // CMS file area
+	SkipIdentical bool         // skip CMS files whose published content already matches
+	Verify        bool         // compare each published file with the uploaded content
+	Stage         stageOptions // transformations applied to a copy of each file
+
+	// OnResult is called with each file's outcome, serialized across workers
+	OnResult func(f batchFile, result *client.UploadResult)
+}
+
+// uploadFilesWithConcurrency uploads files with a pool of workers. Cancelling ctx
+// (e.g. with Ctrl-C) cancels the uploads in flight and returns the results so far.
+func uploadFilesWithConcurrency(parent context.Context, files []batchFile, opts batchOptions) ([]*client.UploadResult, error) {
 	results := make([]*client.UploadResult, 0, len(files))
 	var resultsMutex sync.Mutex
 
+	// Names claimed by files in this batch, so renamed files don't collide with each other
+	reservedNames := make(map[string]bool, len(files))
+	for _, f := range files {
+		reservedNames[f.RemoteName] = true
+	}
+	isReserved := func(name string) bool {
+		resultsMutex.Lock()
+		defer resultsMutex.Unlock()
+		if reservedNames[name] {
+			return true
+		}
+		reservedNames[name] = true
+		return false
+	}
+
+	// Cancelled when the batch must stop early
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var abortErr error
+	var abortOnce sync.Once
+	abort := func(err error) bool {
+		first := false
+		abortOnce.Do(func() {
+			abortErr = err
+			cancel()
+			first = true
+		})
+		return first
+	}
+	consecutiveAuthFailures := 0
+
+	// An interrupted batch stops like an aborted one, keeping its partial results
+	stopWatching := context.AfterFunc(parent, func() { abort(errInterrupted) })
+	defer stopWatching()
+
+	// record stores a file's outcome and prints the live progress; callers hold resultsMutex
+	progress := newTransferProgress(files)
+	record := func(f batchFile, result *client.UploadResult) {
+		results = append(results, result)
+		if opts.OnResult != nil {
+			opts.OnResult(f, result)
+		}
+		fmt.Println(progress.complete(f, result))
+	}
+
+	// Transformed copies of files are staged here and removed after the batch
+	stagingDir, cleanup, err := newStagingDir(opts.Stage)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// Scale in-flight uploads with VTEX's health, up to opts.Concurrency
+	var limiter *concurrencyLimiter
+	if opts.Adaptive {
+		limiter = newConcurrencyLimiter(opts.Concurrency)
+	}
+
 	// Create channels
-	fileChan := make(chan string, len(files))
+	fileChan := make(chan batchFile, len(files))
 	var wg sync.WaitGroup
 
 	// Start workers
-	for i := 0; i < concurrency; i++ {
+	for i := 0; i < opts.Concurrency; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
 
-			// Create client for this worker based on method
-			var uploadFunc func(string, bool) (*client.UploadResult, error)
-
-			if method == "cms" {
-				cmsClient := client.NewCMSFilePickerClient(account, workspace, authenticator, verbose)
-				uploadFunc = cmsClient.UploadFile
-			} else {
-				graphqlClient := client.NewGraphQLClient(account, workspace, authenticator, verbose)
-				uploadFunc = graphqlClient.UploadFile
+			// Create uploaders for this worker; each file selects one by method
+			cmsClient := newCMSClient(opts.Account, opts.Workspace, opts.Authenticator, opts.FileType)
+			uploaders := map[string]client.Uploader{
+				"cms":     cmsClient,
+				"graphql": newGraphQLClient(opts.Account, opts.Workspace, opts.Authenticator, opts.Bucket),
 			}
 
-			for filePath := range fileChan {
-				fmt.Printf("[Worker %d] Uploading: %s\n", workerID+1, filepath.Base(filePath))
+			for f := range fileChan {
+				limiter.acquire()
+
+				// Drain remaining files without uploading once the batch is aborted
+				if ctx.Err() != nil {
+					limiter.release()
+					continue
+				}
+
+				// Transform the file before anything compares or uploads its content
+				uploadPath, originalSize, stagedSize, err := stageFile(f.Path, stagingDir, opts.Stage)
+				if err != nil {
+					color.Red(symbols(i18n.T("[Worker %d] ✗ Failed: %v")), workerID+1, err)
+
+					resultsMutex.Lock()
+					result := &client.UploadResult{FileName: f.RemoteName, FilePath: f.Path, Error: err}
+					record(f, result)
+					resultsMutex.Unlock()
+
+					if opts.FailFast {
+						abort(i18n.Errorf("batch aborted after first failure (--fail-fast): %w", err))
+					}
+					limiter.release()
+					continue
+				}
+				if uploadPath != f.Path {
+					fmt.Printf(i18n.T("[Worker %d] Transformed %s: %.2f KB → %.2f KB\n"), workerID+1, f.RemoteName, float64(originalSize)/1024, float64(stagedSize)/1024)
+				}
+
+				// Check existence immediately before uploading this file
+				uploader := uploaders[f.Method]
+				if opts.OnConflict == "skip" {
+					exists, err := uploader.Exists(ctx, f.RemoteName)
+					if err != nil {
+						slog.Warn("could not check if file exists", "file", f.RemoteName, "error", err)
+					}
+					if exists {
+						fmt.Printf(i18n.T("[Worker %d] Skipping existing file: %s\n"), workerID+1, f.RemoteName)
 
-				result, err := uploadFunc(filePath, false)
+						resultsMutex.Lock()
+						result := &client.UploadResult{FileName: f.RemoteName, FilePath: f.Path, Skipped: true}
+						record(f, result)
+						resultsMutex.Unlock()
+						limiter.release()
+						continue
+					}
+				}
+
+				// Skip files that are already published with the same content
+				if opts.SkipIdentical && f.Method == "cms" {
+					identical, err := cmsClient.RemoteMatches(ctx, f.RemoteName, uploadPath)
+					if err != nil {
+						slog.Warn("could not compare file with the published file", "file", f.RemoteName, "error", err)
+					}
+					if identical {
+						fmt.Printf(i18n.T("[Worker %d] Skipping identical file: %s\n"), workerID+1, f.RemoteName)
+
+						resultsMutex.Lock()
+						result := &client.UploadResult{FileName: f.RemoteName, FilePath: f.Path, Skipped: true}
+						record(f, result)
+						resultsMutex.Unlock()
+						limiter.release()
+						continue
+					}
+				}
+
+				// Pick a free suffixed name immediately before uploading this file
+				requestedName := f.RemoteName
+				if opts.OnConflict == "rename" && f.Method == "cms" {
+					name, err := cmsClient.FindAvailableName(ctx, f.RemoteName, isReserved)
+					if err != nil {
+						// Uploading under the original name would overwrite the file to keep
+						err = i18n.Errorf("could not resolve a free name for %s: %w", f.RemoteName, err)
+						color.Red(symbols(i18n.T("[Worker %d] ✗ Failed: %v")), workerID+1, err)
+
+						resultsMutex.Lock()
+						result := &client.UploadResult{FileName: f.RemoteName, FilePath: f.Path, Error: err}
+						record(f, result)
+						resultsMutex.Unlock()
+
+						if opts.FailFast {
+							abort(i18n.Errorf("batch aborted after first failure (--fail-fast): %w", err))
+						}
+						limiter.release()
+						continue
+					}
+					if name != f.RemoteName {
+						fmt.Printf(i18n.T("[Worker %d] Renaming existing file: %s → %s\n"), workerID+1, f.RemoteName, name)
+						f.RemoteName = name
+					}
+				}
+
+				fmt.Printf(i18n.T("[Worker %d] Uploading: %s\n"), workerID+1, f.RemoteName)
+
+				start := time.Now()
+				result, err := uploader.Upload(ctx, client.UploadRequest{FilePath: uploadPath, FileName: f.RemoteName, Verify: opts.Verify})
+
+				// Uploads cancelled by an abort are left for --resume, not counted as failures
+				if isCancellation(ctx, err) {
+					limiter.release()
+					continue
+				}
+				result.Duration = time.Since(start)
+				metrics.Default.RecordUpload(f.Method, stagedSize, result.Duration, err)
+				result.FilePath = f.Path
+				if uploadPath != f.Path {
+					result.OriginalSize, result.UploadedSize = originalSize, stagedSize
+					if err == nil {
+						hash, _, hashErr := receipt.HashFile(uploadPath)
+						if hashErr != nil {
+							slog.Warn("could not hash the transformed file", "file", f.Path, "error", hashErr)
+						}
+						result.UploadedSHA256 = hash
+					}
+				}
+				if f.RemoteName != requestedName {
+					result.RenamedFrom = requestedName
+				}
 				if err != nil {
-					color.Red("  ✗ Failed: %v", err)
+					// Once the breaker has tripped, its single message covers every rejected upload
+					if !errors.Is(err, client.ErrAuthFailed) || ctx.Err() == nil {
+						color.Red(symbols(i18n.T("  ✗ Failed: %v")), err)
+					}
 				} else {
-					color.Green("  ✓ Success: %s", result.FileURL)
+					if dimensions := result.Dimensions(); dimensions != "" {
+						color.Green(symbols(i18n.T("  ✓ Success: %s (%s)")), result.FileURL, dimensions)
+					} else {
+						color.Green(symbols(i18n.T("  ✓ Success: %s")), result.FileURL)
+					}
 				}
 
 				resultsMutex.Lock()
-				results = append(results, result)
+				record(f, result)
+				if errors.Is(err, client.ErrAuthFailed) {
+					consecutiveAuthFailures++
+				} else {
+					consecutiveAuthFailures = 0
+				}
+				authFailures := consecutiveAuthFailures
+				remaining := len(files) - len(results)
 				resultsMutex.Unlock()
 
-				// Small delay to avoid rate limiting
-				time.Sleep(500 * time.Millisecond)
+				// Stop the batch on the first error (--fail-fast) or on repeated auth errors
+				if err != nil && opts.FailFast {
+					abort(i18n.Errorf("batch aborted after first failure (--fail-fast): %w", err))
+				} else if authFailures >= maxConsecutiveAuthFailures {
+					if abort(i18n.Errorf("batch aborted after %d consecutive authentication failures: %w", authFailures, client.ErrAuthFailed)) {
+						printSessionExpired(authFailures, remaining, "'vfm batch --resume'")
+					}
+				}
+
+				// Without adaptive concurrency, pace uploads with a small delay to avoid rate limiting
+				if limiter == nil {
+					time.Sleep(500 * time.Millisecond)
+				}
+				limiter.release()
 			}
 		}(i)
 	}
@@ -276,15 +1640,19 @@ func uploadFilesWithConcurrency(account, workspace string, authenticator *auth.A
 	// Wait for all workers to finish
 	wg.Wait()
 
-	return results
+	return results, abortErr
 }
 
-func printBatchSummary(results []*client.UploadResult) {
+// printBatchSummary prints the outcome counts of a run that took elapsed
+func printBatchSummary(results []*client.UploadResult, totalFiles int, elapsed time.Duration) {
 	successCount := 0
 	failureCount := 0
+	skippedCount := 0
 
 	for _, result := range results {
-		if result.Success {
+		if result.Skipped {
+			skippedCount++
+		} else if result.Success {
 			successCount++
 		} else {
 			failureCount++
@@ -292,20 +1660,74 @@ func printBatchSummary(results []*client.UploadResult) {
 	}
 
 	fmt.Println()
-	color.New(color.FgCyan, color.Bold).Println("=== Upload Summary ===")
-	fmt.Printf("Total files:     %d\n", len(results))
-	color.Green("Successful:      %d", successCount)
+	color.New(color.FgCyan, color.Bold).Println(i18n.T("=== Upload Summary ==="))
+	fmt.Printf(i18n.T("Total files:     %d\n"), totalFiles)
+	color.Green(i18n.T("Successful:      %d"), successCount)
+	if skippedCount > 0 {
+		color.Yellow(i18n.T("Skipped:         %d"), skippedCount)
+	}
+	if notAttempted := totalFiles - len(results); notAttempted > 0 {
+		color.Yellow(i18n.T("Not attempted:   %d"), notAttempted)
+	}
+	if verbose {
+		printRequestStats()
+	}
 	if failureCount > 0 {
-		color.Red("Failed:          %d", failureCount)
+		color.Red(i18n.T("Failed:          %d"), failureCount)
 	} else {
-		fmt.Printf("Failed:          %d\n", failureCount)
+		fmt.Printf(i18n.T("Failed:          %d\n"), failureCount)
+	}
+	retried := 0
+	for _, result := range results {
+		if result.Attempts > 1 {
+			retried++
+		}
+	}
+	if retried > 0 {
+		color.Yellow(i18n.T("Retried:         %d file(s)"), retried)
+	}
+	if successCount > 0 {
+		transferred := uploadedBytes(results)
+		fmt.Printf(i18n.T("Transferred:     %s in %s (%s/s)\n"), formatBytes(transferred), elapsed.Round(100*time.Millisecond),
+			formatBytes(int64(bytesPerSecond(transferred, elapsed))))
 	}
 	fmt.Println()
 
-	if failureCount > 0 {
-		color.Yellow("Failed uploads:")
+	// List uploaded files with their image dimensions
+	if verbose && successCount > 0 {
+		color.Green(i18n.T("Uploaded files:"))
 		for _, result := range results {
 			if !result.Success {
+				continue
+			}
+			if dimensions := result.Dimensions(); dimensions != "" {
+				fmt.Printf("  • %s (%s)\n", result.FileName, dimensions)
+			} else {
+				fmt.Printf("  • %s\n", result.FileName)
+			}
+		}
+		fmt.Println()
+	}
+
+	// Report final names of renamed files
+	renamedHeader := false
+	for _, result := range results {
+		if result.Success && result.RenamedFrom != "" {
+			if !renamedHeader {
+				color.Yellow(i18n.T("Renamed uploads:"))
+				renamedHeader = true
+			}
+			fmt.Printf("  • %s → %s\n", result.RenamedFrom, result.FileURL)
+		}
+	}
+	if renamedHeader {
+		fmt.Println()
+	}
+
+	if failureCount > 0 {
+		color.Yellow(i18n.T("Failed uploads:"))
+		for _, result := range results {
+			if !result.Success && !result.Skipped {
 				fmt.Printf("  • %s: %v\n", result.FileName, result.Error)
 			}
 		}
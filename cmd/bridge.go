@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/bridge"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bridgeMethod string
+	bridgePort   int
+)
+
+var bridgeCmd = &cobra.Command{
+	Use:   "bridge",
+	Short: "Expose a localhost endpoint for a companion browser extension",
+	Long: `Start a local HTTP bridge that a companion browser extension can call to
+upload the image currently being viewed to VTEX using your VTEX CLI session.
+
+The bridge only listens on 127.0.0.1 and rejects requests for any other host
+name than 127.0.0.1 or localhost. A one-time pairing code is printed on
+startup; the extension exchanges it for a session token via POST /pair (JSON,
+from a chrome-extension:// or moz-extension:// origin only) and then calls the
+other endpoints with "Authorization: Bearer <token>". Pairing is locked after
+5 invalid codes until the bridge is restarted.
+
+Endpoints:
+  POST /pair     {"code": "..."}                 → {"token": "..."}
+  GET  /status                                    → account, workspace, method
+  POST /upload   {"url": "...", "name": "..."}   → {"fileName": "...", "fileUrl": "..."}
+
+With --metrics-addr, Prometheus metrics (uploads, failures, bytes uploaded and
+upload durations per method) are served at /metrics on that address.
+
+Examples:
+  vfm bridge -m cms
+  vfm bridge -m graphql --port 18000
+  vfm bridge -m cms --metrics-addr :9464`,
+	Args: cobra.NoArgs,
+	RunE: runBridge,
+}
+
+func init() {
+	rootCmd.AddCommand(bridgeCmd)
+	bridgeCmd.Flags().StringVarP(&bridgeMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
+	bridgeCmd.Flags().IntVar(&bridgePort, "port", 17333, "local port to listen on")
+	bridgeCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9464)")
+}
+
+func runBridge(cmd *cobra.Command, args []string) error {
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	// Resolve upload method and bucket from flags or account defaults in config
+	method, bucket, err := resolveUploadDefaults(bridgeMethod, session.Account)
+	if err != nil {
+		return err
+	}
+
+	authenticator := newAuthenticator(session)
+
+	uploader := newUploader(method, session.Account, session.Workspace, authenticator, bucket, client.DefaultCMSFileType)
+
+	info := map[string]string{
+		"account":   session.Account,
+		"workspace": session.Workspace,
+		"method":    method,
+	}
+	server, err := bridge.NewServer(info, func(filePath, fileName string) (*client.UploadResult, error) {
+		fmt.Printf("Uploading: %s\n", fileName)
+		start := time.Now()
+		result, err := uploader.Upload(cmd.Context(), client.UploadRequest{FilePath: filePath, FileName: fileName})
+		var size int64
+		if info, statErr := os.Stat(filePath); statErr == nil {
+			size = info.Size()
+		}
+		metrics.Default.RecordUpload(method, size, time.Since(start), err)
+		if err != nil {
+			color.Red(symbols("  ✗ Failed: %v"), err)
+		} else {
+			color.Green(symbols("  ✓ Success: %s"), result.FileURL)
+		}
+		return result, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start bridge: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", bridgePort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", bridgePort, err)
+	}
+
+	var metricsListener net.Listener
+	if metricsAddr != "" {
+		if metricsListener, err = serveMetrics(metricsAddr); err != nil {
+			return err
+		}
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== VTEX Files Bridge ===")
+	fmt.Printf("Account:       %s\n", session.Account)
+	fmt.Printf("Workspace:     %s\n", session.Workspace)
+	fmt.Printf("Method:        %s\n", method)
+	fmt.Printf("Listening on:  http://%s\n", listener.Addr())
+	if metricsListener != nil {
+		fmt.Printf("Metrics:       http://%s/metrics\n", metricsListener.Addr())
+	}
+	fmt.Println()
+	color.Yellow("Pairing code:  %s", server.PairingCode())
+	fmt.Println("Enter this code in the browser extension. It can be used only once.")
+	fmt.Println("Press Ctrl+C to stop.")
+	fmt.Println()
+
+	httpServer := &http.Server{
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	return httpServer.Serve(listener)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+)
+
+// maxChatFiles is the number of failed and uploaded files listed in chat
+// messages; longer lists end with a count of the files left out
+const maxChatFiles = 10
+
+// maxChatErrorLength truncates upload errors quoted in chat messages
+const maxChatErrorLength = 150
+
+// notifyChat posts a summary of the run to the Slack and Teams webhooks set in
+// the config file. Failures are reported as warnings and do not fail the run.
+func notifyChat(report batchRunReport) {
+	cfg, err := config.Load()
+	if err != nil {
+		color.Yellow(symbols("⚠️  Could not send chat notifications: %v"), err)
+		return
+	}
+
+	notifiers := []struct {
+		name    string
+		webhook config.ChatWebhookConfig
+		message func(batchRunReport) interface{}
+	}{
+		{"Slack", cfg.Notifications.Slack, slackMessage},
+		{"Teams", cfg.Notifications.Teams, teamsMessage},
+	}
+
+	for _, n := range notifiers {
+		if n.webhook.WebhookURL == "" {
+			continue
+		}
+		if n.webhook.OnlyOnFailure && report.Failed == 0 && report.NotAttempted == 0 {
+			continue
+		}
+		if err := postJSON(n.webhook.WebhookURL, n.message(report)); err != nil {
+			color.Yellow(symbols("⚠️  Could not notify %s: %v"), n.name, err)
+			continue
+		}
+		slog.Info("sent chat notification", "service", n.name)
+	}
+}
+
+// chatTitle summarizes the outcome of the run in one line
+func chatTitle(report batchRunReport) string {
+	what := "VTEX upload"
+	if report.Command == "batch" {
+		what = "VTEX batch upload"
+	}
+	if report.Failed > 0 || report.NotAttempted > 0 {
+		return fmt.Sprintf("⚠️ %s finished with failures", what)
+	}
+	return fmt.Sprintf("✅ %s completed", what)
+}
+
+// chatSummary is the plain-text fallback of a chat message
+func chatSummary(report batchRunReport) string {
+	return fmt.Sprintf("%s on %s/%s: %d uploaded, %d failed, %d skipped, %d not attempted",
+		chatTitle(report), report.Account, report.Workspace,
+		report.Successful, report.Failed, report.Skipped, report.NotAttempted)
+}
+
+// chatFileLists returns the failed and uploaded files of the run, each item
+// formatted by the given functions and capped at maxChatFiles
+func chatFileLists(report batchRunReport, failed, uploaded func(batchReportEntry) string) (failedItems, uploadedItems []string) {
+	var failedCount, uploadedCount int
+	for _, f := range report.Files {
+		switch f.Status {
+		case "failed":
+			failedCount++
+			if len(failedItems) < maxChatFiles {
+				failedItems = append(failedItems, failed(f))
+			}
+		case "success":
+			uploadedCount++
+			if len(uploadedItems) < maxChatFiles {
+				uploadedItems = append(uploadedItems, uploaded(f))
+			}
+		}
+	}
+	if failedCount > maxChatFiles {
+		failedItems = append(failedItems, fmt.Sprintf("…and %d more", failedCount-maxChatFiles))
+	}
+	if uploadedCount > maxChatFiles {
+		uploadedItems = append(uploadedItems, fmt.Sprintf("…and %d more", uploadedCount-maxChatFiles))
+	}
+	return failedItems, uploadedItems
+}
+
+// chatError shortens an upload error to fit a chat message
+func chatError(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if len([]rune(message)) > maxChatErrorLength {
+		message = string([]rune(message)[:maxChatErrorLength]) + "…"
+	}
+	return message
+}
+
+// slackMessage formats the run report as a Slack incoming webhook message
+func slackMessage(report batchRunReport) interface{} {
+	type text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	type block struct {
+		Type   string `json:"type"`
+		Text   *text  `json:"text,omitempty"`
+		Fields []text `json:"fields,omitempty"`
+	}
+
+	blocks := []block{
+		{Type: "section", Text: &text{"mrkdwn", fmt.Sprintf("*%s*\nAccount: `%s` · Workspace: `%s`", chatTitle(report), report.Account, report.Workspace)}},
+		{Type: "section", Fields: []text{
+			{"mrkdwn", fmt.Sprintf("*Uploaded*\n%d", report.Successful)},
+			{"mrkdwn", fmt.Sprintf("*Failed*\n%d", report.Failed)},
+			{"mrkdwn", fmt.Sprintf("*Skipped*\n%d", report.Skipped)},
+			{"mrkdwn", fmt.Sprintf("*Not attempted*\n%d", report.NotAttempted)},
+		}},
+	}
+
+	failed, uploaded := chatFileLists(report,
+		func(f batchReportEntry) string {
+			return fmt.Sprintf("• `%s`: %s", slackEscape(f.Name), slackEscape(chatError(f.Error)))
+		},
+		func(f batchReportEntry) string {
+			return fmt.Sprintf("• <%s|%s>", f.URL, slackEscape(f.Name))
+		})
+	if len(failed) > 0 {
+		blocks = append(blocks, block{Type: "section", Text: &text{"mrkdwn", "*Failed files*\n" + strings.Join(failed, "\n")}})
+	}
+	if len(uploaded) > 0 {
+		blocks = append(blocks, block{Type: "section", Text: &text{"mrkdwn", "*Uploaded files*\n" + strings.Join(uploaded, "\n")}})
+	}
+
+	return map[string]interface{}{
+		"text":   chatSummary(report),
+		"blocks": blocks,
+	}
+}
+
+// slackEscape escapes the characters Slack treats as control sequences
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// teamsMessage formats the run report as an Adaptive Card accepted by
+// Microsoft Teams incoming webhooks and workflows
+func teamsMessage(report batchRunReport) interface{} {
+	type fact struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+	element := func(kind string, fields map[string]interface{}) map[string]interface{} {
+		fields["type"] = kind
+		return fields
+	}
+
+	body := []map[string]interface{}{
+		element("TextBlock", map[string]interface{}{"text": chatTitle(report), "weight": "Bolder", "size": "Medium", "wrap": true}),
+		element("FactSet", map[string]interface{}{"facts": []fact{
+			{"Account", report.Account},
+			{"Workspace", report.Workspace},
+			{"Uploaded", fmt.Sprint(report.Successful)},
+			{"Failed", fmt.Sprint(report.Failed)},
+			{"Skipped", fmt.Sprint(report.Skipped)},
+			{"Not attempted", fmt.Sprint(report.NotAttempted)},
+		}}),
+	}
+
+	failed, uploaded := chatFileLists(report,
+		func(f batchReportEntry) string {
+			return fmt.Sprintf("- %s: %s", f.Name, chatError(f.Error))
+		},
+		func(f batchReportEntry) string {
+			return fmt.Sprintf("- [%s](%s)", f.Name, f.URL)
+		})
+	if len(failed) > 0 {
+		body = append(body, element("TextBlock", map[string]interface{}{"text": "**Failed files**\n\n" + strings.Join(failed, "\n"), "wrap": true, "color": "Attention"}))
+	}
+	if len(uploaded) > 0 {
+		body = append(body, element("TextBlock", map[string]interface{}{"text": "**Uploaded files**\n\n" + strings.Join(uploaded, "\n"), "wrap": true}))
+	}
+
+	return map[string]interface{}{
+		"type":    "message",
+		"summary": chatSummary(report),
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+const checkpointFileName = "vtex-files-manager/batch-checkpoint.json"
+
+// batchCheckpoint records the progress of a batch so it can be resumed with --resume
+type batchCheckpoint struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	Account   string             `json:"account"`
+	Workspace string             `json:"workspace"`
+	Source    string             `json:"source"`
+	Options   checkpointOptions  `json:"options"`
+	Files     []checkpointedFile `json:"files"`
+
+	// StagingDir holds the files of a remote source or archive; it is kept
+	// until the batch completes so --resume can upload them
+	StagingDir string `json:"stagingDir,omitempty"`
+
+	path string
+}
+
+// checkpointOptions are the batch options needed to resume uploading
+type checkpointOptions struct {
+	Bucket        string `json:"bucket"`
+	Concurrency   int    `json:"concurrency"`
+	Adaptive      bool   `json:"adaptive"`
+	OnConflict    string `json:"onConflict"`
+	FailFast      bool   `json:"failFast"`
+	FileType      string `json:"fileType"`
+	SkipIdentical bool   `json:"skipIdentical"`
+	Verify        bool   `json:"verify,omitempty"`
+	Rehearsal     bool   `json:"rehearsal,omitempty"` // uploads go to a --rehearse target
+
+	Stage stageOptions `json:"stage"`
+	batchOutputs
+}
+
+// batchOutputs are the batch options acting on the outcome of all its files,
+// including those uploaded before it was resumed
+type batchOutputs struct {
+	RewriteRefs  bool   `json:"rewriteRefs,omitempty"`
+	Report       string `json:"report,omitempty"`
+	Receipt      string `json:"receipt,omitempty"`
+	EmitManifest string `json:"emitManifest,omitempty"`
+	StateDir     string `json:"stateDir,omitempty"` // directory of the --incremental state file
+
+	PurgeList   string   `json:"purgeList,omitempty"`
+	Overwritten []string `json:"overwritten,omitempty"` // remote names that existed before the batch
+
+	HashOutput    string            `json:"hashOutput,omitempty"`    // --hash-manifest, with --hash-names
+	HashOriginals map[string]string `json:"hashOriginals,omitempty"` // hashed name → original name
+}
+
+// checkpointedFile is a batch file and whether it was handled
+type checkpointedFile struct {
+	Path       string `json:"path"`
+	RelPath    string `json:"relPath"`
+	RemoteName string `json:"remoteName"`
+	Method     string `json:"method"`
+	Done       bool   `json:"done"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Name       string `json:"name,omitempty"` // uploaded name, when renamed
+	URL        string `json:"url,omitempty"`
+	SHA256     string `json:"sha256,omitempty"` // digest of the uploaded content, when transformed
+}
+
+// newBatchCheckpoint creates a checkpoint for a batch about to start, replacing
+// the checkpoint of an earlier interrupted batch
+func newBatchCheckpoint(source, stagingDir string, files []batchFile, opts batchOptions, outputs batchOutputs) (*batchCheckpoint, error) {
+	path, err := xdg.StateFile(checkpointFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve checkpoint path: %w", err)
+	}
+
+	// The interrupted batch can no longer be resumed
+	if previous, err := loadBatchCheckpoint(); err == nil && previous.StagingDir != "" && previous.StagingDir != stagingDir {
+		os.RemoveAll(previous.StagingDir)
+	}
+
+	cp := &batchCheckpoint{
+		CreatedAt:  time.Now(),
+		Account:    opts.Account,
+		Workspace:  opts.Workspace,
+		Source:     source,
+		StagingDir: stagingDir,
+		Options: checkpointOptions{
+			Bucket:        opts.Bucket,
+			Concurrency:   opts.Concurrency,
+			Adaptive:      opts.Adaptive,
+			OnConflict:    opts.OnConflict,
+			FailFast:      opts.FailFast,
+			FileType:      opts.FileType,
+			SkipIdentical: opts.SkipIdentical,
+			Verify:        opts.Verify,
+			Rehearsal:     rehearsing,
+			Stage:         opts.Stage,
+			batchOutputs:  outputs,
+		},
+		path: path,
+	}
+	for _, f := range files {
+		cp.Files = append(cp.Files, checkpointedFile{
+			Path:       f.Path,
+			RelPath:    f.RelPath,
+			RemoteName: f.RemoteName,
+			Method:     f.Method,
+		})
+	}
+
+	return cp, cp.save()
+}
+
+// loadBatchCheckpoint reads the checkpoint of an interrupted batch
+func loadBatchCheckpoint() (*batchCheckpoint, error) {
+	path, err := xdg.SearchStateFile(checkpointFileName)
+	if err != nil {
+		return nil, fmt.Errorf("no interrupted batch to resume")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	cp := &batchCheckpoint{path: path}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// pending returns the files that were not uploaded or skipped yet
+func (cp *batchCheckpoint) pending() []batchFile {
+	var files []batchFile
+	for _, f := range cp.Files {
+		if !f.Done {
+			files = append(files, batchFile{Path: f.Path, RelPath: f.RelPath, RemoteName: f.RemoteName, Method: f.Method})
+		}
+	}
+	return files
+}
+
+// files returns all files of the batch
+func (cp *batchCheckpoint) files() []batchFile {
+	files := make([]batchFile, 0, len(cp.Files))
+	for _, f := range cp.Files {
+		files = append(files, batchFile{Path: f.Path, RelPath: f.RelPath, RemoteName: f.RemoteName, Method: f.Method})
+	}
+	return files
+}
+
+// removeStagingDir removes the staging directory of the batch unless it is
+// still needed to resume it. cp may be nil when the batch never started.
+func removeStagingDir(dir string, cp *batchCheckpoint) {
+	if dir == "" || (cp != nil && len(cp.pending()) > 0) {
+		return
+	}
+	os.RemoveAll(dir)
+}
+
+// handledResults returns the results of the files uploaded or skipped before
+func (cp *batchCheckpoint) handledResults() []*client.UploadResult {
+	var results []*client.UploadResult
+	for _, f := range cp.Files {
+		if !f.Done {
+			continue
+		}
+		name := f.Name
+		if name == "" {
+			name = f.RemoteName
+		}
+		results = append(results, &client.UploadResult{
+			FileName: name,
+			FilePath: f.Path,
+			FileURL:  f.URL,
+			Success:  !f.Skipped,
+			Skipped:  f.Skipped,
+
+			UploadedSHA256: f.SHA256,
+		})
+	}
+	return results
+}
+
+// uploadedURLs returns the URLs of files uploaded before, or skipped because
+// they were already published, keyed by absolute path
+func (cp *batchCheckpoint) uploadedURLs() map[string]string {
+	urls := map[string]string{}
+	for _, f := range cp.Files {
+		url := f.URL
+		if f.Skipped && url == "" {
+			url = destinationURL(cp.Account, "cms", cp.Options.FileType, firstNonEmpty(f.Name, f.RemoteName))
+		}
+		if url == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(f.Path); err == nil {
+			urls[abs] = url
+		}
+	}
+	return urls
+}
+
+// markDone records the outcome of a file and saves the checkpoint when it was handled
+func (cp *batchCheckpoint) markDone(f batchFile, result *client.UploadResult) {
+	if result == nil || !(result.Success || result.Skipped) {
+		return
+	}
+	for i := range cp.Files {
+		if cp.Files[i].Path == f.Path && !cp.Files[i].Done {
+			cp.Files[i].Done = true
+			cp.Files[i].Skipped = result.Skipped
+			cp.Files[i].URL = result.FileURL
+			cp.Files[i].SHA256 = result.UploadedSHA256
+			if result.FileName != cp.Files[i].RemoteName {
+				cp.Files[i].Name = result.FileName
+			}
+			break
+		}
+	}
+	if err := cp.save(); err != nil {
+		slog.Warn("could not update checkpoint", "error", err)
+	}
+}
+
+// finish removes the checkpoint when every file was handled, or tells how to resume
+func (cp *batchCheckpoint) finish() {
+	remaining := len(cp.pending())
+	if remaining == 0 {
+		os.Remove(cp.path)
+		return
+	}
+	fmt.Printf("Run 'vfm batch --resume' to retry the %d remaining file(s).\n\n", remaining)
+}
+
+func (cp *batchCheckpoint) save() error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cp.path, data, 0644)
+}
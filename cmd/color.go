@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// noColor disables colors and emoji in the output (--no-color or NO_COLOR)
+var noColor bool
+
+// emojiReplacer spells out the emoji of messages for plain-text logs
+var emojiReplacer = strings.NewReplacer(
+	"✓", "[ok]",
+	"✅", "[ok]",
+	"✗", "[x]",
+	"❌", "[x]",
+	"⚠", "[!]",
+	"ℹ", "[i]",
+	"↻", "[retry]",
+	"⏸", "[pause]",
+	"⏹", "[stop]",
+	"⏭", "[skip]",
+	"⬇", "[download]",
+	"\ufe0f", "",
+)
+
+// configureColor disables colors and emoji with --no-color or when the NO_COLOR
+// environment variable is set (https://no-color.org)
+func configureColor() error {
+	if os.Getenv("NO_COLOR") != "" {
+		noColor = true
+	}
+	if noColor {
+		color.NoColor = true
+	}
+	return nil
+}
+
+// symbols spells out the emoji of a message when emoji are disabled. Apply it
+// to message templates only, never to data such as file names or URLs, which
+// must be printed as they are.
+func symbols(message string) string {
+	if !noColor {
+		return message
+	}
+	return emojiReplacer.Replace(message)
+}
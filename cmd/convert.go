@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultWebPQuality is the quality used by --convert webp without a value
+const defaultWebPQuality = 80
+
+// cwebpTool is the libwebp encoder used for conversions, looked up on PATH
+const cwebpTool = "cwebp"
+
+// convertOptions configures the format conversion applied before upload
+type convertOptions struct {
+	Quality int `json:"quality"`
+}
+
+// parseConvert parses a --convert value: webp[:quality]. An empty value
+// disables conversion.
+func parseConvert(value string) (*convertOptions, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	format, quality, hasQuality := strings.Cut(value, ":")
+	if format != "webp" {
+		return nil, fmt.Errorf("invalid --convert value: %s (only 'webp[:quality]' is supported)", value)
+	}
+	opts := &convertOptions{Quality: defaultWebPQuality}
+	if hasQuality {
+		q, err := strconv.Atoi(quality)
+		if err != nil || q < 0 || q > 100 {
+			return nil, fmt.Errorf("invalid --convert quality: %s (must be 0-100)", quality)
+		}
+		opts.Quality = q
+	}
+
+	if _, err := exec.LookPath(cwebpTool); err != nil {
+		return nil, fmt.Errorf("--convert webp requires the %s tool from libwebp on PATH: %w", cwebpTool, err)
+	}
+	return opts, nil
+}
+
+// isConvertible reports whether a file is transcoded by --convert
+func isConvertible(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	default:
+		return false
+	}
+}
+
+// convertedName returns the remote name of a converted file: its extension
+// becomes .webp (banner.jpg → banner.webp)
+func convertedName(localPath, remoteName string, opts *convertOptions) string {
+	if opts == nil || !isConvertible(localPath) {
+		return remoteName
+	}
+	return strings.TrimSuffix(remoteName, filepath.Ext(remoteName)) + ".webp"
+}
+
+// convertToWebP transcodes an image with cwebp into stagingDir and returns the
+// path of the WebP copy
+func convertToWebP(path, stagingDir string, opts *convertOptions) (string, error) {
+	staged, err := os.CreateTemp(stagingDir, "*.webp")
+	if err != nil {
+		return "", err
+	}
+	staged.Close()
+
+	cmd := exec.Command(cwebpTool, "-quiet", "-q", strconv.Itoa(opts.Quality), "-metadata", "icc", path, "-o", staged.Name())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", cwebpTool, err, strings.TrimSpace(string(output)))
+	}
+	return staged.Name(), nil
+}
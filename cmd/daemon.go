@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/metrics"
+	"github.com/glinharesb/vtex-files-manager/pkg/queue"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// daemonPollInterval is how often the daemon looks for new queued files
+	daemonPollInterval = 2 * time.Second
+
+	// daemonFirstBackoff is the wait before retrying a failed upload; it doubles
+	// with each attempt up to daemonMaxBackoff
+	daemonFirstBackoff = 30 * time.Second
+	daemonMaxBackoff   = 30 * time.Minute
+)
+
+var daemonMaxAttempts int
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Upload queued files in the background, retrying failures",
+	Long: `Run a worker that uploads the files added with 'vfm enqueue', in the order
+they were queued. New files are picked up as they are enqueued.
+
+Failed uploads are retried with backoff (30s, doubling up to 30m) until they
+succeed or reach --max-attempts, and are then kept as failed: see them with
+'vfm queue list' and queue them again with 'vfm queue retry'. The queue is
+stored on disk, so stopping the daemon (Ctrl+C) or a crash loses nothing; the
+next 'vfm daemon' continues where it stopped.
+
+Files are uploaded to the account and workspace they were enqueued for, even
+after a 'vtex switch'. Only one daemon drains the queue at a time.
+
+The session is loaded again whenever there are files to upload. VTEX CLI
+tokens expire after a day, so long-running daemons should authenticate with
+--app-key.
+
+Examples:
+  vfm daemon
+  vfm daemon --max-attempts 20 --app-key vtexappkey-store-ABCDEF`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().IntVar(&daemonMaxAttempts, "max-attempts", 10, "give up on a file after this many failed uploads")
+	daemonCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9464)")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	if daemonMaxAttempts < 1 {
+		return fmt.Errorf("--max-attempts must be at least 1")
+	}
+
+	q, err := queue.Open()
+	if err != nil {
+		return err
+	}
+	unlock, err := q.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var metricsListener net.Listener
+	if metricsAddr != "" {
+		if metricsListener, err = serveMetrics(metricsAddr); err != nil {
+			return err
+		}
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== VTEX Upload Daemon ===")
+	fmt.Printf("Queue:         %s\n", q.Dir())
+	fmt.Printf("Max attempts:  %d\n", daemonMaxAttempts)
+	if metricsListener != nil {
+		fmt.Printf("Metrics:       http://%s/metrics\n", metricsListener.Addr())
+	}
+	fmt.Println("Press Ctrl+C to stop.")
+	fmt.Println()
+
+	// Ctrl-C cancels the upload in flight; its job stays queued
+	ctx, stop := notifyInterrupt(cmd.Context())
+	defer stop()
+
+	for {
+		wait, err := drainQueue(ctx, q)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			color.Red("[%s] %v", time.Now().Format(time.DateTime), err)
+			slog.Error("upload queue pass failed", "error", err)
+			wait = daemonFirstBackoff
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	color.Yellow("Upload daemon stopped.")
+	return nil
+}
+
+// drainQueue uploads every queued file that is due and returns how long to
+// wait before looking at the queue again
+func drainQueue(ctx context.Context, q *queue.Queue) (time.Duration, error) {
+	jobs, err := q.List()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var due []queue.Job
+	for _, job := range jobs {
+		if job.Due(now) {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return daemonPollInterval, nil
+	}
+
+	// Load the sessions for each pass, so a renewed login is picked up. A
+	// session that cannot be loaded fails the attempts of its own jobs only.
+	sessions := map[string]*vtexcli.VTEXSession{}
+	sessionErrs := map[string]error{}
+	uploaders := map[string]client.Uploader{}
+
+	for _, job := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		destination := job.Account + "/" + job.Workspace
+		session := sessions[destination]
+		if session == nil && sessionErrs[destination] == nil {
+			if session, err = loadSessionFor(job.Account, job.Workspace); err != nil {
+				sessionErrs[destination] = err
+			} else {
+				sessions[destination] = session
+			}
+		}
+		if err := sessionErrs[destination]; err != nil {
+			// A login or App Key can be fixed while the job waits for its retry
+			job.Attempts++
+			if err := failJob(q, job, err, job.Attempts >= daemonMaxAttempts); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		var uploader client.Uploader
+		method, bucket, err := resolveUploadDefaults(job.Method, session.Account)
+		if err == nil {
+			key := destination + "/" + method
+			if uploader = uploaders[key]; uploader == nil {
+				uploader = newUploader(method, session.Account, session.Workspace, newAuthenticator(session), bucket, client.DefaultCMSFileType)
+				uploaders[key] = uploader
+			}
+			err = client.ValidateFile(job.Path)
+		}
+		if err != nil {
+			// Retrying cannot fix a missing file or an unusable method
+			job.Attempts++
+			if err := failJob(q, job, err, true); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		fmt.Printf("[%s] Uploading %s (%s, %s/%s)\n", time.Now().Format(time.DateTime), job.Name, method, session.Account, session.Workspace)
+		start := time.Now()
+		result, err := uploader.Upload(ctx, client.UploadRequest{FilePath: job.Path, FileName: job.Name})
+		if isCancellation(ctx, err) {
+			break // the attempt does not count
+		}
+		var size int64
+		if info, statErr := os.Stat(job.Path); statErr == nil {
+			size = info.Size()
+		}
+		metrics.Default.RecordUpload(method, size, time.Since(start), err)
+
+		job.Attempts++
+		if err != nil {
+			if err := failJob(q, job, err, job.Attempts >= daemonMaxAttempts); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err := q.Remove(job.ID); err != nil {
+			return 0, err
+		}
+		color.Green(symbols("  ✓ %s"), result.FileURL)
+		slog.Info("uploaded queued file", "file", job.Name, "url", result.FileURL, "attempts", job.Attempts)
+	}
+
+	return daemonPollInterval, nil
+}
+
+// failJob records a failed attempt of job, scheduling a retry with backoff or,
+// when final, keeping it as failed
+func failJob(q *queue.Queue, job queue.Job, uploadErr error, final bool) error {
+	job.LastError = uploadErr.Error()
+	if final {
+		job.Failed = true
+		color.Red(symbols("  ✗ %s failed after %d attempt(s), giving up: %v"), job.Name, job.Attempts, uploadErr)
+		slog.Error("gave up on queued file", "file", job.Name, "attempts", job.Attempts, "error", uploadErr)
+	} else {
+		backoff := daemonBackoff(job.Attempts)
+		job.NextAttemptAt = time.Now().Add(backoff)
+		color.Yellow(symbols("  ✗ %s failed (attempt %d/%d), retrying in %s: %v"), job.Name, job.Attempts, daemonMaxAttempts, backoff, uploadErr)
+		slog.Warn("queued upload failed", "file", job.Name, "attempt", job.Attempts, "retry_in", backoff, "error", uploadErr)
+	}
+	return q.Update(job)
+}
+
+// daemonBackoff returns the wait after the given number of failed attempts
+func daemonBackoff(attempts int) time.Duration {
+	backoff := daemonFirstBackoff
+	for i := 1; i < attempts && backoff < daemonMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, daemonMaxBackoff)
+}
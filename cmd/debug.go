@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugTokenSamples  int
+	debugTokenInterval time.Duration
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Diagnostic tools for troubleshooting uploads",
+}
+
+var debugTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Preview the CMS requestToken workflow without uploading",
+	Long: `Perform only the CMS requestToken acquisition used by the cms upload method
+and report each step with secrets redacted.
+
+Nothing is uploaded. The report shows whether the admin page renders, which
+parse pattern matched the token, and how long the same token was observed.
+Use --samples to fetch the token several times and detect rotation.
+
+Examples:
+  vfm debug token
+  vfm debug token --samples 3 --interval 10s`,
+	Args: cobra.NoArgs,
+	RunE: runDebugToken,
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugTokenCmd)
+
+	debugTokenCmd.Flags().IntVar(&debugTokenSamples, "samples", 1, "number of times to fetch the token")
+	debugTokenCmd.Flags().DurationVar(&debugTokenInterval, "interval", 5*time.Second, "wait between samples")
+}
+
+func runDebugToken(cmd *cobra.Command, args []string) error {
+	if debugTokenSamples < 1 {
+		return fmt.Errorf("--samples must be at least 1")
+	}
+
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	authenticator := newAuthenticator(session)
+	cmsClient := client.NewCMSFilePickerClient(session.Account, session.Workspace, authenticator)
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== CMS requestToken Diagnostics ===")
+	fmt.Printf("Account:       %s\n", session.Account)
+	fmt.Printf("User:          %s\n", session.Login)
+	if session.AppKey != "" {
+		fmt.Printf("App Key:       %s\n", session.AppKey)
+	} else {
+		fmt.Printf("Session token: %s\n", redactSecret(session.Token))
+	}
+	fmt.Println()
+
+	var first *client.RequestTokenDiagnostics
+	var lastSame *client.RequestTokenDiagnostics
+	var rotatedAt *client.RequestTokenDiagnostics
+
+	for i := 0; i < debugTokenSamples; i++ {
+		if i > 0 {
+			time.Sleep(debugTokenInterval)
+		}
+
+		diag, err := cmsClient.DiagnoseRequestToken()
+		fmt.Printf("[Sample %d] POST %s\n", i+1, diag.URL)
+		if err != nil {
+			color.Red(symbols("  ✗ %v"), err)
+			return err
+		}
+
+		fmt.Printf("  Status:        %d (%s)\n", diag.StatusCode, diag.Latency.Round(time.Millisecond))
+		if diag.FinalURL != diag.URL {
+			color.Yellow("  Redirected to: %s", diag.FinalURL)
+		}
+		fmt.Printf("  Body size:     %d bytes\n", diag.BodySize)
+		if diag.PageRendered {
+			color.Green("  Admin page:    rendered")
+		} else {
+			color.Red("  Admin page:    not rendered (login page or error)")
+		}
+		if diag.MatchedPattern != "" {
+			color.Green("  Parse path:    %s", diag.MatchedPattern)
+			fmt.Printf("  Token:         %s\n", redactSecret(diag.Token))
+		} else {
+			color.Red("  Parse path:    no pattern matched")
+		}
+		for name, value := range diag.ExpiryHeaders {
+			fmt.Printf("  %-14s %s\n", name+":", value)
+		}
+		fmt.Println()
+
+		if diag.Token == "" {
+			continue
+		}
+		if first == nil {
+			first = diag
+			lastSame = diag
+		} else if diag.Token == first.Token {
+			lastSame = diag
+		} else if rotatedAt == nil {
+			rotatedAt = diag
+			color.Yellow("Token rotated since sample 1")
+			fmt.Println()
+		}
+	}
+
+	// Summarize observed token lifetime
+	infoColor.Println("=== Summary ===")
+	switch {
+	case first == nil:
+		color.Red("No requestToken could be obtained. Run 'vtex login' and try again.")
+	case debugTokenSamples == 1:
+		fmt.Println("Token TTL:     not advertised by the admin page (use --samples to observe rotation)")
+	case rotatedAt != nil:
+		fmt.Printf("Token TTL:     between %s and %s\n",
+			lastSame.FetchedAt.Sub(first.FetchedAt).Round(time.Second),
+			rotatedAt.FetchedAt.Sub(first.FetchedAt).Round(time.Second))
+	default:
+		fmt.Printf("Token TTL:     unchanged for at least %s\n", lastSame.FetchedAt.Sub(first.FetchedAt).Round(time.Second))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// redactSecret keeps only the first and last characters of a secret for display
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return fmt.Sprintf("%s…%s (%d chars)", secret[:4], secret[len(secret)-4:], len(secret))
+}
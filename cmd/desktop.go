@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// windowsPowerShellAppID is the application id toasts are shown under on
+// Windows; unregistered ids are silently dropped, so PowerShell's own is used
+const windowsPowerShellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// notifyDesktop shows a native OS notification with the outcome of the run.
+// When the platform has no notification tool, a warning is printed instead.
+func notifyDesktop(command string, report batchRunReport) {
+	report.Command = command
+	title := chatTitle(report)
+	message := fmt.Sprintf("%s/%s: %d uploaded, %d failed, %d skipped in %s",
+		report.Account, report.Workspace, report.Successful, report.Failed, report.Skipped,
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Second))
+	if report.NotAttempted > 0 {
+		message += fmt.Sprintf(", %d not attempted", report.NotAttempted)
+	}
+
+	notification, err := desktopNotificationCommand(title, message)
+	if err == nil {
+		err = notification.Run()
+	}
+	if err != nil {
+		color.Yellow(symbols("⚠️  Could not show desktop notification: %v"), err)
+	}
+}
+
+// desktopNotificationCommand returns the command showing a notification on the
+// current platform: osascript on macOS, PowerShell toasts on Windows and
+// notify-send (libnotify) elsewhere
+func desktopNotificationCommand(title, message string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.Command("osascript", "-e", script), nil
+	case "windows":
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+			"$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$text = $template.GetElementsByTagName('text')",
+			fmt.Sprintf("$text.Item(0).AppendChild($template.CreateTextNode(%s)) > $null", powerShellString(title)),
+			fmt.Sprintf("$text.Item(1).AppendChild($template.CreateTextNode(%s)) > $null", powerShellString(message)),
+			fmt.Sprintf("[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(%s).Show([Windows.UI.Notifications.ToastNotification]::new($template))", powerShellString(windowsPowerShellAppID)),
+		}, "; ")
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil, fmt.Errorf("notify-send not found on PATH (install libnotify)")
+		}
+		return exec.Command("notify-send", "--app-name=vfm", title, message), nil
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
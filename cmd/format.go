@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// formatOutput and formatTemplate print results with the --format template;
+// both are nil when no template was given
+var (
+	formatOutput   io.Writer
+	formatTemplate *template.Template
+)
+
+// formatFuncs are the functions available to --format templates, besides the
+// text/template builtins
+var formatFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {{json .}} for a whole result
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	// status is ok, skipped or failed, as in porcelain output
+	"status": resultStatus,
+}
+
+// startFormat parses a --format template and moves the human-readable output of
+// the command to stderr, so stdout only has the formatted results. An empty
+// format leaves the output unchanged.
+func startFormat(format string, porcelain string) error {
+	if format == "" {
+		return nil
+	}
+	if porcelain != "" {
+		return fmt.Errorf("--format cannot be combined with --porcelain")
+	}
+	tmpl, err := template.New("format").Funcs(formatFuncs).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+	formatTemplate = tmpl
+	formatOutput = moveOutputToStderr()
+	return nil
+}
+
+// writeFormatted writes each item with the --format template, one per line
+func writeFormatted[T any](items []T) error {
+	for _, item := range items {
+		if err := formatTemplate.Execute(formatOutput, item); err != nil {
+			return fmt.Errorf("failed to apply --format template: %w", err)
+		}
+		fmt.Fprintln(formatOutput)
+	}
+	return nil
+}
+
+// resultStatus returns ok, skipped or failed for an upload result
+func resultStatus(result *client.UploadResult) string {
+	switch {
+	case result.Skipped:
+		return "skipped"
+	case !result.Success:
+		return "failed"
+	default:
+		return "ok"
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// inGitHubActions reports whether vfm runs inside a GitHub Actions workflow
+func inGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// reportToGitHubActions emits an annotation per uploaded or failed file, so
+// results show up on the workflow run and the PR, and appends a results table
+// to the job summary. Annotations go to stderr, which the runner reads
+// commands from too, so they never mix with --output json or --format results
+// on stdout. It does nothing outside GitHub Actions.
+func reportToGitHubActions(title string, results []*client.UploadResult) {
+	if !inGitHubActions() {
+		return
+	}
+
+	for _, result := range results {
+		file := githubAnnotationPath(result.FilePath)
+		switch {
+		case result.Skipped:
+			continue
+		case result.Success:
+			fmt.Fprintf(os.Stderr, "::notice file=%s,title=%s::%s\n", escapeAnnotationProperty(file),
+				escapeAnnotationProperty("Uploaded "+result.FileName), escapeAnnotationData(result.FileURL))
+		default:
+			message := "upload failed"
+			if result.Error != nil {
+				message = result.Error.Error()
+			}
+			fmt.Fprintf(os.Stderr, "::error file=%s,title=%s::%s\n", escapeAnnotationProperty(file),
+				escapeAnnotationProperty("Upload failed: "+result.FileName), escapeAnnotationData(message))
+		}
+	}
+
+	if err := writeGitHubStepSummary(title, results); err != nil {
+		fmt.Fprintf(os.Stderr, "::warning::%s\n", escapeAnnotationData(fmt.Sprintf("could not write the job summary: %v", err)))
+	}
+}
+
+// writeGitHubStepSummary appends the results to the file in $GITHUB_STEP_SUMMARY
+func writeGitHubStepSummary(title string, results []*client.UploadResult) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	successCount, failureCount, skippedCount := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skippedCount++
+		case result.Success:
+			successCount++
+		default:
+			failureCount++
+		}
+	}
+
+	fmt.Fprintf(file, "### %s\n\n", title)
+	fmt.Fprintf(file, "✅ %d uploaded · ⏭️ %d skipped · ❌ %d failed\n\n", successCount, skippedCount, failureCount)
+	writeMarkdownMapping(file, results)
+	return nil
+}
+
+// githubAnnotationPath returns path relative to the repository checkout, as
+// annotations expect
+func githubAnnotationPath(path string) string {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if abs, err := filepath.Abs(path); err == nil && workspace != "" {
+		if rel, err := filepath.Rel(workspace, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// escapeAnnotationData escapes the message of a workflow command
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property value of a workflow command
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/grpcapi"
+	"github.com/glinharesb/vtex-files-manager/pkg/metrics"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	grpcMethod string
+	grpcAddr   string
+	grpcToken  string
+	grpcCert   string
+	grpcKey    string
+)
+
+var grpcCmd = &cobra.Command{
+	Use:   "grpc",
+	Short: "Serve the vfm gRPC API for other services",
+	Long: `Start a gRPC server so other services (Go, Java, ...) can upload files to
+VTEX with the session vfm was started with, using the typed API published in
+proto/vfm/v1/files_manager.proto:
+
+  Upload        upload one file
+  BatchUpload   stream files in and upload events (started, succeeded,
+                failed) out, to follow a batch as it progresses
+  GetLogs       read the upload log of the server's account
+
+Clients send "authorization: Bearer <token>" metadata when the server has a
+token (--token or VFM_GRPC_TOKEN). Listening on an address other than
+localhost requires a token and a TLS certificate (--tls-cert and --tls-key),
+so neither the token nor the files cross the network in the clear. Server
+reflection is enabled, so tools like grpcurl work without the .proto file.
+
+Examples:
+  vfm grpc -m cms
+  vfm grpc -m graphql --addr :50051 --token "$VFM_GRPC_TOKEN" --tls-cert server.crt --tls-key server.key --app-key vtexappkey-store-ABCDEF`,
+	Args: cobra.NoArgs,
+	RunE: runGRPC,
+}
+
+func init() {
+	rootCmd.AddCommand(grpcCmd)
+	grpcCmd.Flags().StringVarP(&grpcMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
+	grpcCmd.Flags().StringVar(&grpcAddr, "addr", "127.0.0.1:50051", "address to listen on")
+	grpcCmd.Flags().StringVar(&grpcToken, "token", "", "token clients must send (default $VFM_GRPC_TOKEN)")
+	grpcCmd.Flags().StringVar(&grpcCert, "tls-cert", "", "PEM certificate to serve TLS with (required with --tls-key off localhost)")
+	grpcCmd.Flags().StringVar(&grpcKey, "tls-key", "", "PEM private key of --tls-cert")
+	grpcCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9464)")
+}
+
+func runGRPC(cmd *cobra.Command, args []string) error {
+	token := firstNonEmpty(grpcToken, os.Getenv("VFM_GRPC_TOKEN"))
+	if token == "" && !isLoopbackAddr(grpcAddr) {
+		return fmt.Errorf("--token (or VFM_GRPC_TOKEN) is required to listen on %s", grpcAddr)
+	}
+	if (grpcCert == "") != (grpcKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	if grpcCert == "" && !isLoopbackAddr(grpcAddr) {
+		return fmt.Errorf("--tls-cert and --tls-key are required to listen on %s, so the token and the files are not sent in the clear", grpcAddr)
+	}
+	var serverOpts []grpc.ServerOption
+	if grpcCert != "" {
+		cert, err := tls.LoadX509KeyPair(grpcCert, grpcKey)
+		if err != nil {
+			return fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})))
+	}
+
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	// Resolve upload method and bucket from flags or account defaults in config
+	method, bucket, err := resolveUploadDefaults(grpcMethod, session.Account)
+	if err != nil {
+		return err
+	}
+
+	authenticator := newAuthenticator(session)
+
+	uploader := newUploader(method, session.Account, session.Workspace, authenticator, bucket, client.DefaultCMSFileType).(client.ReaderUploader)
+
+	server := grpcapi.NewServer(func(ctx context.Context, content []byte, fileName string) (*client.UploadResult, error) {
+		fmt.Printf("Uploading: %s\n", fileName)
+		start := time.Now()
+		result, err := uploader.UploadReader(ctx, bytes.NewReader(content), fileName, int64(len(content)))
+		metrics.Default.RecordUpload(method, int64(len(content)), time.Since(start), err)
+		if err != nil {
+			color.Red(symbols("  ✗ Failed: %v"), err)
+		} else {
+			color.Green(symbols("  ✓ Success: %s"), result.FileURL)
+		}
+		return result, err
+	}, token, session.Account)
+
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
+	}
+	var metricsListener net.Listener
+	if metricsAddr != "" {
+		if metricsListener, err = serveMetrics(metricsAddr); err != nil {
+			return err
+		}
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== VTEX Files gRPC Server ===")
+	fmt.Printf("Account:       %s\n", session.Account)
+	fmt.Printf("Workspace:     %s\n", session.Workspace)
+	fmt.Printf("Method:        %s\n", method)
+	if grpcCert != "" {
+		fmt.Printf("Listening on:  %s (TLS)\n", listener.Addr())
+	} else {
+		fmt.Printf("Listening on:  %s\n", listener.Addr())
+	}
+	if metricsListener != nil {
+		fmt.Printf("Metrics:       http://%s/metrics\n", metricsListener.Addr())
+	}
+	if token == "" {
+		color.Yellow("No token set: any local process can upload through this server.")
+	}
+	fmt.Println("Press Ctrl+C to stop.")
+	fmt.Println()
+
+	return server.GRPCServer(serverOpts...).Serve(listener)
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
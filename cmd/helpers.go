@@ -2,21 +2,195 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"mime"
 	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/auth"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
 )
 
 // askConfirmation prompts the user for yes/no confirmation
 func askConfirmation(prompt string) bool {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s [y/N]: ", prompt)
+	fmt.Printf(i18n.T("%s [y/N]: "), prompt)
 
 	response, err := reader.ReadString('\n')
 	if err != nil {
 		return false
 	}
 
-	response = strings.ToLower(strings.TrimSpace(response))
-	return response == "y" || response == "yes"
+	return i18n.IsYes(response)
+}
+
+// destinationURL returns the expected public URL for an uploaded file
+// GraphQL URLs are generated by VTEX, so only a placeholder can be shown
+func destinationURL(account, method, fileType, fileName string) string {
+	if method == "cms" {
+		return client.LinkURL(client.PublicFileURL(account, fileType, fileName))
+	}
+	return client.LinkURL(fmt.Sprintf("https://%s.vtexassets.com/assets/.../[generated]", account))
+}
+
+// validateRemoteName checks a destination file name given for a local file
+func validateRemoteName(name, localPath string) error {
+	if name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid destination name %q: must be a file name, not a path", name)
+	}
+
+	// The content type is derived from the local file, so the extension must match
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != strings.ToLower(filepath.Ext(localPath)) {
+		return fmt.Errorf("invalid destination name %q: extension must match %s", name, filepath.Base(localPath))
+	}
+	return nil
+}
+
+// errMethodRequired is returned when no upload method is given by flag or config
+var errMethodRequired = errors.New("--method flag is required (must be 'graphql' or 'cms')")
+
+// resolveUploadDefaults returns the upload method and GraphQL bucket to use,
+// falling back to the account defaults from the config file when the --method
+// flag is not given. The bucket is returned even when the method is missing.
+func resolveUploadDefaults(flagMethod, account string) (string, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", "", err
+	}
+	defaults := cfg.Accounts[account]
+
+	bucket := defaults.Bucket
+	if bucket == "" {
+		bucket = client.DefaultGraphQLBucket
+	}
+
+	method := flagMethod
+	if method == "" {
+		method = defaults.Method
+	}
+
+	// Validate method is specified
+	if method == "" {
+		return "", bucket, fmt.Errorf("%w, or set accounts.%s.method in the config file", errMethodRequired, account)
+	}
+
+	// Validate method value
+	if method != "graphql" && method != "cms" {
+		return "", "", i18n.Errorf("invalid method: %s (must be 'graphql' or 'cms')", method)
+	}
+
+	return method, bucket, nil
+}
+
+// newCMSClient creates a CMS FilePicker client uploading to the given file area
+func newCMSClient(account, workspace string, authenticator *auth.Authenticator, fileType string) *client.CMSFilePickerClient {
+	cmsClient := client.NewCMSFilePickerClient(account, workspace, authenticator)
+	cmsClient.SetFileType(fileType)
+	return cmsClient
+}
+
+// newGraphQLClient creates a GraphQL client uploading to the given bucket
+func newGraphQLClient(account, workspace string, authenticator *auth.Authenticator, bucket string) *client.GraphQLClient {
+	graphqlClient := client.NewGraphQLClient(account, workspace, authenticator)
+	graphqlClient.SetBucket(bucket)
+	graphqlClient.SetWorkspaceEndpoint(rehearsing)
+	return graphqlClient
+}
+
+// newUploader creates the uploader for an upload method ("cms" or "graphql")
+func newUploader(method, account, workspace string, authenticator *auth.Authenticator, bucket, fileType string) client.Uploader {
+	if method == "cms" {
+		return newCMSClient(account, workspace, authenticator, fileType)
+	}
+	return newGraphQLClient(account, workspace, authenticator, bucket)
+}
+
+// validateForcedType checks the --force-type and --content-type flags
+func validateForcedType(force bool, contentType string) error {
+	if !force {
+		if contentType != "" {
+			return fmt.Errorf("--content-type requires --force-type")
+		}
+		return nil
+	}
+	if contentType == "" {
+		return fmt.Errorf("--force-type requires --content-type with the MIME type to send")
+	}
+	if _, _, err := mime.ParseMediaType(contentType); err != nil || !strings.Contains(contentType, "/") {
+		return fmt.Errorf("invalid --content-type %q: must be a MIME type such as image/avif", contentType)
+	}
+	return nil
+}
+
+// forceContentType makes uploader send files with the given MIME type and
+// accept extensions that are not allowed for its method
+func forceContentType(uploader client.Uploader, contentType string) {
+	switch u := uploader.(type) {
+	case *client.CMSFilePickerClient:
+		u.SetContentType(contentType)
+	case *client.GraphQLClient:
+		u.SetContentType(contentType)
+	}
+}
+
+// rehearsing is set when uploads are redirected to a rehearsal target by --rehearse
+var rehearsing bool
+
+// applyRehearsal redirects the session to a disposable rehearsal target. GraphQL
+// uploads go to the given workspace; CMS files are not workspace-scoped, so CMS
+// uploads go to the staging account configured for the current account, with
+// that account's own credentials: the production token is never sent to it.
+func applyRehearsal(session *vtexcli.VTEXSession, workspace string, usesCMS bool) error {
+	if workspace == "master" {
+		return fmt.Errorf("--rehearse needs a disposable workspace, not master")
+	}
+
+	if usesCMS {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		staging := cfg.Accounts[session.Account].RehearsalAccount
+		if staging == "" {
+			return fmt.Errorf("--rehearse with the cms method requires accounts.%s.rehearsalAccount in the config file", session.Account)
+		}
+		if staging == session.Account {
+			return fmt.Errorf("accounts.%s.rehearsalAccount must be a different account", session.Account)
+		}
+		rehearsal, err := rehearsalSession(staging, workspace)
+		if err != nil {
+			return err
+		}
+		*session = *rehearsal
+	}
+
+	session.Workspace = workspace
+	rehearsing = true
+	return nil
+}
+
+// rehearsalSession returns an App Key session for a rehearsal account from the
+// credentials stored for it with 'vfm auth login'
+func rehearsalSession(account, workspace string) (*vtexcli.VTEXSession, error) {
+	stored := storedCredentials(account)
+	if stored == nil {
+		return nil, fmt.Errorf("--rehearse with the cms method needs credentials for the rehearsal account %s: store an App Key for it with 'vfm auth login --account %s --app-key <key>'", account, account)
+	}
+	session, err := vtexcli.NewAppKeySession(account, workspace, stored.AppKey, stored.AppToken)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	return session, verifySession(session)
+}
+
+// printRehearsalNotice reminds that uploads go to the rehearsal target
+func printRehearsalNotice(session *vtexcli.VTEXSession) {
+	color.Yellow(i18n.T("REHEARSAL: real uploads to account %s, workspace %s. Production is not touched."), session.Account, session.Workspace)
 }
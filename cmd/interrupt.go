@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fatih/color"
+)
+
+// errInterrupted is returned by uploads stopped with Ctrl-C
+var errInterrupted = errors.New("interrupted by user")
+
+// notifyInterrupt returns a context that is cancelled on the first Ctrl-C (or
+// SIGTERM), so uploads in flight are cancelled and the partial results can
+// still be reported. A second Ctrl-C terminates the process immediately.
+func notifyInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			color.Yellow(symbols("\n⏹ Interrupted, cancelling uploads in flight (press Ctrl-C again to quit now)"))
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// isCancellation reports whether err was caused by ctx being cancelled
+func isCancellation(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && errors.Is(err, context.Canceled)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
 	"github.com/glinharesb/vtex-files-manager/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +14,9 @@ var (
 	logsStatus string
 	logsMethod string
 	logsClear  bool
+
+	logsPorcelain string
+	logsFormat    string
 )
 
 var logsCmd = &cobra.Command{
@@ -26,13 +30,16 @@ Logs are stored in JSONL format at:
   - Windows: %LOCALAPPDATA%\vtex-files-manager\uploads.jsonl
 
 Each log entry includes: timestamp, file name, size, upload method,
-account, workspace, status (success/failed), resulting URL, and error message if failed.
+account, workspace, status (success/failed), resulting URL, image dimensions
+and error message if failed.
 
 Examples:
   vtex-files-manager logs
   vtex-files-manager logs --limit 10
   vtex-files-manager logs --status failed
   vtex-files-manager logs --method cms
+  vtex-files-manager logs --porcelain
+  vtex-files-manager logs --status failed --format '{{.File}}: {{.Error}}'
   vtex-files-manager logs --clear`,
 	RunE: runLogs,
 }
@@ -44,6 +51,9 @@ func init() {
 	logsCmd.Flags().StringVarP(&logsStatus, "status", "s", "", "filter by status: success or failed")
 	logsCmd.Flags().StringVarP(&logsMethod, "method", "m", "", "filter by upload method: graphql or cms")
 	logsCmd.Flags().BoolVarP(&logsClear, "clear", "c", false, "clear all logs (requires confirmation)")
+	logsCmd.Flags().StringVar(&logsPorcelain, "porcelain", "", "print stable tab-separated entries on stdout for scripts (format version: v1)")
+	logsCmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	logsCmd.Flags().StringVar(&logsFormat, "format", "", "print each entry with a Go template on stdout, e.g. '{{.Timestamp}} {{.File}} {{.URL}}'")
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
@@ -51,6 +61,15 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if logsClear {
 		return clearLogsWithConfirmation()
 	}
+	if err := validatePorcelain(logsPorcelain); err != nil {
+		return err
+	}
+	if err := startFormat(logsFormat, logsPorcelain); err != nil {
+		return err
+	}
+	if logsPorcelain != "" {
+		startPorcelain()
+	}
 
 	// Get log file path
 	logPath, err := logger.GetLogPath()
@@ -85,6 +104,13 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if logsLimit > 0 && len(filteredEntries) > logsLimit {
 		displayEntries = filteredEntries[len(filteredEntries)-logsLimit:]
 	}
+	if porcelainOutput != nil {
+		writePorcelainLogs(porcelainOutput, displayEntries)
+		return nil
+	}
+	if formatTemplate != nil {
+		return writeFormatted(displayEntries)
+	}
 
 	// Print header
 	headerColor := color.New(color.FgCyan, color.Bold)
@@ -136,9 +162,9 @@ func printLogEntry(index int, entry logger.UploadLogEntry) {
 	// Status with color
 	var statusStr string
 	if entry.Status == "success" {
-		statusStr = color.GreenString("✓ SUCCESS")
+		statusStr = color.GreenString(symbols("✓ SUCCESS"))
 	} else {
-		statusStr = color.RedString("✗ FAILED")
+		statusStr = color.RedString(symbols("✗ FAILED"))
 	}
 
 	// Entry header
@@ -148,6 +174,15 @@ func printLogEntry(index int, entry logger.UploadLogEntry) {
 	sizeKB := float64(entry.Size) / 1024
 	fmt.Printf("    File:      %s (%.2f KB)\n", entry.File, sizeKB)
 
+	// Image dimensions if recorded
+	if entry.Image != nil {
+		dimensions := fmt.Sprintf("%dx%d", entry.Image.Width, entry.Image.Height)
+		if entry.Image.Animated {
+			dimensions += ", animated"
+		}
+		fmt.Printf("    Image:     %s\n", dimensions)
+	}
+
 	// Path if available
 	if entry.Path != "" {
 		fmt.Printf("    Path:      %s\n", entry.Path)
@@ -218,13 +253,13 @@ func clearLogsWithConfirmation() error {
 	logPath, _ := logger.GetLogPath()
 
 	// Show warning
-	color.Yellow("\n⚠️  WARNING: This will permanently delete all upload logs!")
-	fmt.Printf("Log file: %s\n", logPath)
-	fmt.Printf("Total entries: %d\n\n", len(entries))
+	color.Yellow(symbols(i18n.T("\n⚠️  WARNING: This will permanently delete all upload logs!")))
+	fmt.Printf(i18n.T("Log file: %s\n"), logPath)
+	fmt.Printf(i18n.T("Total entries: %d\n\n"), len(entries))
 
 	// Ask for confirmation
-	if !askConfirmation("Are you sure you want to clear all logs?") {
-		color.Yellow("Operation cancelled.")
+	if !askConfirmation(i18n.T("Are you sure you want to clear all logs?")) {
+		color.Yellow(i18n.T("Operation cancelled."))
 		return nil
 	}
 
@@ -233,6 +268,6 @@ func clearLogsWithConfirmation() error {
 		return fmt.Errorf("failed to clear logs: %w", err)
 	}
 
-	color.Green("\n✓ Logs cleared successfully!")
+	color.Green(symbols(i18n.T("\n✓ Logs cleared successfully!")))
 	return nil
 }
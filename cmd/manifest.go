@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestEntry is a row of a batch manifest
+type manifestEntry struct {
+	Path   string // local path, relative paths are resolved against the manifest directory
+	Method string // optional upload method overriding the default
+	Name   string // optional destination file name
+}
+
+// readManifest reads a CSV manifest with the columns path, method and name.
+// Only path is required; a header row starting with "path" is skipped and
+// lines starting with "#" are treated as comments.
+func readManifest(manifestPath string) ([]manifestEntry, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	baseDir := filepath.Dir(manifestPath)
+	var entries []manifestEntry
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		// Line in the file, counting the header, comments and quoted line breaks
+		line, _ := reader.FieldPos(0)
+
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		// Skip header and blank rows
+		if first && strings.EqualFold(field(0), "path") {
+			continue
+		}
+		if field(0) == "" {
+			continue
+		}
+
+		entry := manifestEntry{
+			Path:   field(0),
+			Method: strings.ToLower(field(1)),
+			Name:   field(2),
+		}
+		if entry.Method != "" && entry.Method != "graphql" && entry.Method != "cms" {
+			return nil, fmt.Errorf("manifest line %d: invalid method: %s (must be 'graphql' or 'cms')", line, entry.Method)
+		}
+		if !filepath.IsAbs(entry.Path) {
+			entry.Path = filepath.Join(baseDir, filepath.FromSlash(entry.Path))
+		}
+		if _, err := os.Stat(entry.Path); err != nil {
+			return nil, fmt.Errorf("manifest line %d: failed to access %s: %w", line, entry.Path, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// manifestHasMethods reports whether every manifest entry specifies its own method
+func manifestHasMethods(entries []manifestEntry) bool {
+	for _, e := range entries {
+		if e.Method == "" {
+			return false
+		}
+	}
+	return len(entries) > 0
+}
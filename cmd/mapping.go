@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// prefixMapping applies a remote file name prefix to files matching a source glob
+type prefixMapping struct {
+	pattern string
+	prefix  string
+}
+
+// parsePrefixMappings parses --map values in the form "glob=prefix"
+func parsePrefixMappings(values []string) ([]prefixMapping, error) {
+	mappings := make([]prefixMapping, 0, len(values))
+
+	for _, value := range values {
+		idx := strings.LastIndex(value, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid --map value: %q (expected \"glob=prefix\")", value)
+		}
+
+		pattern := filepath.ToSlash(strings.TrimSpace(value[:idx]))
+		prefix := strings.TrimSpace(value[idx+1:])
+
+		// Validate the glob syntax up front so typos fail before any upload
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid --map pattern %q", pattern)
+		}
+
+		mappings = append(mappings, prefixMapping{pattern: pattern, prefix: prefix})
+	}
+
+	return mappings, nil
+}
+
+// applyPrefixMappings returns the remote name for a file given its path relative
+// to the batch directory. The first matching mapping wins; unmatched files keep
+// their base name. When dirSeparator is not empty, the relative directories are
+// kept in the name, joined by dirSeparator (e.g. "icons/home.svg" → "icons_home.svg").
+func applyPrefixMappings(mappings []prefixMapping, relPath, dirSeparator string) string {
+	relPath = filepath.ToSlash(relPath)
+	baseName := path.Base(relPath)
+	if dirSeparator != "" {
+		baseName = strings.ReplaceAll(strings.TrimPrefix(relPath, "./"), "/", dirSeparator)
+	}
+
+	for _, m := range mappings {
+		if doublestar.MatchUnvalidated(m.pattern, relPath) {
+			return m.prefix + baseName
+		}
+	}
+
+	return baseName
+}
+
+// parseExcludePatterns validates --exclude glob patterns
+func parseExcludePatterns(values []string) ([]string, error) {
+	patterns := make([]string, 0, len(values))
+
+	for _, value := range values {
+		pattern := filepath.ToSlash(strings.TrimSpace(value))
+		if pattern == "" || !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid --exclude pattern %q", value)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// parseExtensionFilter parses --ext values into a set of lowercase extensions
+// with a leading dot. It returns nil when no filter is given.
+func parseExtensionFilter(values []string) (map[string]bool, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	extensions := make(map[string]bool, len(values))
+	for _, value := range values {
+		ext := strings.ToLower(strings.TrimSpace(value))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !client.ExtensionAllowed("", ext) {
+			return nil, fmt.Errorf("invalid --ext value: %s is not a supported file type", value)
+		}
+		extensions[ext] = true
+	}
+
+	return extensions, nil
+}
+
+// isExcluded reports whether a path relative to the batch directory matches an
+// exclude pattern. Patterns without a slash are also matched against the base
+// name, so "*.psd" excludes PSD files at any depth.
+func isExcluded(patterns []string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	baseName := path.Base(relPath)
+
+	for _, pattern := range patterns {
+		if doublestar.MatchUnvalidated(pattern, relPath) {
+			return true
+		}
+		if !strings.Contains(pattern, "/") && doublestar.MatchUnvalidated(pattern, baseName) {
+			return true
+		}
+	}
+
+	return false
+}
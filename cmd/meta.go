@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var metaIncludeHidden bool
+
+var metaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Machine-readable information about vfm itself",
+}
+
+var metaDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the command tree and flags as JSON",
+	Long: `Print the full command tree with each command's flags, types and defaults
+as JSON, so wrapper UIs and documentation generators can stay in sync with
+the CLI.
+
+Examples:
+  vfm meta dump > vfm-schema.json
+  vfm meta dump | jq '.commands[].name'`,
+	Args: cobra.NoArgs,
+	RunE: runMetaDump,
+}
+
+func init() {
+	rootCmd.AddCommand(metaCmd)
+	metaCmd.AddCommand(metaDumpCmd)
+
+	metaDumpCmd.Flags().BoolVar(&metaIncludeHidden, "hidden", false, "include hidden commands and flags")
+}
+
+// commandSchema describes a command in the schema dump
+type commandSchema struct {
+	Name       string          `json:"name"`
+	Path       string          `json:"path"`
+	Use        string          `json:"use"`
+	Short      string          `json:"short,omitempty"`
+	Long       string          `json:"long,omitempty"`
+	Aliases    []string        `json:"aliases,omitempty"`
+	Deprecated string          `json:"deprecated,omitempty"`
+	Hidden     bool            `json:"hidden,omitempty"`
+	Runnable   bool            `json:"runnable"`
+	Flags      []flagSchema    `json:"flags"`
+	Commands   []commandSchema `json:"commands,omitempty"`
+}
+
+// flagSchema describes a flag in the schema dump
+type flagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage"`
+	Persistent bool   `json:"persistent,omitempty"` // inherited by subcommands
+	Deprecated string `json:"deprecated,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
+}
+
+func runMetaDump(cmd *cobra.Command, args []string) error {
+	schema := struct {
+		Version string        `json:"version"`
+		Root    commandSchema `json:"root"`
+	}{
+		Version: version,
+		Root:    describeCommand(rootCmd),
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return nil
+}
+
+// describeCommand builds the schema of a command and its subcommands
+func describeCommand(c *cobra.Command) commandSchema {
+	schema := commandSchema{
+		Name:       c.Name(),
+		Path:       c.CommandPath(),
+		Use:        c.Use,
+		Short:      c.Short,
+		Long:       c.Long,
+		Aliases:    c.Aliases,
+		Deprecated: c.Deprecated,
+		Hidden:     c.Hidden,
+		Runnable:   c.Runnable(),
+		Flags:      []flagSchema{},
+	}
+
+	persistent := c.PersistentFlags()
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden && !metaIncludeHidden {
+			return
+		}
+		schema.Flags = append(schema.Flags, flagSchema{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Persistent: persistent.Lookup(f.Name) != nil,
+			Deprecated: f.Deprecated,
+			Hidden:     f.Hidden,
+		})
+	})
+
+	for _, sub := range c.Commands() {
+		if (sub.Hidden && !metaIncludeHidden) || sub.Name() == "help" {
+			continue
+		}
+		schema.Commands = append(schema.Commands, describeCommand(sub))
+	}
+
+	return schema
+}
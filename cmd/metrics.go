@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/metrics"
+)
+
+// metricsAddr is the --metrics-addr flag of the long-running commands
+var metricsAddr string
+
+// serveMetrics serves the Prometheus metrics of this process at /metrics on
+// addr and returns the listener, so its address can be printed. The command
+// keeps running if the metrics server stops later, so its error is reported
+// instead of returned.
+func serveMetrics(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on metrics address %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+	go func() {
+		if err := http.Serve(listener, mux); err != nil && !errors.Is(err, net.ErrClosed) {
+			color.Red("Metrics server stopped: %v", err)
+			slog.Error("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+	return listener, nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// minifyMediaTypes maps the extensions handled by --minify to media types
+var minifyMediaTypes = map[string]string{
+	".css": "text/css",
+	".js":  "application/javascript",
+}
+
+// minifier is shared by all workers; minify.M is safe for concurrent use
+var minifier = func() *minify.M {
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+	return m
+}()
+
+// minifyFile returns the minified content of a CSS or JS file, or nil for
+// other file types
+func minifyFile(path string) ([]byte, error) {
+	mediaType, ok := minifyMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := minifier.Minify(mediaType, &out, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/receipt"
+)
+
+// accentFolder maps accented lowercase Latin letters to their ASCII base letters
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ý", "y", "ÿ", "y",
+	"ß", "ss",
+)
+
+// slugify normalizes a destination file name: lowercase, accents stripped and
+// runs of other characters replaced by a single dash, keeping the extension.
+// For example "Banner Verão 2024 (final).png" becomes "banner-verao-2024-final.png".
+func slugify(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	base := accentFolder.Replace(strings.ToLower(strings.TrimSuffix(fileName, filepath.Ext(fileName))))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range base {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" {
+		slug = "file"
+	}
+	return slug + ext
+}
+
+// checkSlugCollisions returns an error when slugify gives two different
+// names the same slug, as the later upload would overwrite the earlier one.
+// names and slugs are parallel; equal names are not slugify's doing.
+func checkSlugCollisions(names, slugs []string) error {
+	seen := map[string]string{}
+	for i, slug := range slugs {
+		if first, ok := seen[slug]; ok && first != names[i] {
+			return fmt.Errorf("--slugify maps %q and %q to the same name %s", first, names[i], slug)
+		}
+		seen[slug] = names[i]
+	}
+	return nil
+}
+
+// resolveVersionSuffix validates a --version-suffix value. The special value
+// "timestamp" is replaced by the current UTC time (e.g. 20240131154500).
+func resolveVersionSuffix(value string) (string, error) {
+	if value == "timestamp" {
+		return time.Now().UTC().Format("20060102150405"), nil
+	}
+
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '.' && r != '_' && r != '-' {
+			return "", fmt.Errorf("invalid --version-suffix %q: use letters, digits, '.', '_' or '-'", value)
+		}
+	}
+	return value, nil
+}
+
+// appendVersionSuffix inserts a version suffix before the extension,
+// e.g. "theme.css" with "v42" becomes "theme-v42.css"
+func appendVersionSuffix(fileName, suffix string) string {
+	if suffix == "" {
+		return fileName
+	}
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-" + suffix + ext
+}
+
+// hashedName inserts the first 8 hex characters of the SHA-256 of the content
+// uploaded for localPath before the extension, e.g. "logo.png" becomes
+// "logo.1a2b3c4d.png". With transformations, the content is the copy staged in
+// stagingDir, so changing a transformation also changes the name. The
+// transformations are deterministic: the copy the upload worker stages again
+// has the same content.
+func hashedName(localPath, fileName, stagingDir string, stage stageOptions) (string, error) {
+	uploadPath, _, _, err := stageFile(localPath, stagingDir, stage)
+	if err != nil {
+		return "", err
+	}
+	if uploadPath != localPath {
+		defer os.Remove(uploadPath)
+	}
+
+	hash, _, err := receipt.HashFile(uploadPath)
+	if err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "." + hash[:8] + ext, nil
+}
+
+// writeHashManifest writes a JSON object mapping original destination names to
+// the URLs of their content-hashed uploads
+func writeHashManifest(path string, originals map[string]string, results []*client.UploadResult) error {
+	mapping := make(map[string]string, len(results))
+	for _, result := range results {
+		if result == nil || !result.Success {
+			continue
+		}
+		name := result.FileName
+		if result.RenamedFrom != "" {
+			name = result.RenamedFrom
+		}
+		if original, ok := originals[name]; ok {
+			mapping[original] = result.FileURL
+		}
+	}
+
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode hash manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write hash manifest: %w", err)
+	}
+
+	color.Green(symbols("✓ Hash manifest for %d file(s) written to %s"), len(mapping), path)
+	return nil
+}
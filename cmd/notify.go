@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// notifyTimeout bounds the webhook request made when a run completes
+const notifyTimeout = 30 * time.Second
+
+// validateNotifyURL checks a --notify-url value before any upload starts
+func validateNotifyURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid --notify-url: %s (must be an http or https URL)", rawURL)
+	}
+	return nil
+}
+
+// notifyCompletion sends the report of a finished run to --notify-url and to
+// the chat webhooks configured in the config file
+func notifyCompletion(notifyURL, command string, report batchRunReport) {
+	report.Command = command
+	notifyWebhook(notifyURL, report)
+	notifyChat(report)
+}
+
+// notifyWebhook POSTs the run report as JSON to rawURL. A failed notification
+// is reported as a warning and does not fail the run.
+func notifyWebhook(rawURL string, report batchRunReport) {
+	if rawURL == "" {
+		return
+	}
+
+	if err := postJSON(rawURL, report); err != nil {
+		color.Yellow(symbols("⚠️  Could not notify %s: %v"), webhookHost(rawURL), err)
+		return
+	}
+	slog.Info("notified webhook", "host", webhookHost(rawURL))
+}
+
+// webhookHost returns the host of a webhook URL, which is shown instead of the
+// whole URL since webhooks usually carry their secret in the path or query
+func webhookHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "the webhook"
+	}
+	return u.Host
+}
+
+// postJSON sends payload as a JSON POST request and checks for a 2xx response
+func postJSON(rawURL string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	httpClient := &http.Client{Timeout: notifyTimeout}
+	resp, err := httpClient.Post(rawURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error would repeat the whole URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
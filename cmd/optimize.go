@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultOptimizeQuality is the JPEG quality used by --optimize lossy
+const defaultOptimizeQuality = 82
+
+// optimizeOptions configures the compression applied before upload
+type optimizeOptions struct {
+	Lossy   bool `json:"lossy"` // re-encode JPEGs at Quality; PNGs are always recompressed losslessly
+	Quality int  `json:"quality,omitempty"`
+}
+
+// parseOptimize parses an --optimize value: lossless or lossy[:quality].
+// An empty value disables optimization.
+func parseOptimize(value string) (*optimizeOptions, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	mode, quality, hasQuality := strings.Cut(value, ":")
+	switch mode {
+	case "lossless":
+		if hasQuality {
+			return nil, fmt.Errorf("invalid --optimize value: %s (lossless takes no quality)", value)
+		}
+		return &optimizeOptions{}, nil
+	case "lossy":
+		opts := &optimizeOptions{Lossy: true, Quality: defaultOptimizeQuality}
+		if hasQuality {
+			q, err := strconv.Atoi(quality)
+			if err != nil || q < 1 || q > 100 {
+				return nil, fmt.Errorf("invalid --optimize quality: %s (must be 1-100)", quality)
+			}
+			opts.Quality = q
+		}
+		return opts, nil
+	default:
+		return nil, fmt.Errorf("invalid --optimize value: %s (must be 'lossless' or 'lossy[:quality]')", value)
+	}
+}
+
+// optimizeImage recompresses a PNG (always lossless) or a JPEG (lossy mode
+// only). It returns nil for files it leaves alone: other formats, including
+// WebP which has no encoder in the standard library, animated PNGs and JPEGs
+// whose EXIF orientation would be lost by re-encoding.
+func optimizeImage(path string, opts *optimizeOptions) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		return nil, nil
+	}
+	if ext != ".png" && !opts.Lossy {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if ext == ".png" {
+		if isAnimatedPNG(data) {
+			return nil, nil
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&out, img); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+
+	if orientation := jpegOrientation(data); orientation > 1 {
+		return nil, nil
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: opts.Quality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// isAnimatedPNG reports whether a PNG carries an APNG animation control chunk,
+// which the standard decoder would silently drop
+func isAnimatedPNG(data []byte) bool {
+	for offset := 8; offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunkType := string(data[offset+4 : offset+8])
+		if chunkType == "acTL" {
+			return true
+		}
+		if chunkType == "IDAT" {
+			return false
+		}
+		offset += 12 + length
+	}
+	return false
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG, or 0 if absent
+func jpegOrientation(data []byte) int {
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || offset+2+length > len(data) {
+			return 0
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 0
+}
+
+// exifOrientation reads the orientation tag (0x0112) from a TIFF header
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if string(tiff[:2]) == "II" {
+		order = binary.LittleEndian
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// resultStdout is the process stdout, before --no-color replaces it with a
+// pipe that spells out emoji: results for scripts are written unchanged
+var resultStdout io.Writer = os.Stdout
+
+// moveOutputToStderr sends the human-readable output of the command to stderr
+// and returns stdout, so results for scripts are the only output there
+func moveOutputToStderr() io.Writer {
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	return resultStdout
+}
+
+// validateOutputFormat checks an --output value
+func validateOutputFormat(format string) error {
+	switch format {
+	case "text", "markdown":
+		return nil
+	default:
+		return fmt.Errorf("invalid --output value: %s (must be 'text' or 'markdown')", format)
+	}
+}
+
+// printMarkdownMapping prints a Markdown table of local files and their remote
+// URLs, ready to paste into pull requests and handoff docs
+func printMarkdownMapping(results []*client.UploadResult) {
+	writeMarkdownMapping(os.Stdout, results)
+}
+
+// writeMarkdownMapping writes the Markdown table of printMarkdownMapping to w
+func writeMarkdownMapping(w io.Writer, results []*client.UploadResult) {
+	fmt.Fprintln(w, "| Local file | Remote URL |")
+	fmt.Fprintln(w, "|------------|------------|")
+	for _, result := range results {
+		remote := result.FileURL
+		switch {
+		case result.Skipped:
+			remote = "_skipped_"
+		case !result.Success:
+			remote = "_failed_"
+			if result.Error != nil {
+				remote = fmt.Sprintf("_failed: %s_", result.Error)
+			}
+		}
+		fmt.Fprintf(w, "| `%s` | %s |\n", escapeMarkdownCell(result.FilePath), escapeMarkdownCell(remote))
+	}
+	fmt.Fprintln(w)
+}
+
+// escapeMarkdownCell keeps a value from breaking a Markdown table row
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
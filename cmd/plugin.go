@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/glinharesb/vtex-files-manager/pkg/logger"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is the executable name prefix of external vfm subcommands
+const pluginPrefix = "vfm-"
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage external vfm-<name> subcommands",
+	Long: `vfm dispatches unknown subcommands to executables named vfm-<name> found
+on PATH, like git and kubectl do. For example, 'vfm acme-publish --dry-run'
+runs 'vfm-acme-publish --dry-run'.
+
+Built-in commands always take precedence over plugins with the same name.
+
+Plugins inherit vfm's environment, plus the session and settings vfm would
+upload with, so they don't need to read the VTEX CLI or vfm files:
+
+  VFM_BIN          path of the vfm executable, to call back into vfm
+  VFM_VERSION      vfm version
+  VFM_CONFIG_FILE  path of the vfm config file
+  VFM_LOG_FILE     path of the upload log
+  VTEX_ACCOUNT     account of the VTEX CLI session (or the one selected)
+  VTEX_WORKSPACE   workspace of the session
+  VFM_VTEX_LOGIN   user logged in with the VTEX CLI
+  VFM_VTEX_TOKEN   VTEX CLI token (VtexIdclientAutCookie), when not expired
+  VFM_METHOD       default upload method of the account in the config file
+  VFM_BUCKET       default GraphQL bucket of the account in the config file
+
+With VTEX_APP_KEY and VTEX_APP_TOKEN set, the App Key credentials are passed
+through as they are instead of the VTEX CLI session.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins found on PATH",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		color.Yellow("No plugins found on PATH (executables named %s<name>).", pluginPrefix)
+		return nil
+	}
+
+	fmt.Println("Plugins found on PATH:")
+	seen := map[string]bool{}
+	for _, path := range plugins {
+		name := pluginName(path)
+		switch {
+		case isBuiltinCommand(name):
+			fmt.Printf("  %s %s\n", path, color.YellowString("(ignored: conflicts with built-in command %q)", name))
+		case seen[name]:
+			fmt.Printf("  %s %s\n", path, color.YellowString("(shadowed by an earlier %s%s on PATH)", pluginPrefix, name))
+		default:
+			fmt.Printf("  %s\n", path)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// findPlugins returns the vfm-<name> executables on PATH in lookup order
+func findPlugins() []string {
+	var plugins []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), pluginPrefix) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				plugins = append(plugins, path)
+			}
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the subcommand name handled by a plugin executable
+func pluginName(path string) string {
+	name := strings.TrimPrefix(filepath.Base(path), pluginPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// isExecutable reports whether path is a file the current user can run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode()&0111 != 0
+}
+
+// isBuiltinCommand reports whether name is a built-in subcommand or alias
+func isBuiltinCommand(name string) bool {
+	// help and completion are added by cobra when executing
+	if name == "help" || name == "completion" || strings.HasPrefix(name, "__complete") {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// runPlugin executes the plugin handling args[0], if any. It reports whether a
+// plugin was run and exits with the plugin's exit code on failure.
+func runPlugin(args []string) bool {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args[0]) {
+		return false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return false
+	}
+
+	plugin := exec.Command(path, args[1:]...)
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+	plugin.Env = pluginEnv()
+
+	if err := plugin.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "failed to run plugin %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// pluginEnv returns the environment of a plugin: vfm's own environment plus the
+// variables listed in the plugin command help. The VTEX CLI session is read from
+// disk without contacting VTEX; when there is none, its variables are left out.
+func pluginEnv() []string {
+	env := os.Environ()
+	set := func(name, value string) {
+		if value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		set("VFM_BIN", exe)
+	}
+	set("VFM_VERSION", version)
+	if path, err := config.GetConfigPath(); err == nil {
+		set("VFM_CONFIG_FILE", path)
+	}
+	if path, err := logger.GetLogPath(); err == nil {
+		set("VFM_LOG_FILE", path)
+	}
+
+	account := os.Getenv("VTEX_ACCOUNT")
+	if os.Getenv("VTEX_APP_KEY") == "" {
+		session, err := vtexcli.LoadSessionForAccount(account)
+		if err != nil {
+			slog.Debug("no VTEX CLI session for plugin", "error", err)
+		} else {
+			account = session.Account
+			set("VTEX_ACCOUNT", account)
+			set("VTEX_WORKSPACE", firstNonEmpty(os.Getenv("VTEX_WORKSPACE"), session.Workspace))
+			set("VFM_VTEX_LOGIN", session.Login)
+			if session.ValidateToken() == nil {
+				set("VFM_VTEX_TOKEN", session.Token)
+			}
+		}
+	}
+
+	if cfg, err := config.Load(); err == nil && account != "" {
+		defaults := cfg.Accounts[account]
+		set("VFM_METHOD", defaults.Method)
+		set("VFM_BUCKET", defaults.Bucket)
+	}
+	return env
+}
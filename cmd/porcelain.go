@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/logger"
+)
+
+// porcelainV1 is the only porcelain format. Its records never change between
+// releases; a format with other fields would be added as a new version.
+const porcelainV1 = "v1"
+
+// porcelainOutput receives the porcelain records of the command, and is nil
+// when porcelain output was not requested
+var porcelainOutput io.Writer
+
+// porcelainEscaper keeps field values from breaking the tab-separated records
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// validatePorcelain checks a --porcelain value
+func validatePorcelain(version string) error {
+	switch version {
+	case "", porcelainV1:
+		return nil
+	default:
+		return fmt.Errorf("unsupported --porcelain version: %s (must be '%s')", version, porcelainV1)
+	}
+}
+
+// startPorcelain moves the human-readable output of the command to stderr, so
+// porcelain records are the only output on stdout, and writes the header
+// record naming the format version
+func startPorcelain() {
+	porcelainOutput = moveOutputToStderr()
+	writePorcelainRecord(porcelainOutput, "vfm-porcelain", porcelainV1)
+}
+
+// writePorcelainRecord writes one tab-separated record with escaped fields
+func writePorcelainRecord(w io.Writer, fields ...string) {
+	for i, field := range fields {
+		fields[i] = porcelainEscaper.Replace(field)
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+}
+
+// writePorcelainResults writes a file record for each upload result:
+//
+//	file <status> <local path> <remote name> <url> <error>
+//
+// where status is ok, skipped or failed
+func writePorcelainResults(w io.Writer, results []*client.UploadResult) {
+	for _, result := range results {
+		status, url, errMsg := resultStatus(result), "", ""
+		switch {
+		case status == "ok":
+			url = result.FileURL
+		case result.Error != nil && status == "failed":
+			errMsg = result.Error.Error()
+		}
+		writePorcelainRecord(w, "file", status, result.FilePath, result.FileName, url, errMsg)
+	}
+}
+
+// writePorcelainSummary writes the summary record of a batch:
+//
+//	summary <total> <succeeded> <skipped> <failed>
+func writePorcelainSummary(w io.Writer, total int, results []*client.UploadResult) {
+	var succeeded, skipped, failed int
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Success:
+			succeeded++
+		default:
+			failed++
+		}
+	}
+	writePorcelainRecord(w, "summary", strconv.Itoa(total), strconv.Itoa(succeeded), strconv.Itoa(skipped), strconv.Itoa(failed))
+}
+
+// writePorcelainLogs writes a log record for each upload log entry:
+//
+//	log <timestamp> <status> <method> <account> <workspace> <bytes> <file> <url> <error>
+//
+// with RFC 3339 timestamps in UTC
+func writePorcelainLogs(w io.Writer, entries []logger.UploadLogEntry) {
+	for _, entry := range entries {
+		writePorcelainRecord(w, "log",
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			entry.Status,
+			entry.Method,
+			entry.Account,
+			entry.Workspace,
+			strconv.FormatInt(entry.Size, 10),
+			entry.File,
+			entry.URL,
+			entry.Error,
+		)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// transferProgress tracks completed bytes of a batch to report throughput
+// and an ETA. It is not synchronized; callers hold the results mutex.
+type transferProgress struct {
+	start       time.Time
+	totalFiles  int
+	totalBytes  int64
+	doneFiles   int
+	doneBytes   int64 // bytes of handled files, uploaded or not
+	transferred int64 // bytes of successfully uploaded files
+}
+
+// newTransferProgress starts tracking a batch of files
+func newTransferProgress(files []batchFile) *transferProgress {
+	p := &transferProgress{start: time.Now(), totalFiles: len(files)}
+	for _, f := range files {
+		if info, err := os.Stat(f.Path); err == nil {
+			p.totalBytes += info.Size()
+		}
+	}
+	return p
+}
+
+// complete records a handled file and returns the live progress line
+func (p *transferProgress) complete(f batchFile, result *client.UploadResult) string {
+	var size int64
+	if info, err := os.Stat(f.Path); err == nil {
+		size = info.Size()
+	}
+	p.doneFiles++
+	p.doneBytes += size
+	if result.Success {
+		p.transferred += size
+	}
+
+	line := fmt.Sprintf("  [%d/%d] %s of %s", p.doneFiles, p.totalFiles, formatBytes(p.doneBytes), formatBytes(p.totalBytes))
+	speed := bytesPerSecond(p.transferred, time.Since(p.start))
+	if speed > 0 {
+		line += fmt.Sprintf(" · %s/s", formatBytes(int64(speed)))
+		if remaining := p.totalBytes - p.doneBytes; remaining > 0 {
+			eta := time.Duration(float64(remaining) / speed * float64(time.Second))
+			line += fmt.Sprintf(" · ETA %s", eta.Round(time.Second))
+		}
+	}
+	return line
+}
+
+// bytesPerSecond returns the throughput of n bytes over elapsed
+func bytesPerSecond(n int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
+
+// uploadedBytes sums the sizes of successfully uploaded files
+func uploadedBytes(results []*client.UploadResult) int64 {
+	var total int64
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		if result.UploadedSize > 0 {
+			total += result.UploadedSize
+		} else if info, err := os.Stat(result.FilePath); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// formatBytes formats a byte count in KB or MB
+func formatBytes(n int64) string {
+	if n >= 1024*1024 {
+		return fmt.Sprintf("%.2f MB", float64(n)/(1024*1024))
+	}
+	return fmt.Sprintf("%.2f KB", float64(n)/1024)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/queue"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enqueueMethod string
+	enqueueAs     string
+)
+
+var enqueueCmd = &cobra.Command{
+	Use:   "enqueue <file...>",
+	Short: "Add files to the upload queue drained by 'vfm daemon'",
+	Long: `Add files to a durable on-disk upload queue. 'vfm daemon' uploads queued
+files in the background and retries failed uploads with backoff, so the queue
+survives network outages, crashes and restarts.
+
+Files are read when they are uploaded, not when they are enqueued, so they
+must stay in place until the daemon picks them up. They are uploaded to the
+account and workspace of the session they were enqueued with (see --account
+and --workspace).
+
+Examples:
+  vfm enqueue hero.png -m cms
+  vfm enqueue ./migration/*.jpg -m graphql
+  vfm enqueue ./build/logo.final.png --as logo.png -m cms`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runEnqueue,
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Inspect and manage the upload queue",
+	Long: `Inspect and manage the upload queue filled by 'vfm enqueue' and drained
+by 'vfm daemon'.`,
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued and failed uploads",
+	Args:  cobra.NoArgs,
+	RunE:  runQueueList,
+}
+
+var queueRetryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Queue failed uploads again",
+	Long: `Reset the failed uploads in the queue so 'vfm daemon' attempts them again
+right away, with a fresh attempt count.`,
+	Args: cobra.NoArgs,
+	RunE: runQueueRetry,
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove failed uploads from the queue",
+	Args:  cobra.NoArgs,
+	RunE:  runQueueClear,
+}
+
+func init() {
+	rootCmd.AddCommand(enqueueCmd)
+	enqueueCmd.Flags().StringVarP(&enqueueMethod, "method", "m", "", "upload method: graphql or cms (default: the account default when uploaded)")
+	enqueueCmd.Flags().StringVar(&enqueueAs, "as", "", "upload under this destination file name (single file only)")
+
+	rootCmd.AddCommand(queueCmd)
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueRetryCmd)
+	queueCmd.AddCommand(queueClearCmd)
+}
+
+func runEnqueue(cmd *cobra.Command, args []string) error {
+	if enqueueMethod != "" && enqueueMethod != "graphql" && enqueueMethod != "cms" {
+		return fmt.Errorf("invalid method: %s (must be 'graphql' or 'cms')", enqueueMethod)
+	}
+	if enqueueAs != "" && len(args) > 1 {
+		return fmt.Errorf("--as can only be used with a single file")
+	}
+
+	// Validate every file before queueing any of them
+	jobs := make([]queue.Job, len(args))
+	for i, filePath := range args {
+		if err := client.ValidateFile(filePath); err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", filePath, err)
+		}
+		name := filepath.Base(filePath)
+		if enqueueAs != "" {
+			if err := validateRemoteName(enqueueAs, filePath); err != nil {
+				return err
+			}
+			name = enqueueAs
+		}
+		jobs[i] = queue.Job{Path: absPath, Name: name, Method: enqueueMethod}
+	}
+
+	// Jobs keep their destination if the current account changes before upload
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+
+	q, err := queue.Open()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.Account, job.Workspace = session.Account, session.Workspace
+		if _, err := q.Add(job); err != nil {
+			return err
+		}
+		fmt.Printf("Queued %s\n", job.Name)
+	}
+	fmt.Printf("Destination: %s (workspace %s)\n", session.Account, session.Workspace)
+
+	color.Green(symbols("\n✓ %d file(s) queued. Run 'vfm daemon' to upload them."), len(jobs))
+	return nil
+}
+
+func runQueueList(cmd *cobra.Command, args []string) error {
+	q, err := queue.Open()
+	if err != nil {
+		return err
+	}
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		fmt.Println("The upload queue is empty.")
+		return nil
+	}
+
+	now := time.Now()
+	for _, job := range jobs {
+		method := job.Method
+		if method == "" {
+			method = "default"
+		}
+		var status string
+		switch {
+		case job.Failed:
+			status = color.RedString("failed")
+		case job.Due(now):
+			status = color.CyanString("pending")
+		default:
+			status = color.YellowString("retry in %s", job.NextAttemptAt.Sub(now).Round(time.Second))
+		}
+
+		destination := "current account"
+		if job.Account != "" {
+			destination = job.Account + "/" + job.Workspace
+		}
+		fmt.Printf("%s  %s (%s, %s)  %s\n", job.EnqueuedAt.Format(time.DateTime), job.Name, method, destination, status)
+		fmt.Printf("    %s\n", job.Path)
+		if job.LastError != "" {
+			fmt.Printf("    attempt %d: %s\n", job.Attempts, job.LastError)
+		}
+	}
+	return nil
+}
+
+func runQueueRetry(cmd *cobra.Command, args []string) error {
+	q, err := queue.Open()
+	if err != nil {
+		return err
+	}
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, job := range jobs {
+		if !job.Failed {
+			continue
+		}
+		job.Failed = false
+		job.Attempts = 0
+		job.NextAttemptAt = time.Time{}
+		if err := q.Update(job); err != nil {
+			return err
+		}
+		count++
+	}
+
+	fmt.Printf("%d failed upload(s) queued again.\n", count)
+	return nil
+}
+
+func runQueueClear(cmd *cobra.Command, args []string) error {
+	q, err := queue.Open()
+	if err != nil {
+		return err
+	}
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, job := range jobs {
+		if !job.Failed {
+			continue
+		}
+		if err := q.Remove(job.ID); err != nil {
+			return err
+		}
+		count++
+	}
+
+	fmt.Printf("%d failed upload(s) removed from the queue.\n", count)
+	return nil
+}
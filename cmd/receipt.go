@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/receipt"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	receiptVerifyKey   string
+	receiptVerifyFetch bool
+)
+
+var receiptCmd = &cobra.Command{
+	Use:   "receipt",
+	Short: "Work with signed upload receipts",
+	Long: `Signed receipts record which files were published in a run: their names,
+SHA-256 hashes, URLs, the account, the operator and a timestamp.
+
+Write one with --receipt on upload or batch. Receipts are signed with a local
+ed25519 key created on first use and stored next to the config file. Share the
+output of 'vfm receipt key' with counterparts so they can verify receipts.
+
+Examples:
+  vfm batch ./images -m cms -y --receipt receipt.json
+  vfm receipt key
+  vfm receipt verify receipt.json --key <public-key>
+  vfm receipt verify receipt.json --fetch`,
+}
+
+var receiptVerifyCmd = &cobra.Command{
+	Use:   "verify <receipt.json>",
+	Short: "Verify the signature of an upload receipt",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReceiptVerify,
+}
+
+var receiptKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Print the public key used to sign receipts",
+	Args:  cobra.NoArgs,
+	RunE:  runReceiptKey,
+}
+
+func init() {
+	rootCmd.AddCommand(receiptCmd)
+	receiptCmd.AddCommand(receiptVerifyCmd)
+	receiptCmd.AddCommand(receiptKeyCmd)
+
+	receiptVerifyCmd.Flags().StringVar(&receiptVerifyKey, "key", "", "public key the receipt must be signed with")
+	receiptVerifyCmd.Flags().BoolVar(&receiptVerifyFetch, "fetch", false, "download each URL and compare its hash with the receipt")
+}
+
+func runReceiptVerify(cmd *cobra.Command, args []string) error {
+	r, err := receipt.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== Upload Receipt ===")
+	fmt.Printf("Account:       %s\n", r.Account)
+	fmt.Printf("Workspace:     %s\n", r.Workspace)
+	fmt.Printf("Operator:      %s\n", r.Operator)
+	fmt.Printf("Created:       %s\n", r.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("Files:         %d\n", len(r.Files))
+	fmt.Printf("Signed by:     %s\n", r.PublicKey)
+	fmt.Println()
+
+	if err := r.Verify(receiptVerifyKey); err != nil {
+		color.Red(symbols("✗ %v"), err)
+		return err
+	}
+	if receiptVerifyKey == "" {
+		color.Green(symbols("✓ Signature valid"))
+		color.Yellow("  Use --key to also check who signed the receipt")
+	} else {
+		color.Green(symbols("✓ Signature valid and signed by the expected key"))
+	}
+
+	if !receiptVerifyFetch {
+		return nil
+	}
+
+	// Compare the published content with the recorded hashes
+	fmt.Println()
+	httpClient := &http.Client{Timeout: 2 * time.Minute}
+	mismatches := 0
+	for _, f := range r.Files {
+		hash, err := fetchSHA256(httpClient, f.URL)
+		switch {
+		case err != nil:
+			mismatches++
+			color.Red(symbols("  ✗ %s: %v"), f.Name, err)
+		case hash != f.SHA256:
+			mismatches++
+			color.Red(symbols("  ✗ %s: published content differs"), f.Name)
+		default:
+			color.Green(symbols("  ✓ %s"), f.Name)
+		}
+	}
+	fmt.Println()
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d file(s) do not match the receipt", mismatches, len(r.Files))
+	}
+	return nil
+}
+
+func runReceiptKey(cmd *cobra.Command, args []string) error {
+	key, err := receipt.LoadOrCreateKey()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(receipt.PublicKey(key))
+	return nil
+}
+
+// writeReceipt signs and writes a receipt for the successful uploads in results
+func writeReceipt(path string, session *vtexcli.VTEXSession, results []*client.UploadResult) error {
+	key, err := receipt.LoadOrCreateKey()
+	if err != nil {
+		return err
+	}
+
+	r := receipt.New(session.Account, session.Workspace, session.Login)
+	for _, result := range results {
+		if result == nil || !result.Success {
+			continue
+		}
+		if err := r.AddFile(result.FilePath, result.FileName, result.FileURL); err != nil {
+			return fmt.Errorf("failed to add %s to receipt: %w", result.FileName, err)
+		}
+	}
+
+	if err := r.Sign(key); err != nil {
+		return err
+	}
+	if err := r.Write(path); err != nil {
+		return err
+	}
+
+	color.Green(symbols("✓ Signed receipt for %d file(s) written to %s"), len(r.Files), path)
+	return nil
+}
+
+// fetchSHA256 downloads a URL and returns the hex SHA-256 digest of its body
+func fetchSHA256(httpClient *http.Client, url string) (string, error) {
+	if url == "" {
+		return "", errors.New("no URL recorded")
+	}
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, resp.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
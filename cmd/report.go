@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/receipt"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+)
+
+// batchRunReport is the per-file outcome of a run, written by --report and
+// posted to --notify-url
+type batchRunReport struct {
+	Command      string             `json:"command,omitempty"` // set in --notify-url payloads
+	Account      string             `json:"account"`
+	Workspace    string             `json:"workspace"`
+	StartedAt    time.Time          `json:"startedAt"`
+	FinishedAt   time.Time          `json:"finishedAt"`
+	Total        int                `json:"total"`
+	Successful   int                `json:"successful"`
+	Failed       int                `json:"failed"`
+	Skipped      int                `json:"skipped"`
+	NotAttempted int                `json:"notAttempted"`
+	Files        []batchReportEntry `json:"files"`
+}
+
+// batchReportEntry is the outcome of a single file
+type batchReportEntry struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Method      string `json:"method"`
+	Status      string `json:"status"` // success, failed, skipped or not_attempted
+	URL         string `json:"url,omitempty"`
+	RenamedFrom string `json:"renamedFrom,omitempty"`
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"durationMs"`
+	Attempts    int    `json:"attempts,omitempty"`
+	Size        int64  `json:"size"`
+	// UploadedSize is the size sent after --resize/--convert/--optimize/--minify, when it differs from Size
+	UploadedSize int64 `json:"uploadedSize,omitempty"`
+}
+
+// writeBatchReport writes the outcome of every file in the batch as JSON
+func writeBatchReport(path string, session *vtexcli.VTEXSession, startedAt time.Time, files []batchFile, results []*client.UploadResult) error {
+	report := newBatchRunReport(session, startedAt, files, results)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	color.Green(symbols("✓ Report written to %s"), path)
+	return nil
+}
+
+// newBatchRunReport returns the outcome of every file of a run
+func newBatchRunReport(session *vtexcli.VTEXSession, startedAt time.Time, files []batchFile, results []*client.UploadResult) batchRunReport {
+	report := batchRunReport{
+		Account:    session.Account,
+		Workspace:  session.Workspace,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Total:      len(files),
+		Files:      []batchReportEntry{},
+	}
+
+	byPath := make(map[string]*client.UploadResult, len(results))
+	for _, result := range results {
+		byPath[result.FilePath] = result
+	}
+
+	for _, f := range files {
+		entry := batchReportEntry{
+			Path:   f.Path,
+			Name:   f.RemoteName,
+			Method: f.Method,
+			Status: "not_attempted",
+		}
+		if info, err := os.Stat(f.Path); err == nil {
+			entry.Size = info.Size()
+		}
+
+		if result, ok := byPath[f.Path]; ok {
+			entry.Name = result.FileName
+			entry.URL = result.FileURL
+			entry.RenamedFrom = result.RenamedFrom
+			entry.DurationMs = result.Duration.Milliseconds()
+			entry.UploadedSize = result.UploadedSize
+			entry.Attempts = result.Attempts
+			switch {
+			case result.Skipped:
+				entry.Status = "skipped"
+			case result.Success:
+				entry.Status = "success"
+			default:
+				entry.Status = "failed"
+				if result.Error != nil {
+					entry.Error = result.Error.Error()
+				}
+			}
+		}
+
+		switch entry.Status {
+		case "success":
+			report.Successful++
+		case "failed":
+			report.Failed++
+		case "skipped":
+			report.Skipped++
+		default:
+			report.NotAttempted++
+		}
+		report.Files = append(report.Files, entry)
+	}
+
+	return report
+}
+
+// urlManifestEntry is a file in the JSON manifest written by --emit-manifest
+type urlManifestEntry struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// writeURLManifest writes a JSON object mapping each uploaded file's path,
+// relative to the batch directory, to its final URL and the hash of the
+// content sent, which differs from the local file when it was transformed
+func writeURLManifest(path string, files []batchFile, results []*client.UploadResult) error {
+	relPaths := make(map[string]string, len(files))
+	for _, f := range files {
+		relPaths[f.Path] = filepath.ToSlash(f.RelPath)
+	}
+
+	manifest := struct {
+		Files map[string]urlManifestEntry `json:"files"`
+	}{Files: map[string]urlManifestEntry{}}
+
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		relPath, ok := relPaths[result.FilePath]
+		if !ok {
+			relPath = filepath.ToSlash(result.FilePath)
+		}
+		hash := result.UploadedSHA256
+		if hash == "" {
+			if result.UploadedSize > 0 {
+				return fmt.Errorf("failed to hash %s: the transformed content was not hashed", result.FilePath)
+			}
+			var err error
+			if hash, _, err = receipt.HashFile(result.FilePath); err != nil {
+				return fmt.Errorf("failed to hash %s: %w", result.FilePath, err)
+			}
+		}
+		manifest.Files[relPath] = urlManifestEntry{Name: result.FileName, URL: result.FileURL, SHA256: hash}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	color.Green(symbols("✓ Manifest for %d file(s) written to %s"), len(manifest.Files), path)
+	return nil
+}
+
+// writePurgeList writes the URLs of the files that replaced an already
+// published file, one per line, so they can be purged from the CDN cache.
+// overwritten holds the remote names that existed before the upload.
+func writePurgeList(path string, overwritten map[string]bool, results []*client.UploadResult) error {
+	var urls []string
+	for _, result := range results {
+		if result.Success && !result.Skipped && result.RenamedFrom == "" && overwritten[result.FileName] {
+			urls = append(urls, result.FileURL)
+		}
+	}
+
+	content := strings.Join(urls, "\n")
+	if len(urls) > 0 {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write purge list: %w", err)
+	}
+
+	if len(urls) > 0 {
+		color.Yellow(symbols("⚠️  %d overwritten file(s) may be served from the CDN cache until it expires; URLs to purge written to %s"), len(urls), path)
+	} else {
+		fmt.Printf("No file was overwritten; %s is empty.\n", path)
+	}
+	return nil
+}
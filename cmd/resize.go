@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// resizeJPEGQuality is the quality resized JPEGs are re-encoded at
+const resizeJPEGQuality = 90
+
+// resizeOptions bounds the dimensions of images before upload; 0 means unbounded
+type resizeOptions struct {
+	MaxWidth  int `json:"maxWidth,omitempty"`
+	MaxHeight int `json:"maxHeight,omitempty"`
+}
+
+// parseResize combines --resize WxH and --max-width into resize options. It
+// returns nil when neither is set.
+func parseResize(box string, maxWidth int) (*resizeOptions, error) {
+	if box == "" && maxWidth == 0 {
+		return nil, nil
+	}
+	if maxWidth < 0 {
+		return nil, fmt.Errorf("invalid --max-width: %d (must be positive)", maxWidth)
+	}
+
+	opts := &resizeOptions{MaxWidth: maxWidth}
+	if box != "" {
+		w, h, ok := strings.Cut(strings.ToLower(box), "x")
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("invalid --resize value: %s (expected WIDTHxHEIGHT, e.g. 1600x1600)", box)
+		}
+		if opts.MaxWidth == 0 || width < opts.MaxWidth {
+			opts.MaxWidth = width
+		}
+		opts.MaxHeight = height
+	}
+	return opts, nil
+}
+
+// fit returns the size that fits width x height within the bounds, keeping
+// the aspect ratio and never upscaling
+func (o *resizeOptions) fit(width, height int) (int, int) {
+	scale := 1.0
+	if o.MaxWidth > 0 && width > o.MaxWidth {
+		scale = float64(o.MaxWidth) / float64(width)
+	}
+	if o.MaxHeight > 0 && height > o.MaxHeight {
+		scale = min(scale, float64(o.MaxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// resizeImage downscales a PNG or JPEG that exceeds the bounds. It returns nil
+// for files it leaves alone: images already within bounds, other formats
+// (there are no GIF animation or WebP encoders in the standard library),
+// animated PNGs and JPEGs whose EXIF orientation would be lost.
+func resizeImage(path string, opts *resizeOptions) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext == ".png" && isAnimatedPNG(data) {
+		return nil, nil
+	}
+	if ext != ".png" && jpegOrientation(data) > 1 {
+		return nil, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	width, height := opts.fit(config.Width, config.Height)
+	if width == config.Width && height == config.Height {
+		return nil, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	resized := downscale(src, width, height)
+
+	var out bytes.Buffer
+	if ext == ".png" {
+		err = png.Encode(&out, resized)
+	} else {
+		err = jpeg.Encode(&out, resized, &jpeg.Options{Quality: resizeJPEGQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// downscale shrinks src to width x height by averaging the source pixels
+// covered by each destination pixel (an area filter, which does not alias
+// when reducing by large factors)
+func downscale(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok || bounds.Min != (image.Point{}) {
+		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	}
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0] = uint8((r + n/2) / n)
+			d[1] = uint8((g + n/2) / n)
+			d[2] = uint8((b + n/2) / n)
+			d[3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/state"
+)
+
+// cssURLPattern matches url(...) references in stylesheets, quoted or not
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+
+// isStylesheet reports whether a file gets its references rewritten
+func isStylesheet(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".css")
+}
+
+// rewriteStylesheet replaces relative url(...) references to uploaded files with
+// their final URLs. urls is keyed by absolute local path. It returns the new
+// content and the number of rewritten references.
+func rewriteStylesheet(cssPath string, urls map[string]string) ([]byte, int, error) {
+	content, err := os.ReadFile(cssPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(cssPath))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count := 0
+	rewritten := cssURLPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		parts := cssURLPattern.FindSubmatch(match)
+		ref := strings.TrimSpace(string(parts[2]))
+
+		// Leave absolute, root-relative and inline references alone
+		lower := strings.ToLower(ref)
+		if strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") || strings.HasPrefix(lower, "data:") || strings.Contains(ref, "://") {
+			return match
+		}
+
+		// Keep any query string or fragment (e.g. sprite anchors)
+		suffix := ""
+		if idx := strings.IndexAny(ref, "?#"); idx >= 0 {
+			ref, suffix = ref[:idx], ref[idx:]
+		}
+
+		url, ok := urls[filepath.Join(baseDir, filepath.FromSlash(ref))]
+		if !ok {
+			return match
+		}
+		count++
+		return []byte(fmt.Sprintf(`url("%s%s")`, url, suffix))
+	})
+
+	return rewritten, count, nil
+}
+
+// publishedStateURLs returns the URLs recorded in the --incremental state of
+// dir for files that are unchanged since they were uploaded to account, keyed
+// by absolute path, so references to them resolve although they are not
+// uploaded again
+func publishedStateURLs(s *state.State, dir, account string) map[string]string {
+	urls := map[string]string{}
+	for relPath, entry := range s.Files {
+		if entry.URL == "" {
+			continue
+		}
+		localPath := filepath.Join(dir, filepath.FromSlash(relPath))
+		if unchanged, err := s.Unchanged(relPath, localPath, account, entry.Method, entry.RemoteName); err != nil || !unchanged {
+			continue
+		}
+		if abs, err := filepath.Abs(localPath); err == nil {
+			urls[abs] = entry.URL
+		}
+	}
+	return urls
+}
+
+// uploadRewritingRefs uploads assets first, then uploads stylesheets with their
+// relative url(...) references rewritten to the final URLs. knownURLs holds
+// URLs of files published earlier (e.g. before a resume or unchanged since the
+// last --incremental run), keyed by absolute path. CMS files skipped because
+// they are already published resolve to their published URL.
+func uploadRewritingRefs(ctx context.Context, files []batchFile, opts batchOptions, knownURLs map[string]string) ([]*client.UploadResult, error) {
+	var assets, stylesheets []batchFile
+	for _, f := range files {
+		if isStylesheet(f.Path) {
+			stylesheets = append(stylesheets, f)
+		} else {
+			assets = append(assets, f)
+		}
+	}
+
+	results, err := uploadFilesWithConcurrency(ctx, assets, opts)
+	if err != nil || len(stylesheets) == 0 {
+		return results, err
+	}
+
+	urls := make(map[string]string, len(knownURLs)+len(results))
+	for path, url := range knownURLs {
+		urls[path] = url
+	}
+	for _, result := range results {
+		url := result.FileURL
+		if result.Skipped && url == "" {
+			url = destinationURL(opts.Account, "cms", opts.FileType, result.FileName)
+		}
+		if result.Success || result.Skipped {
+			if abs, err := filepath.Abs(result.FilePath); err == nil {
+				urls[abs] = url
+			}
+		}
+	}
+
+	// Stage rewritten copies; the originals are never modified
+	stagingDir, err := os.MkdirTemp("", "vfm-rewrite-")
+	if err != nil {
+		return results, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	originals := make(map[string]string, len(stylesheets))
+	rewritten := make([]batchFile, 0, len(stylesheets))
+	for i, f := range stylesheets {
+		content, count, err := rewriteStylesheet(f.Path, urls)
+		if err != nil {
+			return results, fmt.Errorf("failed to rewrite %s: %w", f.Path, err)
+		}
+
+		stagedPath := filepath.Join(stagingDir, fmt.Sprintf("%d-%s", i, filepath.Base(f.Path)))
+		if err := os.WriteFile(stagedPath, content, 0644); err != nil {
+			return results, fmt.Errorf("failed to stage %s: %w", f.Path, err)
+		}
+		fmt.Printf("Rewrote %d reference(s) in %s\n", count, f.RemoteName)
+
+		originals[stagedPath] = f.Path
+		staged := f
+		staged.Path = stagedPath
+		rewritten = append(rewritten, staged)
+	}
+
+	// Report stylesheets under their original paths
+	stylesheetOpts := opts
+	if opts.OnResult != nil {
+		stylesheetOpts.OnResult = func(f batchFile, result *client.UploadResult) {
+			f.Path = originals[f.Path]
+			opts.OnResult(f, result)
+		}
+	}
+
+	stylesheetResults, err := uploadFilesWithConcurrency(ctx, rewritten, stylesheetOpts)
+	for _, result := range stylesheetResults {
+		if original, ok := originals[result.FilePath]; ok {
+			result.FilePath = original
+		}
+	}
+
+	return append(results, stylesheetResults...), err
+}
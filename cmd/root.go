@@ -2,13 +2,35 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
+	verbose       bool
+	quotaPerMin   int
+	quotaThrottle bool
+	maxRetries    int
+	maxFileSizeMB float64
+	urlDomain     string
+	environment   string
+	language      string
+	caCertFile    string
+	insecureTLS   bool
+	debugHAR      string
+
+	// Fault injection (hidden flags for testing automation around vfm)
+	injectFailureRate float64
+	injectLatency     time.Duration
 
 	// Build-time variables set via ldflags
 	version = "dev"
@@ -32,19 +54,330 @@ Supported file types:
   - Documents (CMS only): pdf, txt, json, xml
   - Web (CMS only): css, js
 
-Maximum file size: 5MB per file`,
+Maximum file size: 5MB per file (change it with --max-file-size)`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := configureColor(); err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := configureLanguage(cmd, cfg); err != nil {
+			return err
+		}
+		if err := configureLogging(cmd); err != nil {
+			return err
+		}
+
+		if injectFailureRate < 0 || injectFailureRate > 1 {
+			return fmt.Errorf("--inject-failure-rate must be between 0 and 1")
+		}
+		client.DefaultFaultInjector.Configure(injectFailureRate, injectLatency)
+
+		if err := configureTracing(cmd); err != nil {
+			return err
+		}
+		if debugHAR != "" {
+			client.DefaultHARRecorder.Enable()
+		}
+
+		if err := client.ConfigureTLS(caCertFile, insecureTLS); err != nil {
+			return err
+		}
+		if insecureTLS {
+			color.New(color.FgRed, color.Bold).Fprintln(os.Stderr, symbols("⚠️  TLS certificate verification is DISABLED (--insecure-skip-verify). Anyone on the network path can read and alter your session and files. Prefer --ca-cert with your proxy's CA."))
+		}
+
+		if profile := firstNonEmpty(profileName, os.Getenv("VFM_PROFILE")); profile != "" {
+			if err := applyProfile(cmd, cfg, profile); err != nil {
+				return err
+			}
+		}
+
+		if err := configureQuota(cmd, cfg); err != nil {
+			return err
+		}
+		if err := configureRetries(cmd, cfg); err != nil {
+			return err
+		}
+		if err := configureFileSize(cmd, cfg); err != nil {
+			return err
+		}
+		if err := configureURLDomains(cfg); err != nil {
+			return err
+		}
+		if err := configureEnvironments(cfg); err != nil {
+			return err
+		}
+		if err := configureEndpoints(cfg); err != nil {
+			return err
+		}
+		if err := configureExtensions(cfg); err != nil {
+			return err
+		}
+
+		startUpdateCheck(cmd, cfg)
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Dispatch unknown subcommands to vfm-<name> plugins on PATH
+	if runPlugin(os.Args[1:]) {
+		return
+	}
+
+	err := rootCmd.Execute()
+	shutdownTracing(err)
+	writeDebugHAR()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+	} else {
+		printUpdateNotice()
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output (same as --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "diagnostic log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "also append diagnostic logs as JSON lines to this file")
+	rootCmd.PersistentFlags().IntVar(&quotaPerMin, "quota", 0, "VTEX requests per minute to stay below (warns at 80%)")
+	rootCmd.PersistentFlags().BoolVar(&quotaThrottle, "throttle", false, "delay requests instead of exceeding --quota")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "ca-cert", "", "PEM file with extra CA certificates to trust (e.g. a TLS-intercepting proxy)")
+	rootCmd.PersistentFlags().BoolVar(&insecureTLS, "insecure-skip-verify", false, "disable TLS certificate verification (unsafe, last resort)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "connection profile from the config file (or VFM_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&appKey, "app-key", "", "VTEX App Key to authenticate with instead of the VTEX CLI session (or VTEX_APP_KEY)")
+	rootCmd.PersistentFlags().StringVar(&appToken, "app-token", "", "VTEX App Token for --app-key (or VTEX_APP_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&sessionAccount, "account", "", "account to upload to, using its cached VTEX CLI token or --app-key (or VTEX_ACCOUNT)")
+	rootCmd.PersistentFlags().StringVar(&sessionWorkspace, "workspace", "", "workspace to use (or VTEX_WORKSPACE; default: current workspace, or master for other accounts)")
+	rootCmd.PersistentFlags().StringVar(&debugHAR, "debug-har", "", "record every VTEX request and response to this HAR file (credentials redacted)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "retries", client.DefaultMaxRetries, "times to retry requests failing with HTTP 429, 5xx or network errors (0 disables retries)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors and emoji in the output (also with NO_COLOR)")
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "language of messages: en, pt-BR, es or auto for the system locale (default from VFM_LANG or config, else en)")
+	rootCmd.PersistentFlags().StringVar(&environment, "environment", "", "VTEX environment of the account: stable or beta (default from config, or stable)")
+	rootCmd.PersistentFlags().StringVar(&urlDomain, "url-domain", "", "domain to print and log file URLs with instead of {account}.vtexassets.com (e.g. mystore.vteximg.com.br)")
+	rootCmd.PersistentFlags().Float64Var(&maxFileSizeMB, "max-file-size", client.MaxFileSize/(1024*1024), "maximum file size to upload, in MB, for accounts that accept larger files")
+
+	rootCmd.PersistentFlags().Float64Var(&injectFailureRate, "inject-failure-rate", 0, "fraction of requests to fail locally with HTTP 503 (testing only)")
+	rootCmd.PersistentFlags().DurationVar(&injectLatency, "inject-latency", 0, "latency added to every request (testing only)")
+	rootCmd.PersistentFlags().MarkHidden("inject-failure-rate")
+	rootCmd.PersistentFlags().MarkHidden("inject-latency")
+}
+
+// writeDebugHAR saves the requests recorded with --debug-har, also when the
+// command failed
+func writeDebugHAR() {
+	if debugHAR == "" {
+		return
+	}
+	if err := client.DefaultHARRecorder.WriteFile(debugHAR, version); err != nil {
+		color.Yellow(symbols("⚠️  %v"), err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Recorded %d request(s) to %s\n", client.DefaultHARRecorder.Count(), debugHAR)
+}
+
+// loadConfig loads the config file once for all the configure steps. Commands
+// that don't use it, such as version, logs and help, still run with a warning
+// when it is malformed, so the file can be fixed with their help.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err == nil {
+		return cfg, nil
+	}
+	switch cmd.Name() {
+	case "version", "logs", "help", "completion", "__complete", "update":
+		color.Yellow(symbols("⚠️  Ignoring the config file: %v"), err)
+		return &config.Config{}, nil
+	}
+	return nil, err
+}
+
+// configureLanguage selects the language of messages from --lang, VFM_LANG or
+// the config file, in that order. Messages stay in English unless one of them
+// asks otherwise, so scripts parsing the output keep working on any locale;
+// "auto" follows the system locale. Porcelain output is always in English, as
+// its error fields are part of the stable format.
+func configureLanguage(cmd *cobra.Command, cfg *config.Config) error {
+	if porcelain := cmd.Flags().Lookup("porcelain"); porcelain != nil && porcelain.Changed {
+		return i18n.SetLanguage(i18n.English)
+	}
+
+	if lang := firstNonEmpty(language, os.Getenv("VFM_LANG")); lang != "" {
+		return setLanguage(lang)
+	}
+	if cfg.Language != "" {
+		if err := setLanguage(cfg.Language); err != nil {
+			return fmt.Errorf("language in config: %w", err)
+		}
+		return nil
+	}
+	return i18n.SetLanguage(i18n.English)
+}
+
+// setLanguage selects a language by tag or locale, or the system locale's for "auto"
+func setLanguage(lang string) error {
+	if strings.EqualFold(lang, "auto") {
+		lang = i18n.Detect()
+	}
+	return i18n.SetLanguage(lang)
+}
+
+// configureQuota applies request quota settings from flags, falling back to the config file
+func configureQuota(cmd *cobra.Command, cfg *config.Config) error {
+	limit := cfg.Quota.RequestsPerMinute
+	if cmd.Flags().Changed("quota") {
+		limit = quotaPerMin
+	}
+	throttle := cfg.Quota.Throttle
+	if cmd.Flags().Changed("throttle") {
+		throttle = quotaThrottle
+	}
+	if throttle && limit <= 0 {
+		return fmt.Errorf("--throttle requires a request quota (--quota or quota.requestsPerMinute in config)")
+	}
+
+	client.DefaultQuotaTracker.ConfigureQuota(limit, throttle)
+	client.DefaultQuotaTracker.OnWarn = func(count, limit int) {
+		if throttle {
+			color.Yellow(symbols("⚠️  Approaching VTEX request quota: %d/%d requests in the last minute (throttling enabled)"), count, limit)
+		} else {
+			color.Yellow(symbols("⚠️  Approaching VTEX request quota: %d/%d requests in the last minute"), count, limit)
+		}
+	}
+
+	return nil
+}
+
+// configureRetries applies the retry settings from flags, falling back to the config file
+func configureRetries(cmd *cobra.Command, cfg *config.Config) error {
+	retries := client.DefaultMaxRetries
+	if cfg.Retry.MaxRetries != nil {
+		retries = *cfg.Retry.MaxRetries
+	}
+	if cmd.Flags().Changed("retries") {
+		retries = maxRetries
+	}
+	if retries < 0 {
+		return fmt.Errorf("--retries must not be negative")
+	}
+
+	client.DefaultRetryPolicy.Configure(retries)
+	client.DefaultRetryPolicy.OnRetry = func(req *http.Request, attempt int, delay time.Duration, reason string) {
+		color.Yellow(symbols("↻ %s %s failed (%s), retrying in %s (attempt %d/%d)"), req.Method, req.URL.Path, reason, delay.Round(100*time.Millisecond), attempt, retries+1)
+	}
+	client.DefaultRetryPolicy.OnPause = func(delay time.Duration) {
+		color.Yellow(symbols("⏸  VTEX asked to retry after %s: pausing all requests"), delay.Round(time.Second))
+	}
+
+	return nil
+}
+
+// configureFileSize applies the maximum file size from flags, falling back to the config file
+func configureFileSize(cmd *cobra.Command, cfg *config.Config) error {
+	if cmd.Flags().Changed("max-file-size") {
+		size := int64(maxFileSizeMB * 1024 * 1024)
+		if size <= 0 {
+			return fmt.Errorf("--max-file-size must be greater than 0")
+		}
+		client.SetMaxFileSize(size)
+		return nil
+	}
+
+	// 0 in the config file keeps the default limit
+	if cfg.Limits.MaxFileSizeMB < 0 {
+		return fmt.Errorf("limits.maxFileSizeMB in config must not be negative")
+	}
+	client.SetMaxFileSize(int64(cfg.Limits.MaxFileSizeMB * 1024 * 1024))
+	return nil
+}
+
+// configureURLDomains applies the URL domain from flags, falling back to the
+// per-account domains of the config file
+func configureURLDomains(cfg *config.Config) error {
+	for account, defaults := range cfg.Accounts {
+		if defaults.URLDomain == "" {
+			continue
+		}
+		domain, err := parseURLDomain(defaults.URLDomain)
+		if err != nil {
+			return fmt.Errorf("invalid accounts.%s.urlDomain in config: %w", account, err)
+		}
+		client.SetURLDomain(account, domain)
+	}
+
+	if urlDomain != "" {
+		domain, err := parseURLDomain(urlDomain)
+		if err != nil {
+			return fmt.Errorf("invalid --url-domain: %w", err)
+		}
+		client.SetURLDomain("", domain)
+	}
+	return nil
+}
+
+// configureEnvironments applies the VTEX environment from flags, falling back
+// to the per-account environments of the config file
+func configureEnvironments(cfg *config.Config) error {
+	for account, defaults := range cfg.Accounts {
+		if defaults.Environment == "" {
+			continue
+		}
+		if err := client.SetEnvironment(account, defaults.Environment); err != nil {
+			return fmt.Errorf("accounts.%s.environment in config: %w", account, err)
+		}
+	}
+
+	if environment != "" {
+		if err := client.SetEnvironment("", environment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureEndpoints applies the API base URL overrides of the config file
+// or the VFM_CMS_ENDPOINT and VFM_GRAPHQL_ENDPOINT environment variables
+func configureEndpoints(cfg *config.Config) error {
+	if err := client.SetEndpoints(cfg.Endpoints.CMS, cfg.Endpoints.GraphQL); err != nil {
+		return fmt.Errorf("endpoints in config: %w", err)
+	}
+	if cfg.Endpoints.CMS != "" || cfg.Endpoints.GraphQL != "" {
+		slog.Info("using endpoint overrides", "cms", cfg.Endpoints.CMS, "graphql", cfg.Endpoints.GraphQL)
+	}
+	return nil
+}
+
+// parseURLDomain accepts a host, optionally given as an https:// URL without a path
+func parseURLDomain(value string) (string, error) {
+	host := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(value, "https://"), "http://"), "/")
+	if host == "" || strings.ContainsAny(host, "/?#@ ") {
+		return "", fmt.Errorf("%q is not a domain", value)
+	}
+	return host, nil
+}
+
+// configureExtensions applies the per-method extension lists from the config file
+func configureExtensions(cfg *config.Config) error {
+	for method, extensions := range cfg.Extensions {
+		if method != "cms" && method != "graphql" {
+			return fmt.Errorf("invalid method %q in extensions config (must be 'graphql' or 'cms')", method)
+		}
+		client.ConfigureExtensions(method, extensions.Add, extensions.Remove, extensions.Only)
+	}
+
+	return nil
+}
+
+// printRequestStats prints request counters collected by the shared transport
+func printRequestStats() {
+	current, peak, total := client.DefaultQuotaTracker.Stats()
+	fmt.Printf("Requests:        %d total, %d in last minute, peak %d/min\n", total, current, peak)
 }
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/auth"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/glinharesb/vtex-files-manager/pkg/credentials"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/spf13/cobra"
+)
+
+// Session selection, from flags or environment variables
+var (
+	appKey           string
+	appToken         string
+	sessionAccount   string
+	sessionWorkspace string
+	profileName      string
+)
+
+// loadSession returns the session commands upload with: an App Key session when
+// an App Key is given by flag or VTEX_APP_KEY, otherwise the VTEX CLI session
+// for --account (the current account by default)
+func loadSession() (*vtexcli.VTEXSession, error) {
+	account := firstNonEmpty(sessionAccount, os.Getenv("VTEX_ACCOUNT"))
+	workspace := firstNonEmpty(sessionWorkspace, os.Getenv("VTEX_WORKSPACE"))
+	return loadSessionFor(account, workspace)
+}
+
+// currentAccount returns the account loadSession selects, without loading or
+// verifying its credentials
+func currentAccount() string {
+	if account := firstNonEmpty(sessionAccount, os.Getenv("VTEX_ACCOUNT")); account != "" {
+		return account
+	}
+	if session, err := vtexcli.LoadSession(); err == nil {
+		return session.Account
+	}
+	return ""
+}
+
+// loadSessionFor returns the session for account and workspace, as loadSession
+// does for --account and --workspace. Empty values select the current ones.
+func loadSessionFor(account, workspace string) (*vtexcli.VTEXSession, error) {
+	key := firstNonEmpty(appKey, os.Getenv("VTEX_APP_KEY"))
+	token := firstNonEmpty(appToken, os.Getenv("VTEX_APP_TOKEN"))
+
+	// Credentials stored with 'vfm auth login' complete a missing App Token
+	if token == "" && account != "" {
+		if stored := storedCredentials(account); stored != nil && (key == "" || key == stored.AppKey) {
+			key, token = stored.AppKey, stored.AppToken
+		}
+	}
+
+	if key != "" || token != "" {
+		session, err := vtexcli.NewAppKeySession(account, workspace, key, token)
+		if err != nil {
+			return nil, i18n.Errorf("authentication failed: %w", err)
+		}
+		return session, verifySession(session)
+	}
+	session, err := vtexcli.LoadSessionForAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	if workspace != "" {
+		session.Workspace = workspace
+	}
+
+	// Validate token before proceeding
+	if err := session.ValidateToken(); err != nil {
+		return nil, i18n.Errorf("authentication failed: %w. Please run 'vtex login' and try again", err)
+	}
+
+	if remaining := time.Until(session.ExpiresAt); !session.ExpiresAt.IsZero() && remaining < tokenExpiryWarning {
+		color.Yellow(symbols(i18n.T("⚠️  Your VTEX session expires in %s. Run 'vtex login' to renew it before long uploads.")), remaining.Round(time.Minute))
+	}
+	return session, verifySession(session)
+}
+
+// storedCredentials returns the credentials stored in the OS keyring for account,
+// or nil when there are none or the keyring is not available
+func storedCredentials(account string) *credentials.AppKey {
+	stored, err := credentials.Load(account)
+	if err != nil {
+		slog.Warn("could not read stored credentials", "account", account, "error", err)
+	}
+	return stored
+}
+
+// tokenExpiryWarning is how close to its expiry a session token triggers a warning
+const tokenExpiryWarning = 30 * time.Minute
+
+// estimatedUploadDuration is a conservative time per file and worker, used to
+// tell whether a batch can finish before the session expires
+const estimatedUploadDuration = 2 * time.Second
+
+// checkSessionOutlivesBatch refuses to start a batch of files that will likely
+// still be running when the session token expires
+func checkSessionOutlivesBatch(session *vtexcli.VTEXSession, files, concurrency int) error {
+	if session.ExpiresAt.IsZero() || concurrency < 1 {
+		return nil
+	}
+
+	estimate := time.Duration((files+concurrency-1)/concurrency) * estimatedUploadDuration
+	if remaining := time.Until(session.ExpiresAt); estimate > remaining {
+		return fmt.Errorf("this batch of %d files may take about %s, but your VTEX session expires in %s. Please run 'vtex login' and try again",
+			files, estimate.Round(time.Minute), remaining.Round(time.Minute))
+	}
+	return nil
+}
+
+// sessionCheckTimeout bounds the VTEX ID check made before any work starts
+const sessionCheckTimeout = 15 * time.Second
+
+// verifySession checks the session with VTEX ID so expired credentials fail up
+// front: user tokens with the authenticated user, App Keys with an App Key
+// login. When VTEX ID cannot be reached, a warning is printed and work goes on;
+// the uploads themselves still detect expired sessions.
+func verifySession(session *vtexcli.VTEXSession) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionCheckTimeout)
+	defer cancel()
+
+	var user string
+	var err error
+	if session.AppKey != "" {
+		err = client.VerifyAppKey(ctx, session.Account, session.AppKey, session.AppToken)
+	} else {
+		user, err = client.VerifySession(ctx, session.Account, newAuthenticator(session))
+	}
+	switch {
+	case errors.Is(err, client.ErrAuthFailed) && session.AppKey != "":
+		return fmt.Errorf("authentication failed: VTEX rejected App Key %s for account %s (%v)", session.AppKey, session.Account, err)
+	case errors.Is(err, client.ErrAuthFailed):
+		return i18n.Errorf("authentication failed: your VTEX session for %s has expired (%v). Please run 'vtex login %s' and try again", session.Account, err, session.Account)
+	case err != nil:
+		color.Yellow(symbols(i18n.T("⚠️  Could not verify the VTEX session: %v")), err)
+	default:
+		slog.Info("VTEX session verified", "account", session.Account, "user", firstNonEmpty(user, session.Login))
+	}
+	return nil
+}
+
+// newAuthenticator returns the authenticator for a session
+func newAuthenticator(session *vtexcli.VTEXSession) *auth.Authenticator {
+	if session.AppKey != "" {
+		return auth.NewAppKeyAuthenticator(session.AppKey, session.AppToken)
+	}
+	return auth.NewAuthenticator(session.Token)
+}
+
+// applyProfile sets the flags of cmd from the named config profile. Flags given
+// on the command line take precedence over the profile. Only commands that
+// upload use profiles: elsewhere flags such as logs --method are filters, and
+// a profile would silently narrow them.
+func applyProfile(cmd *cobra.Command, cfg *config.Config, name string) error {
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in the config file", name)
+	}
+	switch cmd.Name() {
+	case "upload", "batch", "sync", "deploy", "enqueue", "retry", "daemon", "bridge", "grpc", "token":
+	default:
+		return nil
+	}
+
+	values := map[string]string{
+		"account":   profile.Account,
+		"workspace": profile.Workspace,
+		"method":    profile.Method,
+		"app-key":   profile.AppKey,
+	}
+	if profile.Concurrency > 0 {
+		values["concurrent"] = strconv.Itoa(profile.Concurrency)
+	}
+	if profile.AppKey != "" {
+		values["app-token"] = os.Getenv(firstNonEmpty(profile.AppTokenEnv, "VTEX_APP_TOKEN"))
+	}
+
+	for flag, value := range values {
+		f := cmd.Flags().Lookup(flag)
+		if value == "" || f == nil || f.Changed {
+			continue
+		}
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s in profile %q: %w", flag, name, err)
+		}
+	}
+	return nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
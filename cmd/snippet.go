@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/imageurl"
+)
+
+// snippetWidths are the srcset candidate widths, in pixels
+var snippetWidths = []int{480, 800, 1200, 1600}
+
+// validateSnippetFormat checks a --snippet value
+func validateSnippetFormat(format string) error {
+	switch format {
+	case "", "html", "picture":
+		return nil
+	default:
+		return fmt.Errorf("invalid --snippet value: %s (must be 'html' or 'picture')", format)
+	}
+}
+
+// resizedURL returns fileURL resized on the fly by the VTEX image server to
+// the given width, keeping the aspect ratio
+func resizedURL(fileURL string, width int) string {
+	resized, err := imageurl.Transform(fileURL, imageurl.Options{Width: width, Aspect: true})
+	if err != nil {
+		return fileURL
+	}
+	return resized
+}
+
+// snippetCandidateWidths returns the srcset widths that do not upscale the
+// image, always including its intrinsic width
+func snippetCandidateWidths(intrinsic int) []int {
+	var widths []int
+	for _, width := range snippetWidths {
+		if width < intrinsic {
+			widths = append(widths, width)
+		}
+	}
+	return append(widths, intrinsic)
+}
+
+// printSnippets prints a ready-to-paste <img> or <picture> block for every
+// uploaded raster image, using VTEX resize URLs as srcset candidates
+func printSnippets(format string, results []*client.UploadResult) {
+	for _, result := range results {
+		if !result.Success || result.Image == nil || result.Image.Width == 0 ||
+			strings.EqualFold(filepath.Ext(result.FileName), ".svg") {
+			continue
+		}
+		fmt.Printf("<!-- %s -->\n", result.FileName)
+		if format == "picture" {
+			printPictureSnippet(result)
+		} else {
+			printImgSnippet(result)
+		}
+		fmt.Println()
+	}
+}
+
+// snippetAlt derives a placeholder alt text from the file name
+func snippetAlt(fileName string) string {
+	alt := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(alt)
+}
+
+func printImgSnippet(result *client.UploadResult) {
+	widths := snippetCandidateWidths(result.Image.Width)
+	candidates := make([]string, len(widths))
+	for i, width := range widths {
+		candidates[i] = fmt.Sprintf("%s %dw", html.EscapeString(resizedURL(result.FileURL, width)), width)
+	}
+
+	fmt.Printf("<img src=\"%s\"\n", html.EscapeString(result.FileURL))
+	fmt.Printf("     srcset=\"%s\"\n", strings.Join(candidates, ",\n             "))
+	fmt.Printf("     sizes=\"(max-width: %dpx) 100vw, %dpx\"\n", result.Image.Width, result.Image.Width)
+	fmt.Printf("     width=\"%d\" height=\"%d\" alt=\"%s\" loading=\"lazy\">\n",
+		result.Image.Width, result.Image.Height, html.EscapeString(snippetAlt(result.FileName)))
+}
+
+func printPictureSnippet(result *client.UploadResult) {
+	widths := snippetCandidateWidths(result.Image.Width)
+
+	fmt.Println("<picture>")
+	for _, width := range widths[:len(widths)-1] {
+		fmt.Printf("  <source media=\"(max-width: %dpx)\" srcset=\"%s\">\n", width, html.EscapeString(resizedURL(result.FileURL, width)))
+	}
+	fmt.Printf("  <img src=\"%s\" width=\"%d\" height=\"%d\" alt=\"%s\" loading=\"lazy\">\n",
+		html.EscapeString(result.FileURL), result.Image.Width, result.Image.Height, html.EscapeString(snippetAlt(result.FileName)))
+	fmt.Println("</picture>")
+}
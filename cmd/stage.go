@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// stageOptions are the transformations applied to a copy of each file before
+// upload; the local originals are never modified
+type stageOptions struct {
+	Resize   *resizeOptions   `json:"resize,omitempty"`
+	Convert  *convertOptions  `json:"convert,omitempty"`
+	Optimize *optimizeOptions `json:"optimize,omitempty"`
+	Minify   bool             `json:"minify,omitempty"` // CSS and JS files
+}
+
+// enabled reports whether any transformation is configured
+func (o stageOptions) enabled() bool {
+	return o.Resize != nil || o.Convert != nil || o.Optimize != nil || o.Minify
+}
+
+// newStagingDir creates the directory transformed copies are written to. It
+// returns an empty path and a no-op cleanup when no transformation is enabled.
+func newStagingDir(opts stageOptions) (string, func(), error) {
+	if !opts.enabled() {
+		return "", func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "vfm-stage-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }, nil
+}
+
+// stageFile applies the pre-upload transformations to a copy of path inside
+// stagingDir. It returns the path to upload (path itself when nothing
+// changed) with the original and staged sizes.
+func stageFile(path, stagingDir string, opts stageOptions) (string, int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path, 0, 0, err
+	}
+	uploadPath, size := path, info.Size()
+
+	if opts.Resize != nil {
+		content, err := resizeImage(path, opts.Resize)
+		if err != nil {
+			return path, 0, 0, fmt.Errorf("failed to resize %s: %w", filepath.Base(path), err)
+		}
+		if content != nil {
+			staged, err := writeStaged(stagingDir, filepath.Ext(path), content)
+			if err != nil {
+				return path, 0, 0, fmt.Errorf("failed to stage %s: %w", filepath.Base(path), err)
+			}
+			uploadPath, size = staged, int64(len(content))
+		}
+	}
+
+	if opts.Convert != nil && isConvertible(path) {
+		converted, err := convertToWebP(uploadPath, stagingDir, opts.Convert)
+		if err != nil {
+			return path, 0, 0, fmt.Errorf("failed to convert %s: %w", filepath.Base(path), err)
+		}
+		convertedInfo, err := os.Stat(converted)
+		if err != nil {
+			return path, 0, 0, err
+		}
+		uploadPath, size = converted, convertedInfo.Size()
+	}
+
+	if opts.Optimize != nil {
+		content, err := optimizeImage(uploadPath, opts.Optimize)
+		if err != nil {
+			return path, 0, 0, fmt.Errorf("failed to optimize %s: %w", filepath.Base(path), err)
+		}
+		if content != nil && int64(len(content)) < size {
+			staged, err := writeStaged(stagingDir, filepath.Ext(uploadPath), content)
+			if err != nil {
+				return path, 0, 0, fmt.Errorf("failed to stage %s: %w", filepath.Base(path), err)
+			}
+			uploadPath, size = staged, int64(len(content))
+		}
+	}
+
+	if opts.Minify {
+		content, err := minifyFile(uploadPath)
+		if err != nil {
+			return path, 0, 0, fmt.Errorf("failed to minify %s: %w", filepath.Base(path), err)
+		}
+		if content != nil && int64(len(content)) < size {
+			staged, err := writeStaged(stagingDir, filepath.Ext(uploadPath), content)
+			if err != nil {
+				return path, 0, 0, fmt.Errorf("failed to stage %s: %w", filepath.Base(path), err)
+			}
+			uploadPath, size = staged, int64(len(content))
+		}
+	}
+
+	return uploadPath, info.Size(), size, nil
+}
+
+// writeStaged writes content to a new file with the given extension in stagingDir
+func writeStaged(stagingDir, ext string, content []byte) (string, error) {
+	staged, err := os.CreateTemp(stagingDir, "*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer staged.Close()
+	if _, err := staged.Write(content); err != nil {
+		return "", err
+	}
+	return staged.Name(), nil
+}
+
+// printStageSavings prints how much the pre-upload transformations changed the
+// size of the uploaded files
+func printStageSavings(results []*client.UploadResult) {
+	var files int
+	var before, after int64
+	for _, result := range results {
+		if result.Success && result.UploadedSize > 0 {
+			files++
+			before += result.OriginalSize
+			after += result.UploadedSize
+		}
+	}
+	if files == 0 {
+		fmt.Println("Transformed:     no file changed")
+		fmt.Println()
+		return
+	}
+	fmt.Printf("Transformed:     %d file(s), %.2f KB → %.2f KB (%+.0f%%)\n",
+		files, float64(before)/1024, float64(after)/1024, 100*float64(after-before)/float64(before))
+	fmt.Println()
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/state"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+)
+
+var syncSchedule string
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <directory>",
+	Short: "Publish new and changed files of a directory, once or on a schedule",
+	Long: `Upload the files of a directory that are new or changed since they were
+last uploaded (tracked in ` + state.FileName + ` inside the directory), without
+asking for confirmation. It is the same as 'vfm batch --incremental -y'.
+
+With --schedule, vfm keeps running and syncs the directory every time the cron
+expression matches (minute hour day-of-month month day-of-week, in local time;
+@hourly, @daily and @every 30m also work). Each run is logged with its outcome;
+a failed run does not stop the schedule. Press Ctrl+C to stop.
+
+The session is loaded again for every run. VTEX CLI tokens expire after a day,
+so long-running schedules should authenticate with --app-key.
+
+Examples:
+  vfm sync ./shared/assets -m cms
+  vfm sync ./shared/assets -m cms -r --schedule "0 3 * * *"
+  vfm sync ./banners -m graphql --schedule "@every 30m" --app-key vtexappkey-store-ABCDEF`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVarP(&batchMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
+	syncCmd.Flags().IntVarP(&concurrency, "concurrent", "c", 3, "maximum number of concurrent uploads")
+	syncCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "recursively search subdirectories")
+	syncCmd.Flags().StringArrayVar(&batchExcludes, "exclude", nil, "skip files matching a glob (repeatable)")
+	syncCmd.Flags().StringSliceVar(&batchExtensions, "ext", nil, "only upload files with these extensions (e.g. jpg,png,svg)")
+	syncCmd.Flags().StringVar(&batchReport, "report", "", "write the per-file outcome of each run as JSON to this path")
+	syncCmd.Flags().StringVar(&batchNotifyURL, "notify-url", "", "POST a JSON summary of each run to this webhook URL")
+	syncCmd.Flags().StringVar(&syncSchedule, "schedule", "", "keep running and sync on this cron schedule (e.g. \"0 3 * * *\")")
+	syncCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9464; with --schedule)")
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	batchIncremental = true
+	batchSkipConfirm = true
+
+	if syncSchedule == "" {
+		if metricsAddr != "" {
+			return fmt.Errorf("--metrics-addr requires --schedule")
+		}
+		return runBatch(cmd, args)
+	}
+
+	schedule, err := cron.ParseStandard(syncSchedule)
+	if err != nil {
+		return fmt.Errorf("invalid --schedule: %w", err)
+	}
+	var metricsListener net.Listener
+	if metricsAddr != "" {
+		if metricsListener, err = serveMetrics(metricsAddr); err != nil {
+			return err
+		}
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== VTEX Scheduled Sync ===")
+	fmt.Printf("Directory:     %s\n", args[0])
+	fmt.Printf("Schedule:      %s\n", syncSchedule)
+	if metricsListener != nil {
+		fmt.Printf("Metrics:       http://%s/metrics\n", metricsListener.Addr())
+	}
+	fmt.Println("Press Ctrl+C to stop.")
+
+	for {
+		next := schedule.Next(time.Now())
+		fmt.Printf("\nNext run:      %s\n", next.Format("2006-01-02 15:04:05 MST"))
+
+		if !waitUntil(cmd.Context(), next) {
+			color.Yellow("\nScheduled sync stopped.")
+			return nil
+		}
+		if err := runScheduledSync(cmd, args); errors.Is(err, errInterrupted) {
+			return err
+		}
+	}
+}
+
+// waitUntil sleeps until t and reports false when Ctrl-C or ctx ended the wait
+// first. Runs handle Ctrl-C themselves, so the signals are only caught here.
+func waitUntil(parent context.Context, t time.Time) bool {
+	ctx, stop := signal.NotifyContext(parent, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// runScheduledSync performs one sync of a schedule and logs its outcome
+func runScheduledSync(cmd *cobra.Command, args []string) error {
+	startedAt := time.Now()
+	color.Cyan("\n[%s] Sync started", startedAt.Format(time.DateTime))
+	slog.Info("scheduled sync started", "directory", args[0])
+
+	err := runBatch(cmd, args)
+	elapsed := time.Since(startedAt).Round(time.Second)
+	if err != nil {
+		color.Red("[%s] Sync failed after %s: %v", time.Now().Format(time.DateTime), elapsed, err)
+		slog.Error("scheduled sync failed", "directory", args[0], "duration", elapsed, "error", err)
+		return err
+	}
+
+	color.Green("[%s] Sync finished in %s", time.Now().Format(time.DateTime), elapsed)
+	slog.Info("scheduled sync finished", "directory", args[0], "duration", elapsed)
+	return nil
+}
@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	themeMethod      string
+	themeSkipConfirm bool
+	themeDryRun      bool
+	themePrefix      string
+	themeConcurrency int
+	themeOnConflict  string
+	themeFileType    string
+)
+
+// jsonStringPattern matches JSON string literals without escape sequences,
+// which is how asset paths are written in block files
+var jsonStringPattern = regexp.MustCompile(`"([^"\\\n]*)"`)
+
+var themeCmd = &cobra.Command{
+	Use:   "theme",
+	Short: "Work with VTEX IO store themes",
+}
+
+var themeDeployCmd = &cobra.Command{
+	Use:   "deploy <theme-directory>",
+	Short: "Upload the assets of a store theme and rewrite their references",
+	Long: `Find the local assets referenced by a VTEX IO store theme, upload them and
+rewrite the references to the resulting URLs, so the theme serves them from
+the VTEX file storage instead of bundling them with the app.
+
+References are looked up in:
+  store/**/*.json, *.jsonc   string values such as "src": "assets/hero.png"
+  styles/**/*.css            url(...) references
+
+A reference is an asset when it points to a file inside the theme with a
+supported extension, relative to the file that references it or to the theme
+root (VTEX IO's "assets/..." convention). Absolute URLs are left alone.
+
+Theme files are rewritten in place after the uploads; only references to
+files that were uploaded successfully are changed, and comments and
+formatting are kept. Commit the theme before deploying so the changes are
+easy to review, then run 'vtex link' or 'vtex publish' as usual.
+
+Remote names are the asset file names (with --prefix prepended). Assets with
+the same name in different directories get their directories in the name
+(assets/home/hero.png → assets_home_hero.png).
+
+Remote names are shared by the whole account, so with --method cms the deploy
+stops when an asset name is already taken (--on-conflict fail). Use
+--on-conflict skip to keep the published files (the references to them stay
+local), rename to upload under a free suffixed name, or overwrite to replace
+them after a confirmation.
+
+Examples:
+  vfm theme deploy ./store-theme -m cms --dry-run
+  vfm theme deploy ./store-theme -m cms -y
+  vfm theme deploy . -m graphql --prefix mystore-`,
+	Args: cobra.ExactArgs(1),
+	RunE: runThemeDeploy,
+}
+
+func init() {
+	rootCmd.AddCommand(themeCmd)
+	themeCmd.AddCommand(themeDeployCmd)
+	themeDeployCmd.Flags().StringVarP(&themeMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
+	themeDeployCmd.Flags().BoolVarP(&themeSkipConfirm, "yes", "y", false, "skip confirmation prompt")
+	themeDeployCmd.Flags().BoolVar(&themeDryRun, "dry-run", false, "show the assets and files that would change without uploading")
+	themeDeployCmd.Flags().StringVar(&themePrefix, "prefix", "", "prefix for the remote names of the assets (e.g. mystore-)")
+	themeDeployCmd.Flags().IntVarP(&themeConcurrency, "concurrent", "c", 3, "maximum number of concurrent uploads")
+	themeDeployCmd.Flags().StringVar(&themeOnConflict, "on-conflict", "fail", "what to do with assets that already exist: fail, skip, rename or overwrite (cms only)")
+	themeDeployCmd.Flags().StringVar(&themeFileType, "file-type", client.DefaultCMSFileType, "CMS file area (fileType): auto, images, others or files (served from /files) (cms only)")
+}
+
+// themeScan holds the local assets referenced by a store theme
+type themeScan struct {
+	Root   string
+	Assets []string       // absolute paths of the referenced assets, sorted
+	Files  map[string]int // theme file → number of asset references
+}
+
+func runThemeDeploy(cmd *cobra.Command, args []string) error {
+	if themeConcurrency < 1 {
+		return fmt.Errorf("--concurrent must be at least 1")
+	}
+	onConflict := themeOnConflict
+	if onConflict != "skip" && onConflict != "overwrite" && onConflict != "rename" && onConflict != "fail" {
+		return fmt.Errorf("invalid --on-conflict value: %s (must be 'skip', 'overwrite', 'rename' or 'fail')", onConflict)
+	}
+	if err := client.ValidateCMSFileType(themeFileType); err != nil {
+		return err
+	}
+
+	scan, err := scanTheme(args[0])
+	if err != nil {
+		return err
+	}
+	if len(scan.Assets) == 0 {
+		color.Yellow("No local assets are referenced by the theme in %s.", scan.Root)
+		return nil
+	}
+
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+	method, bucket, err := resolveUploadDefaults(themeMethod, session.Account)
+	if err != nil {
+		return err
+	}
+	if method != "cms" {
+		if cmd.Flags().Changed("on-conflict") {
+			return fmt.Errorf("--on-conflict requires --method cms (GraphQL generates unique file names)")
+		}
+		if cmd.Flags().Changed("file-type") {
+			return fmt.Errorf("--file-type requires --method cms")
+		}
+		// GraphQL file names never collide
+		onConflict = "overwrite"
+	}
+
+	names := themeRemoteNames(scan.Root, scan.Assets, themePrefix)
+	files := make([]batchFile, len(scan.Assets))
+	for i, asset := range scan.Assets {
+		relPath, _ := filepath.Rel(scan.Root, asset)
+		files[i] = batchFile{Path: asset, RelPath: relPath, RemoteName: names[asset], Method: method}
+	}
+
+	// Skip and rename resolve conflicts per file during the upload
+	authenticator := newAuthenticator(session)
+	resolveInWorker := onConflict == "skip" || onConflict == "rename"
+	existingFiles := []string{}
+	if !resolveInWorker || themeDryRun {
+		existingFiles = findExistingFiles(cmd.Context(), files, session.Account, session.Workspace, authenticator, themeConcurrency)
+	}
+
+	infoColor := color.New(color.FgCyan, color.Bold)
+	fmt.Println()
+	infoColor.Println("=== VTEX Store Theme Deploy ===")
+	fmt.Printf("Theme:         %s\n", scan.Root)
+	fmt.Printf("Account:       %s\n", session.Account)
+	fmt.Printf("Workspace:     %s\n", session.Workspace)
+	fmt.Printf("Method:        %s\n", method)
+	fmt.Printf("Assets:        %d, referenced from %d file(s)\n", len(files), len(scan.Files))
+	fmt.Println()
+	for i, f := range files {
+		fmt.Printf("  %d. %s\n", i+1, filepath.ToSlash(f.RelPath))
+		fmt.Printf("     → %s\n", destinationURL(session.Account, method, themeFileType, f.RemoteName))
+	}
+	fmt.Println()
+
+	if len(existingFiles) > 0 {
+		switch onConflict {
+		case "fail":
+			color.Red(symbols("✗ %d asset name(s) are already taken by published files:"), len(existingFiles))
+		case "overwrite":
+			color.Yellow(symbols("⚠️  WARNING: %d published file(s) will be OVERWRITTEN:"), len(existingFiles))
+		case "skip":
+			color.Yellow("%d asset(s) already exist and will be SKIPPED:", len(existingFiles))
+		case "rename":
+			color.Yellow("%d asset(s) already exist and will be RENAMED:", len(existingFiles))
+		}
+		for _, f := range existingFiles {
+			fmt.Printf("  • %s\n", f)
+		}
+		fmt.Println()
+	} else if resolveInWorker {
+		action := "skipped"
+		if onConflict == "rename" {
+			action = "renamed"
+		}
+		fmt.Printf("Existing files will be checked and %s during upload.\n\n", action)
+	}
+	if onConflict == "fail" && len(existingFiles) > 0 && !themeDryRun {
+		return fmt.Errorf("%d asset name(s) already exist (--on-conflict fail); use --prefix, or --on-conflict skip, rename or overwrite", len(existingFiles))
+	}
+
+	if themeDryRun {
+		fmt.Println("Files that would be rewritten:")
+		for _, path := range sortedThemeFiles(scan) {
+			relPath, _ := filepath.Rel(scan.Root, path)
+			fmt.Printf("  • %s (%d reference(s))\n", filepath.ToSlash(relPath), scan.Files[path])
+		}
+		fmt.Println()
+		color.Yellow("Dry run: no files were uploaded or rewritten.")
+		return nil
+	}
+
+	if err := checkSessionOutlivesBatch(session, len(files), themeConcurrency); err != nil {
+		return err
+	}
+	if !themeSkipConfirm {
+		prompt := i18n.Sprintf("Upload %d asset(s) and rewrite the references in %d theme file(s)?", len(files), len(scan.Files))
+		if onConflict == "overwrite" && len(existingFiles) > 0 {
+			prompt = i18n.Sprintf("%d published file(s) will be overwritten. Continue?", len(existingFiles))
+		}
+		if !askConfirmation(prompt) {
+			color.Yellow(i18n.T("Deploy cancelled."))
+			return nil
+		}
+		fmt.Println()
+	}
+
+	opts := batchOptions{
+		Account:       session.Account,
+		Workspace:     session.Workspace,
+		Authenticator: authenticator,
+		Bucket:        bucket,
+		Concurrency:   themeConcurrency,
+		OnConflict:    onConflict,
+		FileType:      themeFileType,
+	}
+
+	// Ctrl-C from here on cancels the uploads in flight instead of killing the process
+	ctx, stop := notifyInterrupt(cmd.Context())
+	defer stop()
+
+	startedAt := time.Now()
+	results, abortErr := uploadFilesWithConcurrency(ctx, files, opts)
+	printBatchSummary(results, len(files), time.Since(startedAt))
+
+	// Rewrite the references to the assets that were uploaded
+	urls := make(map[string]string, len(results))
+	failed := 0
+	for _, result := range results {
+		if result.Success {
+			urls[result.FilePath] = result.FileURL
+		} else if !result.Skipped {
+			failed++
+		}
+	}
+	if len(urls) > 0 {
+		fmt.Println()
+		for _, path := range sortedThemeFiles(scan) {
+			content, count, err := rewriteThemeFile(scan.Root, path, urls)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("failed to rewrite %s: %w", path, err)
+			}
+			if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to rewrite %s: %w", path, err)
+			}
+			relPath, _ := filepath.Rel(scan.Root, path)
+			fmt.Printf("Rewrote %d reference(s) in %s\n", count, filepath.ToSlash(relPath))
+		}
+	}
+
+	if abortErr != nil {
+		return abortErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d asset(s) failed to upload; their references were left unchanged", failed)
+	}
+	return nil
+}
+
+// scanTheme finds the local assets referenced by the block files and
+// stylesheets of the store theme in dir
+func scanTheme(dir string) (*themeScan, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &themeScan{Root: root, Files: map[string]int{}}
+	assets := map[string]bool{}
+	found := false
+	for _, sub := range []string{"store", "styles"} {
+		subDir := filepath.Join(root, sub)
+		if info, err := os.Stat(subDir); err != nil || !info.IsDir() {
+			continue
+		}
+		found = true
+
+		err := filepath.WalkDir(subDir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !isThemeFile(path) {
+				return nil
+			}
+			refs, err := themeFileRefs(root, path)
+			if err != nil {
+				return err
+			}
+			if len(refs) > 0 {
+				scan.Files[path] = len(refs)
+			}
+			for _, ref := range refs {
+				assets[ref] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan theme: %w", err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not a VTEX IO store theme: it has no store/ or styles/ directory", root)
+	}
+
+	for asset := range assets {
+		scan.Assets = append(scan.Assets, asset)
+	}
+	sort.Strings(scan.Assets)
+	return scan, nil
+}
+
+// isThemeFile reports whether a theme file can reference assets
+func isThemeFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonc", ".css":
+		return true
+	}
+	return false
+}
+
+// themeFileRefs returns the absolute paths of the assets a theme file references
+func themeFileRefs(root, path string) ([]string, error) {
+	var refs []string
+	_, _, err := replaceThemeRefs(root, path, func(asset string) (string, bool) {
+		refs = append(refs, asset)
+		return "", false
+	})
+	return refs, err
+}
+
+// rewriteThemeFile returns the content of a theme file with the references to
+// assets in urls (keyed by absolute path) replaced by their URLs, and the
+// number of replaced references
+func rewriteThemeFile(root, path string, urls map[string]string) ([]byte, int, error) {
+	return replaceThemeRefs(root, path, func(asset string) (string, bool) {
+		url, ok := urls[asset]
+		return url, ok
+	})
+}
+
+// replaceThemeRefs calls replace with the absolute path of each asset
+// referenced by a theme file, replacing the reference with the returned URL
+// when it reports true. Query strings and fragments of references are kept.
+func replaceThemeRefs(root, path string, replace func(asset string) (string, bool)) ([]byte, int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	fileDir := filepath.Dir(path)
+
+	count := 0
+	replaceRef := func(ref string) (string, bool) {
+		asset, suffix, ok := resolveThemeRef(root, fileDir, ref)
+		if !ok {
+			return "", false
+		}
+		url, ok := replace(asset)
+		if !ok {
+			return "", false
+		}
+		count++
+		return url + suffix, true
+	}
+
+	if isStylesheet(path) {
+		content = cssURLPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+			parts := cssURLPattern.FindSubmatch(match)
+			if url, ok := replaceRef(string(parts[2])); ok {
+				return []byte(fmt.Sprintf(`url("%s")`, url))
+			}
+			return match
+		})
+		return content, count, nil
+	}
+
+	var rewritten []byte
+	last := 0
+	for _, loc := range jsonStringPattern.FindAllSubmatchIndex(content, -1) {
+		// Skip object keys
+		if bytes.HasPrefix(bytes.TrimLeft(content[loc[1]:], " \t\r\n"), []byte(":")) {
+			continue
+		}
+		url, ok := replaceRef(string(content[loc[2]:loc[3]]))
+		if !ok {
+			continue
+		}
+		rewritten = append(rewritten, content[last:loc[2]]...)
+		rewritten = append(rewritten, url...)
+		last = loc[3]
+	}
+	return append(rewritten, content[last:]...), count, nil
+}
+
+// resolveThemeRef returns the absolute path of the theme asset a reference
+// points to, with its query string or fragment, and false when it is not a
+// local asset. References are resolved against the referencing file's
+// directory, then against the theme root.
+func resolveThemeRef(root, fileDir, ref string) (string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	lower := strings.ToLower(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(lower, "data:") || strings.HasPrefix(ref, "//") || strings.Contains(ref, "://") {
+		return "", "", false
+	}
+
+	suffix := ""
+	if idx := strings.IndexAny(ref, "?#"); idx >= 0 {
+		ref, suffix = ref[:idx], ref[idx:]
+	}
+	if !client.ExtensionAllowed("", filepath.Ext(ref)) {
+		return "", "", false
+	}
+
+	candidates := []string{filepath.Join(root, filepath.FromSlash(ref))}
+	if !strings.HasPrefix(ref, "/") {
+		candidates = append([]string{filepath.Join(fileDir, filepath.FromSlash(ref))}, candidates...)
+	}
+	for _, candidate := range candidates {
+		// Never upload files outside the theme
+		if rel, err := filepath.Rel(root, candidate); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, suffix, true
+		}
+	}
+	return "", "", false
+}
+
+// themeRemoteNames returns the remote name of each asset: its file name, or its
+// path relative to the theme root joined by "_" when file names collide
+func themeRemoteNames(root string, assets []string, prefix string) map[string]string {
+	byName := map[string]int{}
+	for _, asset := range assets {
+		byName[filepath.Base(asset)]++
+	}
+
+	names := make(map[string]string, len(assets))
+	for _, asset := range assets {
+		name := filepath.Base(asset)
+		if byName[name] > 1 {
+			relPath, _ := filepath.Rel(root, asset)
+			name = strings.ReplaceAll(filepath.ToSlash(relPath), "/", "_")
+		}
+		names[asset] = prefix + name
+	}
+	return names
+}
+
+// sortedThemeFiles returns the theme files with asset references in order
+func sortedThemeFiles(scan *themeScan) []string {
+	paths := make([]string, 0, len(scan.Files))
+	for path := range scan.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
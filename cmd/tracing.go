@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingShutdownTimeout bounds the export of the spans left when vfm exits
+const tracingShutdownTimeout = 5 * time.Second
+
+var (
+	// tracerProvider exports spans when OTLP tracing is configured, nil otherwise
+	tracerProvider *sdktrace.TracerProvider
+
+	// commandSpan is the root span of the command, parent of every upload span
+	commandSpan trace.Span
+)
+
+// tracingConfigured reports whether the standard OTLP environment variables
+// ask for traces to be exported
+func tracingConfigured() bool {
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "none" || os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// configureTracing registers an OTLP/HTTP trace exporter configured from the
+// OTEL_* environment variables and starts the root span of cmd. Without an
+// endpoint, spans are not recorded.
+func configureTracing(cmd *cobra.Command) error {
+	if tracerProvider != nil || !tracingConfigured() {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override these attributes
+	res, err := resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName("vfm"),
+			semconv.ServiceVersion(version),
+		),
+		resource.Environment(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	ctx, span := otel.Tracer("github.com/glinharesb/vtex-files-manager/cmd").Start(cmd.Context(), cmd.CommandPath())
+	commandSpan = span
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// shutdownTracing ends the command span with err and exports the spans still
+// buffered before vfm exits
+func shutdownTracing(err error) {
+	if tracerProvider == nil {
+		return
+	}
+	if commandSpan != nil {
+		if err != nil {
+			commandSpan.RecordError(err)
+			commandSpan.SetStatus(codes.Error, err.Error())
+		}
+		commandSpan.End()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		slog.Warn("could not export traces", "error", err)
+	}
+}
@@ -1,18 +1,45 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
+	"github.com/inconshreveable/go-update"
 	"github.com/rhysd/go-github-selfupdate/selfupdate"
 	"github.com/spf13/cobra"
 )
 
+const (
+	releasesRepo = "glinharesb/vtex-files-manager"
+
+	// checksumsFileName is the file GoReleaser publishes with the SHA-256
+	// checksums of the release archives
+	checksumsFileName = "checksums.txt"
+
+	// Update channels
+	channelStable     = "stable"
+	channelPrerelease = "prerelease"
+)
+
 var (
-	checkOnly bool
-	forceUpdate bool
+	checkOnly      bool
+	forceUpdate    bool
+	updateChannel  string
+	updateRollback bool
 )
 
 var updateCmd = &cobra.Command{
@@ -21,12 +48,25 @@ var updateCmd = &cobra.Command{
 	Long: `Update vfm to the latest version available on GitHub Releases.
 
 This command checks for new versions and automatically downloads and installs
-the latest binary for your platform.
+the latest binary for your platform. The downloaded archive is checked against
+the SHA-256 checksums published with the release (checksums.txt) before the
+executable is replaced; the update is aborted when they don't match.
+
+The replaced binary is kept next to the new one (vfm.previous, or
+vfm.previous.exe on Windows), so a broken release can be backed out with
+--rollback. Rolling back swaps the two binaries: running it again restores
+the newer version.
+
+Channels:
+  stable       the latest release (default)
+  prerelease   the latest release including pre-releases (e.g. 1.5.0-rc.1)
 
 Examples:
-  vfm update                 # Update to latest version
-  vfm update --check         # Only check for updates, don't install
-  vfm update --force         # Force update even if same version`,
+  vfm update                        # Update to latest version
+  vfm update --check                # Only check for updates, don't install
+  vfm update --force                # Force update even if same version
+  vfm update --channel prerelease   # Try the next version before it is released
+  vfm update --rollback             # Restore the binary replaced by the last update`,
 	RunE: runUpdate,
 }
 
@@ -34,6 +74,8 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 	updateCmd.Flags().BoolVarP(&checkOnly, "check", "c", false, "only check for updates, don't install")
 	updateCmd.Flags().BoolVarP(&forceUpdate, "force", "f", false, "force update even if same version")
+	updateCmd.Flags().StringVar(&updateChannel, "channel", channelStable, "release channel: stable or prerelease")
+	updateCmd.Flags().BoolVar(&updateRollback, "rollback", false, "restore the binary replaced by the last update")
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
@@ -47,25 +89,29 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
-	fmt.Printf("%s Checking for updates...\n", cyan("ℹ"))
-	fmt.Printf("Current version: %s\n", currentVersion)
+	if updateRollback {
+		if checkOnly || forceUpdate || cmd.Flags().Changed("channel") {
+			return fmt.Errorf("--rollback cannot be combined with --check, --force or --channel")
+		}
+		return rollbackUpdate()
+	}
+	if updateChannel != channelStable && updateChannel != channelPrerelease {
+		return fmt.Errorf("invalid --channel value: %s (must be '%s' or '%s')", updateChannel, channelStable, channelPrerelease)
+	}
 
-	// Create self-updater
-	updater, err := selfupdate.NewUpdater(selfupdate.Config{
-		Filters: []string{}, // No filters, use all assets
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create updater: %w", err)
+	fmt.Printf("%s Checking for updates...\n", cyan(symbols("ℹ")))
+	fmt.Printf("Current version: %s\n", currentVersion)
+	if updateChannel != channelStable {
+		fmt.Printf("Channel:         %s\n", updateChannel)
 	}
 
-	// Check for latest release
-	latest, found, err := updater.DetectLatest("glinharesb/vtex-files-manager")
+	latest, found, err := detectLatestRelease(updateChannel)
 	if err != nil {
-		return fmt.Errorf("failed to check for updates: %w", err)
+		return err
 	}
 
 	if !found {
-		fmt.Printf("%s No releases found\n", yellow("⚠"))
+		fmt.Printf("%s No releases found\n", yellow(symbols("⚠")))
 		return nil
 	}
 
@@ -74,7 +120,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// Check if update is needed
 	if currentVersion == latestVersion && !forceUpdate {
-		fmt.Printf("\n%s You're already on the latest version!\n", green("✓"))
+		fmt.Printf("\n%s You're already on the latest version!\n", green(symbols("✓")))
 		return nil
 	}
 
@@ -84,7 +130,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		if err == nil {
 			// If current version >= latest version, no update needed
 			if currentSemVer.GTE(latest.Version) {
-				fmt.Printf("\n%s You're already on the latest version (or newer)!\n", green("✓"))
+				fmt.Printf("\n%s You're already on the latest version (or newer)!\n", green(symbols("✓")))
 				return nil
 			}
 		}
@@ -92,39 +138,213 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// If check-only mode, stop here
 	if checkOnly {
-		fmt.Printf("\n%s New version available: %s → %s\n", cyan("ℹ"), currentVersion, latestVersion)
+		fmt.Printf("\n%s New version available: %s → %s\n", cyan(symbols("ℹ")), currentVersion, latestVersion)
 		fmt.Printf("Run 'vfm update' to install the latest version\n")
 		return nil
 	}
 
 	// Confirm update
 	if !forceUpdate {
-		fmt.Printf("\n%s Update available: %s → %s\n", yellow("⚠"), currentVersion, latestVersion)
-		fmt.Printf("Do you want to update? [y/N]: ")
-
-		var response string
-		fmt.Scanln(&response)
-
-		if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
-			fmt.Println("Update cancelled")
+		fmt.Printf("\n%s Update available: %s → %s\n", yellow(symbols("⚠")), currentVersion, latestVersion)
+		if !askConfirmation(i18n.T("Do you want to update?")) {
+			fmt.Println(i18n.T("Update cancelled"))
 			return nil
 		}
 	}
 
 	// Perform update
-	fmt.Printf("\n%s Downloading update...\n", cyan("⬇"))
+	fmt.Printf("\n%s Downloading update...\n", cyan(symbols("⬇")))
 
 	exe, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	if err := updater.UpdateTo(latest, exe); err != nil {
+	if err := installRelease(latest, exe); err != nil {
 		return fmt.Errorf("failed to update binary: %w", err)
 	}
 
-	fmt.Printf("%s Successfully updated to version %s!\n", green("✓"), latestVersion)
+	fmt.Printf("%s Successfully updated to version %s!\n", green(symbols("✓")), latestVersion)
+	fmt.Printf("Previous version kept at %s (restore it with 'vfm update --rollback')\n", previousBinaryPath(exe))
 	fmt.Printf("\nRelease notes: %s\n", latest.ReleaseNotes)
 
 	return nil
 }
+
+// detectLatestRelease finds the latest release of vfm on GitHub Releases. The
+// prerelease channel also considers pre-releases, which selfupdate skips.
+func detectLatestRelease(channel string) (*selfupdate.Release, bool, error) {
+	updater, err := selfupdate.NewUpdater(selfupdate.Config{
+		Filters: []string{}, // No filters, use all assets
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create updater: %w", err)
+	}
+
+	if channel == channelPrerelease {
+		tag, err := latestPrereleaseTag()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to check for updates: %w", err)
+		}
+		if tag == "" {
+			return nil, false, nil
+		}
+		latest, found, err := updater.DetectVersion(releasesRepo, tag)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to check for updates: %w", err)
+		}
+		return latest, found, nil
+	}
+
+	latest, found, err := updater.DetectLatest(releasesRepo)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	return latest, found, nil
+}
+
+// latestPrereleaseTag returns the tag of the highest version among the
+// published releases, pre-releases included, or "" when there is none
+func latestPrereleaseTag() (string, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Get("https://api.github.com/repos/" + releasesRepo + "/releases?per_page=50")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		Draft   bool   `json:"draft"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	var latestTag string
+	var latestVersion semver.Version
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		ver, err := semver.Parse(strings.TrimPrefix(release.TagName, "v"))
+		if err != nil {
+			continue
+		}
+		if latestTag == "" || ver.GT(latestVersion) {
+			latestTag, latestVersion = release.TagName, ver
+		}
+	}
+	return latestTag, nil
+}
+
+// isNewerVersion reports whether latest is newer than the current version.
+// Versions that are not semver (e.g. dev builds) are never outdated.
+func isNewerVersion(current string, latest semver.Version) bool {
+	currentSemVer, err := semver.Parse(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return false
+	}
+	return latest.GT(currentSemVer)
+}
+
+// installRelease downloads the archive of release, checks it against the
+// checksums published with it and replaces the executable at exe with the
+// binary inside
+func installRelease(release *selfupdate.Release, exe string) error {
+	archive, err := downloadReleaseFile(release.AssetURL)
+	if err != nil {
+		return err
+	}
+
+	// The checksums file is published next to the archives of the release
+	baseURL := release.AssetURL[:strings.LastIndex(release.AssetURL, "/")+1]
+	checksums, err := downloadReleaseFile(baseURL + checksumsFileName)
+	if err != nil {
+		return fmt.Errorf("failed to download %s to verify the update: %w", checksumsFileName, err)
+	}
+	assetName := release.AssetURL[len(baseURL):]
+	if err := verifyChecksum(archive, assetName, checksums); err != nil {
+		return err
+	}
+
+	binary, err := selfupdate.UncompressCommand(bytes.NewReader(archive), release.AssetURL, filepath.Base(exe))
+	if err != nil {
+		return err
+	}
+	return update.Apply(binary, update.Options{TargetPath: exe, OldSavePath: previousBinaryPath(exe)})
+}
+
+// previousBinaryPath returns where an update keeps the binary it replaced
+func previousBinaryPath(exe string) string {
+	ext := filepath.Ext(exe)
+	return strings.TrimSuffix(exe, ext) + ".previous" + ext
+}
+
+// rollbackUpdate swaps the running binary with the one kept by the last update
+func rollbackUpdate() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	previousPath := previousBinaryPath(exe)
+
+	previous, err := os.ReadFile(previousPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no previous version to roll back to (%s not found)", previousPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read previous version: %w", err)
+	}
+
+	// The current binary takes the place of the previous one, so rolling
+	// back again returns to it
+	if err := update.Apply(bytes.NewReader(previous), update.Options{TargetPath: exe, OldSavePath: previousPath}); err != nil {
+		return fmt.Errorf("failed to roll back: %w", err)
+	}
+
+	color.Green(symbols("✓ Rolled back from version %s to the previous binary"), version)
+	fmt.Printf("Version %s kept at %s (run 'vfm update --rollback' again to restore it)\n", version, previousPath)
+	return nil
+}
+
+// downloadReleaseFile downloads a file published with a release
+func downloadReleaseFile(url string) ([]byte, error) {
+	httpClient := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// verifyChecksum checks data against its SHA-256 checksum in a checksums file
+// with "<hex digest>  <file name>" lines, as written by sha256sum and GoReleaser
+func verifyChecksum(data []byte, name string, checksums []byte) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if !strings.EqualFold(fields[0], actual) {
+			return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, downloaded file has %s", name, fields[0], actual)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s has no checksum for %s", checksumsFileName, name)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/blang/semver"
+	"github.com/fatih/color"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	updateCheckFileName = "vtex-files-manager/update-check.json"
+
+	// updateCheckInterval is how long the latest version found is reused
+	// before GitHub Releases is asked again
+	updateCheckInterval = 24 * time.Hour
+
+	// updateCheckWait is how long a finished command waits for a check still
+	// in progress, so a slow network never delays the prompt for long
+	updateCheckWait = 2 * time.Second
+)
+
+// stderrIsTerminal reports whether update notices can reach a user
+var stderrIsTerminal = term.IsTerminal(int(os.Stderr.Fd()))
+
+// updateCheckResult receives the latest released version from the passive
+// update check, and is closed without a value when the check found nothing.
+// It is nil when no check was started.
+var updateCheckResult chan semver.Version
+
+// updateCheckState records the last passive update check
+type updateCheckState struct {
+	CheckedAt     time.Time `json:"checkedAt"`
+	LatestVersion string    `json:"latestVersion"`
+}
+
+// startUpdateCheck looks up the latest release in the background when the
+// updateCheck config option (or VFM_UPDATE_CHECK) is enabled. It is skipped
+// for dev builds, in CI, when stderr is not a terminal and for the commands
+// that check for updates themselves.
+func startUpdateCheck(cmd *cobra.Command, cfg *config.Config) {
+	switch cmd.Name() {
+	case "update", "version", "completion", "help", "__complete":
+		return
+	}
+	if version == "dev" || os.Getenv("CI") != "" || !stderrIsTerminal {
+		return
+	}
+	if !cfg.UpdateCheck {
+		return
+	}
+
+	updateCheckResult = make(chan semver.Version, 1)
+	go func() {
+		defer close(updateCheckResult)
+
+		state, ok := loadUpdateCheck()
+		if ok && time.Since(state.CheckedAt) < updateCheckInterval {
+			if latest, err := semver.Parse(state.LatestVersion); err == nil {
+				updateCheckResult <- latest
+			}
+			return
+		}
+
+		latest, found, err := detectLatestRelease(channelStable)
+		if err != nil || !found {
+			// Record the attempt too, so offline or rate-limited hosts don't
+			// retry (and wait for the check) on every command
+			slog.Debug("update check failed", "error", err, "found", found)
+			saveUpdateCheck(state.LatestVersion)
+			return
+		}
+		saveUpdateCheck(latest.Version.String())
+		updateCheckResult <- latest.Version
+	}()
+}
+
+// printUpdateNotice tells the user about a newer version found by the update
+// check started with startUpdateCheck
+func printUpdateNotice() {
+	if updateCheckResult == nil {
+		return
+	}
+	select {
+	case latest, ok := <-updateCheckResult:
+		if ok && isNewerVersion(version, latest) {
+			fmt.Fprintln(os.Stderr)
+			color.New(color.FgYellow).Fprintf(os.Stderr, "A newer version of vfm is available: %s → %s\nRun 'vfm update' to install it.\n", version, latest)
+		}
+	case <-time.After(updateCheckWait):
+	}
+}
+
+// loadUpdateCheck reads the state of the last update check
+func loadUpdateCheck() (updateCheckState, bool) {
+	var state updateCheckState
+	path, err := xdg.SearchStateFile(updateCheckFileName)
+	if err != nil {
+		return state, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &state) != nil {
+		return state, false
+	}
+	return state, true
+}
+
+// saveUpdateCheck records the latest version found, so the passive check asks
+// GitHub Releases at most once per updateCheckInterval
+func saveUpdateCheck(latestVersion string) {
+	path, err := xdg.StateFile(updateCheckFileName)
+	if err != nil {
+		slog.Debug("failed to save update check", "error", err)
+		return
+	}
+	data, _ := json.Marshal(updateCheckState{CheckedAt: time.Now(), LatestVersion: latestVersion})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Debug("failed to save update check", "error", err)
+	}
+}
@@ -1,27 +1,52 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"net/url"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
-	"github.com/glinharesb/vtex-files-manager/pkg/auth"
 	"github.com/glinharesb/vtex-files-manager/pkg/client"
-	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+	"github.com/glinharesb/vtex-files-manager/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var (
-	uploadMethod string
-	skipConfirm  bool
+	uploadMethod    string
+	skipConfirm     bool
+	uploadDryRun    bool
+	uploadReceipt   string
+	uploadRehearse  string
+	uploadFileType  string
+	uploadBucket    string
+	uploadAs        string
+	uploadSlugify   bool
+	uploadVersion   string
+	uploadSkipSame  bool
+	uploadOutput    string
+	uploadPorcelain string
+	uploadFormat    string
+	uploadSnippet   string
+	uploadOptimize  string
+	uploadConvert   string
+	uploadResize    string
+	uploadMaxWidth  int
+	uploadMinify    bool
+	uploadNotify    string
+	uploadForce     bool
+	uploadMIME      string
+	uploadVerify    bool
+	uploadPurge     string
 )
 
 var uploadCmd = &cobra.Command{
-	Use:   "upload [file]",
-	Short: "Upload a single file to VTEX",
-	Long: `Upload a single image or file to your VTEX account.
+	Use:   "upload [file...]",
+	Short: "Upload one or more files to VTEX",
+	Long: `Upload one or more images or files to your VTEX account.
+Multiple files are uploaded in sequence after a single confirmation.
 
 Authentication:
   Uses VTEX CLI session. Run 'vtex login' first if not logged in.
@@ -29,138 +54,421 @@ Authentication:
 Supported file types:
   - Universal (both methods): jpg, jpeg, png, gif, svg, webp
   - CMS only: bmp, pdf, txt, json, xml, css, js
-Maximum file size: 5MB
+Maximum file size: 5MB (change it with --max-file-size)
+Other extensions are rejected unless --force-type is given with the MIME type
+to send as --content-type. VTEX may still refuse them.
 
 Upload Methods:
   graphql: Official GraphQL API - URLs: account.vtexassets.com/assets/.../uuid___hash.ext
   cms:     Legacy CMS FilePicker - URLs: account.vtexassets.com/arquivos/filename.ext
 
-Note: You must specify the --method flag. There is no default value.
+Note: You must specify the --method flag unless a default method is set
+for the account in the config file (accounts.<account>.method).
 
 Examples:
   vtex-files-manager upload image.jpg -m cms
   vtex-files-manager upload logo.png -m graphql -y
-  vtex-files-manager upload banner.jpg -m cms -v`,
-	Args: cobra.ExactArgs(1),
+  vtex-files-manager upload banner.jpg -m cms -v
+  vtex-files-manager upload a.png b.png c.svg -m graphql
+  vtex-files-manager upload checkout6-custom.css -m cms --file-type files
+  vtex-files-manager upload hero.png -m graphql --bucket my-app-assets
+  vtex-files-manager upload banner.jpg -m cms --dry-run
+  vtex-files-manager upload ./build/logo.final.v3.png --as logo.png -m cms
+  vtex-files-manager upload "Banner Verão 2024 (final).png" -m cms --slugify
+  vtex-files-manager upload theme.css -m cms --version-suffix timestamp
+  vtex-files-manager upload theme.css -m cms --skip-identical -y
+  vtex-files-manager upload checkout6-custom.js -m cms --verify
+  vtex-files-manager upload hero.jpg -m cms -y --snippet html
+  vtex-files-manager upload hero.png -m cms --optimize
+  vtex-files-manager upload hero.jpg -m cms --convert webp
+  vtex-files-manager upload hero.jpg -m cms --resize 1920x1080
+  vtex-files-manager upload theme.css app.js -m cms --minify
+  vtex-files-manager upload banner.jpg -m graphql --rehearse release-check
+  vtex-files-manager upload a.png b.png -m cms -y --receipt receipt.json
+  vtex-files-manager upload hero.png -m cms -y --notify-url https://hooks.example.com/vfm
+  vtex-files-manager upload icon.avif -m cms --force-type --content-type image/avif`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: runUpload,
 }
 
 func init() {
 	rootCmd.AddCommand(uploadCmd)
-	uploadCmd.Flags().StringVarP(&uploadMethod, "method", "m", "", "upload method: graphql or cms (required)")
+	uploadCmd.Flags().StringVarP(&uploadMethod, "method", "m", "", "upload method: graphql or cms (required unless set in config)")
 	uploadCmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "skip confirmation prompt")
+	uploadCmd.Flags().BoolVar(&uploadDryRun, "dry-run", false, "show what would be uploaded without uploading")
+	uploadCmd.Flags().StringVar(&uploadAs, "as", "", "destination file name (single file only)")
+	uploadCmd.Flags().BoolVar(&uploadSlugify, "slugify", false, "normalize destination names (lowercase, no accents, dashes instead of spaces)")
+	uploadCmd.Flags().StringVar(&uploadVersion, "version-suffix", "", "append a version tag to destination names for cache busting (\"timestamp\" for the current time)")
+	uploadCmd.Flags().BoolVar(&uploadSkipSame, "skip-identical", false, "skip files whose published copy already has the same content (cms only)")
+	uploadCmd.Flags().StringVar(&uploadPurge, "purge-list", "", "write the URLs of overwritten CMS files to this path, to purge them from the CDN cache")
+	uploadCmd.Flags().BoolVar(&uploadVerify, "verify", false, "download each uploaded file and fail the upload if it differs from the local file")
+	uploadCmd.Flags().StringVarP(&uploadOutput, "output", "o", "text", "result output format: text or markdown")
+	uploadCmd.Flags().StringVar(&uploadPorcelain, "porcelain", "", "print stable tab-separated results on stdout for scripts (format version: v1), other output goes to stderr")
+	uploadCmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	uploadCmd.Flags().StringVar(&uploadFormat, "format", "", "print each result with a Go template on stdout, e.g. '{{.FileName}} {{.FileURL}}'; other output goes to stderr")
+	uploadCmd.Flags().StringVar(&uploadOptimize, "optimize", "", "compress images before upload: lossless (PNG) or lossy[:quality] (PNG and JPEG)")
+	uploadCmd.Flags().Lookup("optimize").NoOptDefVal = "lossless"
+	uploadCmd.Flags().StringVar(&uploadResize, "resize", "", "downscale images to fit within WIDTHxHEIGHT before upload, keeping the aspect ratio")
+	uploadCmd.Flags().IntVar(&uploadMaxWidth, "max-width", 0, "downscale images wider than this many pixels before upload")
+	uploadCmd.Flags().BoolVar(&uploadMinify, "minify", false, "minify CSS and JS files before upload")
+	uploadCmd.Flags().StringVar(&uploadConvert, "convert", "", "transcode jpg/png files before upload: webp[:quality] (requires cwebp; banner.jpg is uploaded as banner.webp)")
+	uploadCmd.Flags().StringVar(&uploadSnippet, "snippet", "", "print a ready-to-paste HTML block for each uploaded image: html (<img srcset>) or picture")
+	uploadCmd.Flags().StringVar(&uploadBucket, "bucket", "", "GraphQL bucket to upload into (default from config, or images)")
+	uploadCmd.Flags().StringVar(&uploadFileType, "file-type", client.DefaultCMSFileType, "CMS file area (fileType): auto, images, others or files (served from /files) (cms only)")
+	uploadCmd.Flags().StringVar(&uploadRehearse, "rehearse", "", "perform the real upload against this disposable workspace (or the configured rehearsal account for cms)")
+	uploadCmd.Flags().StringVar(&uploadReceipt, "receipt", "", "write a signed receipt of the uploaded files to this path")
+	uploadCmd.Flags().StringVar(&uploadNotify, "notify-url", "", "POST a JSON summary of the upload to this webhook URL when it completes")
+	uploadCmd.Flags().BoolVar(&uploadForce, "force-type", false, "attempt extensions vfm has not tested, at your own risk (requires --content-type)")
+	uploadCmd.Flags().StringVar(&uploadMIME, "content-type", "", "MIME type to send files with when using --force-type (e.g. image/avif)")
 }
 
 func runUpload(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	if err := validateOutputFormat(uploadOutput); err != nil {
+		return err
+	}
+	if err := validateSnippetFormat(uploadSnippet); err != nil {
+		return err
+	}
+	if err := validatePorcelain(uploadPorcelain); err != nil {
+		return err
+	}
+	if err := validateNotifyURL(uploadNotify); err != nil {
+		return err
+	}
+	if err := validateForcedType(uploadForce, uploadMIME); err != nil {
+		return err
+	}
+	optimize, err := parseOptimize(uploadOptimize)
+	if err != nil {
+		return err
+	}
+	convert, err := parseConvert(uploadConvert)
+	if err != nil {
+		return err
+	}
+	resize, err := parseResize(uploadResize, uploadMaxWidth)
+	if err != nil {
+		return err
+	}
+	stage := stageOptions{Resize: resize, Convert: convert, Optimize: optimize, Minify: uploadMinify}
+	if err := startFormat(uploadFormat, uploadPorcelain); err != nil {
+		return err
+	}
+	if uploadPorcelain != "" {
+		startPorcelain()
+	}
 
-	// Validate method is specified
-	if uploadMethod == "" {
-		return fmt.Errorf("--method flag is required (must be 'graphql' or 'cms')")
+	// Resolve destination file names
+	remoteNames := make([]string, len(args))
+	for i, filePath := range args {
+		remoteNames[i] = filepath.Base(filePath)
+	}
+	if uploadAs != "" {
+		if len(args) > 1 {
+			return i18n.Errorf("--as can only be used when uploading a single file")
+		}
+		if err := validateRemoteName(uploadAs, args[0]); err != nil {
+			return err
+		}
+		remoteNames[0] = uploadAs
+	}
+	versionSuffix, err := resolveVersionSuffix(uploadVersion)
+	if err != nil {
+		return err
+	}
+	unslugged := append([]string(nil), remoteNames...)
+	var slugs []string
+	for i := range remoteNames {
+		if uploadSlugify {
+			remoteNames[i] = slugify(remoteNames[i])
+		}
+		slugs = append(slugs, remoteNames[i])
+		remoteNames[i] = convertedName(args[i], remoteNames[i], convert)
+		remoteNames[i] = appendVersionSuffix(remoteNames[i], versionSuffix)
 	}
 
-	// Validate method value
-	if uploadMethod != "graphql" && uploadMethod != "cms" {
-		return fmt.Errorf("invalid method: %s (must be 'graphql' or 'cms')", uploadMethod)
+	// Load the VTEX CLI or App Key session
+	session, err := loadSession()
+	if err != nil {
+		return err
 	}
 
-	// Load VTEX CLI session
-	session, err := vtexcli.LoadSession()
+	// Resolve upload method and bucket from flags or account defaults in config
+	method, bucket, err := resolveUploadDefaults(uploadMethod, session.Account)
 	if err != nil {
 		return err
 	}
+	if cmd.Flags().Changed("file-type") && method != "cms" {
+		return i18n.Errorf("--file-type requires --method cms")
+	}
+	// CMS files are published under their names; GraphQL generates unique URLs
+	if uploadSlugify && method == "cms" {
+		if err := checkSlugCollisions(unslugged, slugs); err != nil {
+			return err
+		}
+	}
+	if err := client.ValidateCMSFileType(uploadFileType); err != nil {
+		return err
+	}
+	if uploadSkipSame && method != "cms" {
+		return i18n.Errorf("--skip-identical requires --method cms")
+	}
+	if uploadBucket != "" {
+		if method != "graphql" {
+			return i18n.Errorf("--bucket requires --method graphql")
+		}
+		bucket = uploadBucket
+	}
 
-	// Validate token before proceeding
-	if err := session.ValidateToken(); err != nil {
-		return fmt.Errorf("authentication failed: %w. Please run 'vtex login' and try again", err)
+	// Redirect uploads to the rehearsal target
+	if uploadRehearse != "" {
+		if uploadDryRun {
+			return i18n.Errorf("--rehearse and --dry-run cannot be used together")
+		}
+		if err := applyRehearsal(session, uploadRehearse, method == "cms"); err != nil {
+			return err
+		}
 	}
 
 	// Create authenticator
-	authenticator := auth.NewAuthenticator(session.Token)
+	authenticator := newAuthenticator(session)
 
 	// Get file info for display
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to access file: %w", err)
+	fileInfos := make([]os.FileInfo, len(args))
+	for i, filePath := range args {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			return i18n.Errorf("failed to access file: %w", err)
+		}
+		fileInfos[i] = fileInfo
 	}
 
-	// Build destination URL
-	fileName := filepath.Base(filePath)
-	var destURL string
-	if uploadMethod == "cms" {
-		// Use URL encoding for filenames with spaces or special characters
-		encodedFileName := url.PathEscape(fileName)
-		destURL = fmt.Sprintf("https://%s.vtexassets.com/arquivos/%s", session.Account, encodedFileName)
-	} else {
-		destURL = fmt.Sprintf("https://%s.vtexassets.com/assets/.../[generated]", session.Account)
+	uploader := newUploader(method, session.Account, session.Workspace, authenticator, bucket, uploadFileType)
+	if uploadForce {
+		forceContentType(uploader, uploadMIME)
 	}
 
-	// Check if file exists (only for CMS method)
-	fileExists := false
-	if uploadMethod == "cms" {
-		cmsClient := client.NewCMSFilePickerClient(session.Account, session.Workspace, authenticator, verbose)
-		exists, err := cmsClient.CheckFileExists(fileName)
-		if err != nil && verbose {
-			fmt.Printf("Warning: Could not check if file exists: %v\n", err)
+	// Only CMS files keep their names, so only they can be compared with the published file
+	cmsClient, _ := uploader.(*client.CMSFilePickerClient)
+
+	// Check which files exist (only CMS files can conflict)
+	existing := make([]bool, len(args))
+	existingCount := 0
+	if cmsClient != nil {
+		for i, fileName := range remoteNames {
+			exists, err := uploader.Exists(cmd.Context(), fileName)
+			if err != nil {
+				slog.Warn("could not check if file exists", "file", fileName, "error", err)
+			}
+			existing[i] = exists
+			if exists {
+				existingCount++
+			}
 		}
-		fileExists = exists
 	}
 
 	// Display upload info
 	infoColor := color.New(color.FgCyan, color.Bold)
 	fmt.Println()
-	infoColor.Println("=== VTEX File Upload ===")
-	fmt.Printf("Account:       %s\n", session.Account)
-	fmt.Printf("Workspace:     %s\n", session.Workspace)
-	fmt.Printf("User:          %s\n", session.Login)
-	fmt.Printf("Method:        %s\n", uploadMethod)
-	fmt.Printf("File:          %s (%.2f KB)\n", fileName, float64(fileInfo.Size())/1024)
-	fmt.Printf("Destination:   %s\n", destURL)
+	infoColor.Println(i18n.T("=== VTEX File Upload ==="))
+	if rehearsing {
+		printRehearsalNotice(session)
+	}
+	fmt.Printf(i18n.T("Account:       %s\n"), session.Account)
+	fmt.Printf(i18n.T("Workspace:     %s\n"), session.Workspace)
+	fmt.Printf(i18n.T("User:          %s\n"), session.Login)
+	fmt.Printf(i18n.T("Method:        %s\n"), method)
+	if uploadForce {
+		color.Yellow(i18n.T("Content type:  %s (--force-type: untested file types may be rejected by VTEX)"), uploadMIME)
+	}
 
-	// Show warning if file exists
-	if fileExists {
-		color.Yellow("\n⚠️  WARNING: File already exists and will be OVERWRITTEN!")
+	if len(args) == 1 {
+		fileName := remoteNames[0]
+		if fileName != filepath.Base(args[0]) {
+			fmt.Printf(i18n.T("File:          %s → %s (%.2f KB)\n"), filepath.Base(args[0]), fileName, float64(fileInfos[0].Size())/1024)
+		} else {
+			fmt.Printf(i18n.T("File:          %s (%.2f KB)\n"), fileName, float64(fileInfos[0].Size())/1024)
+		}
+		fmt.Printf(i18n.T("Destination:   %s\n"), destinationURL(session.Account, method, uploadFileType, fileName))
+
+		// Show warning if file exists
+		if existing[0] {
+			color.Yellow(symbols(i18n.T("\n⚠️  WARNING: File already exists and will be OVERWRITTEN!")))
+		}
+	} else {
+		fmt.Printf(i18n.T("Files:         %d\n"), len(args))
+		for i, fileName := range remoteNames {
+			marker := ""
+			if existing[i] {
+				marker = color.YellowString(" (exists, will be OVERWRITTEN)")
+			}
+			fmt.Printf("  %d. %s (%.2f KB)%s\n", i+1, fileName, float64(fileInfos[i].Size())/1024, marker)
+			fmt.Printf("     → %s\n", destinationURL(session.Account, method, uploadFileType, fileName))
+		}
 	}
 
 	fmt.Println()
 
+	// Stop before uploading in dry-run mode
+	if uploadDryRun {
+		color.Yellow(i18n.T("Dry run: no files were uploaded."))
+		return nil
+	}
+
 	// Ask for confirmation unless --yes flag is set
 	if !skipConfirm {
-		promptMsg := "Proceed with upload?"
-		if fileExists {
-			promptMsg = "File exists. Overwrite?"
+		promptMsg := i18n.T("Proceed with upload?")
+		if len(args) == 1 && existingCount > 0 {
+			promptMsg = i18n.T("File exists. Overwrite?")
+		} else if existingCount > 0 {
+			promptMsg = i18n.Sprintf("%d file(s) will be overwritten. Continue?", existingCount)
 		}
 		if !askConfirmation(promptMsg) {
-			color.Yellow("Upload cancelled.")
+			color.Yellow(i18n.T("Upload cancelled."))
 			return nil
 		}
 		fmt.Println()
 	}
 
-	// Upload file based on method
-	var result *client.UploadResult
-	if uploadMethod == "cms" {
-		// Use CMS FilePicker client
-		cmsClient := client.NewCMSFilePickerClient(session.Account, session.Workspace, authenticator, verbose)
-		result, err = cmsClient.UploadFile(filePath, true)
-	} else {
-		// Use GraphQL client (default)
-		graphqlClient := client.NewGraphQLClient(session.Account, session.Workspace, authenticator, verbose)
-		result, err = graphqlClient.UploadFile(filePath, true)
-	}
-
+	// Transformed copies of files are staged here
+	stagingDir, cleanup, err := newStagingDir(stage)
 	if err != nil {
-		errorColor := color.New(color.FgRed, color.Bold)
-		errorColor.Printf("\n✗ Upload failed: %v\n", err)
 		return err
 	}
+	defer cleanup()
 
-	// Print success message
-	successColor := color.New(color.FgGreen, color.Bold)
-	fmt.Println()
-	successColor.Println("✓ Upload successful!")
-	fmt.Printf("File URL: %s\n", result.FileURL)
+	// Ctrl-C from here on cancels the upload in flight instead of killing the process
+	ctx, stop := notifyInterrupt(cmd.Context())
+	defer stop()
+
+	// Upload files in sequence
+	startedAt := time.Now()
+	results := make([]*client.UploadResult, 0, len(args))
+	var lastErr error
+	authFailures := 0
+	for i, filePath := range args {
+		if ctx.Err() != nil {
+			lastErr = errInterrupted
+			break
+		}
+
+		uploadPath, originalSize, stagedSize, err := stageFile(filePath, stagingDir, stage)
+		if err != nil {
+			lastErr = err
+			color.New(color.FgRed, color.Bold).Printf(symbols(i18n.T("\n✗ Upload failed: %v\n")), err)
+			results = append(results, &client.UploadResult{FileName: remoteNames[i], FilePath: filePath, Error: err})
+			continue
+		}
+		if uploadPath != filePath {
+			fmt.Printf(i18n.T("Transformed %s: %.2f KB → %.2f KB\n"), remoteNames[i], float64(originalSize)/1024, float64(stagedSize)/1024)
+		}
+
+		// Skip files that are already published with the same content
+		if uploadSkipSame && existing[i] {
+			identical, err := cmsClient.RemoteMatches(ctx, remoteNames[i], uploadPath)
+			if err != nil {
+				slog.Warn("could not compare file with the published file", "file", remoteNames[i], "error", err)
+			}
+			if identical {
+				color.Yellow(i18n.T("Skipping %s: the published file is identical"), remoteNames[i])
+				results = append(results, &client.UploadResult{FileName: remoteNames[i], FilePath: filePath, Skipped: true})
+				continue
+			}
+		}
+
+		start := time.Now()
+		result, err := uploader.Upload(ctx, client.UploadRequest{FilePath: uploadPath, FileName: remoteNames[i], ShowProgress: true, Verify: uploadVerify})
+		if isCancellation(ctx, err) {
+			lastErr = errInterrupted
+			break
+		}
+		result.Duration = time.Since(start)
+		result.FilePath = filePath
+		if uploadPath != filePath {
+			result.OriginalSize, result.UploadedSize = originalSize, stagedSize
+		}
+		results = append(results, result)
+
+		if err != nil {
+			lastErr = err
+			errorColor := color.New(color.FgRed, color.Bold)
+			errorColor.Printf(symbols(i18n.T("\n✗ Upload failed: %v\n")), err)
+
+			// Stop instead of repeating the same error for every remaining file
+			if errors.Is(err, client.ErrAuthFailed) {
+				authFailures++
+			} else {
+				authFailures = 0
+			}
+			if authFailures >= maxConsecutiveAuthFailures && i < len(args)-1 {
+				printSessionExpired(authFailures, len(args)-i-1, i18n.T("re-run the upload"))
+				break
+			}
+			continue
+		}
+		authFailures = 0
+
+		// Print success message
+		successColor := color.New(color.FgGreen, color.Bold)
+		fmt.Println()
+		successColor.Println(symbols(i18n.T("✓ Upload successful!")))
+		fmt.Printf(i18n.T("File URL: %s\n"), result.FileURL)
+		if dimensions := result.Dimensions(); dimensions != "" {
+			fmt.Printf(i18n.T("Image:    %s\n"), dimensions)
+		}
+	}
 	fmt.Println()
 
+	if uploadOutput == "markdown" {
+		printMarkdownMapping(results)
+	}
+	if porcelainOutput != nil {
+		writePorcelainResults(porcelainOutput, results)
+	}
+	if formatTemplate != nil {
+		if err := writeFormatted(results); err != nil {
+			return err
+		}
+	}
+	if uploadSnippet != "" {
+		printSnippets(uploadSnippet, results)
+	}
+
+	if uploadReceipt != "" {
+		if err := writeReceipt(uploadReceipt, session, results); err != nil {
+			return err
+		}
+	}
+	if uploadPurge != "" {
+		overwritten := map[string]bool{}
+		for i, name := range remoteNames {
+			overwritten[name] = existing[i]
+		}
+		if err := writePurgeList(uploadPurge, overwritten, results); err != nil {
+			return err
+		}
+	}
+
+	reportToGitHubActions("VTEX upload", results)
+
+	uploadedFiles := make([]batchFile, len(args))
+	for i, filePath := range args {
+		uploadedFiles[i] = batchFile{Path: filePath, RemoteName: remoteNames[i], Method: method}
+	}
+	notifyCompletion(uploadNotify, "upload", newBatchRunReport(session, startedAt, uploadedFiles, results))
+
+	if len(args) == 1 {
+		return lastErr
+	}
+
+	// Print summary for multiple files
+	printBatchSummary(results, len(args), time.Since(startedAt))
+
+	if errors.Is(lastErr, errInterrupted) {
+		return lastErr
+	}
+	if lastErr != nil {
+		return i18n.Errorf("one or more uploads failed")
+	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/imageurl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	transformWidth  int
+	transformHeight int
+	transformCrop   bool
+)
+
+var urlCmd = &cobra.Command{
+	Use:   "url",
+	Short: "Build URLs for uploaded files",
+}
+
+var urlTransformCmd = &cobra.Command{
+	Use:   "transform <url>",
+	Short: "Build a VTEX on-the-fly resize or crop URL for an uploaded image",
+	Long: `Build the URL of an uploaded image resized or cropped on the fly by the
+VTEX image server.
+
+Catalog image URLs (/arquivos/ids/{id}/...) get the {id}-{width}-{height}
+form, which requires both dimensions. Other vtexassets.com URLs get width,
+height and aspect query parameters; leave one dimension out to derive it
+from the aspect ratio. Sizes are limited to 4000 pixels.
+
+Examples:
+  vfm url transform https://mystore.vtexassets.com/arquivos/banner.jpg --width 800
+  vfm url transform https://mystore.vtexassets.com/arquivos/banner.jpg -W 400 -H 400 --crop
+  vfm url transform https://mystore.vtexassets.com/arquivos/ids/155392/shoe.jpg -W 500 -H 500`,
+	Args: cobra.ExactArgs(1),
+	RunE: runURLTransform,
+}
+
+func init() {
+	rootCmd.AddCommand(urlCmd)
+	urlCmd.AddCommand(urlTransformCmd)
+
+	urlTransformCmd.Flags().IntVarP(&transformWidth, "width", "W", 0, "target width in pixels")
+	urlTransformCmd.Flags().IntVarP(&transformHeight, "height", "H", 0, "target height in pixels")
+	urlTransformCmd.Flags().BoolVar(&transformCrop, "crop", false, "crop to exactly width x height instead of keeping the aspect ratio")
+}
+
+func runURLTransform(cmd *cobra.Command, args []string) error {
+	transformed, err := imageurl.Transform(args[0], imageurl.Options{
+		Width:  transformWidth,
+		Height: transformHeight,
+		Aspect: !transformCrop,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(transformed)
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var versionCheck bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the vfm version",
+	Long: `Print the version, commit and build date of vfm.
+
+With --check, also look up the latest release on GitHub Releases and report
+whether a newer version is available. To be told about new versions after
+normal commands, set "updateCheck": true in the config file (checked at most
+once a day).
+
+Examples:
+  vfm version
+  vfm version --check`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVarP(&versionCheck, "check", "c", false, "check whether a newer version is available")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	fmt.Printf("vfm version %s (commit %s, built %s)\n", version, commit, date)
+	if !versionCheck {
+		return nil
+	}
+
+	latest, found, err := detectLatestRelease(channelStable)
+	if err != nil {
+		return err
+	}
+	if !found {
+		color.Yellow(symbols("⚠ No releases found"))
+		return nil
+	}
+	saveUpdateCheck(latest.Version.String())
+
+	if isNewerVersion(version, latest.Version) {
+		color.Cyan(symbols("ℹ A newer version is available: %s → %s"), version, latest.Version)
+		fmt.Println("Run 'vfm update' to install it")
+		return nil
+	}
+	color.Green(symbols("✓ You're on the latest version (%s)"), latest.Version)
+	return nil
+}
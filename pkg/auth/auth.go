@@ -4,9 +4,12 @@ import (
 	"net/http"
 )
 
-// Authenticator handles authentication for VTEX API requests using VTEX CLI token
+// Authenticator handles authentication for VTEX API requests, using either a
+// VTEX CLI token or an App Key/App Token pair
 type Authenticator struct {
-	token string
+	token    string
+	appKey   string
+	appToken string
 }
 
 // NewAuthenticator creates a new authenticator with VTEX CLI token
@@ -16,13 +19,30 @@ func NewAuthenticator(token string) *Authenticator {
 	}
 }
 
-// AddAuthHeaders adds the authentication header to an HTTP request
+// NewAppKeyAuthenticator creates a new authenticator with a VTEX App Key and App Token,
+// for machines where nobody runs 'vtex login'
+func NewAppKeyAuthenticator(appKey, appToken string) *Authenticator {
+	return &Authenticator{
+		appKey:   appKey,
+		appToken: appToken,
+	}
+}
+
+// AddAuthHeaders adds the authentication headers to an HTTP request
 // VTEX CLI token is the same as VtexIdclientAutCookie
 func (a *Authenticator) AddAuthHeaders(req *http.Request) {
+	if a.appKey != "" {
+		req.Header.Set("X-VTEX-API-AppKey", a.appKey)
+		req.Header.Set("X-VTEX-API-AppToken", a.appToken)
+		return
+	}
 	req.Header.Set("VtexIdclientAutCookie", a.token)
 }
 
 // GetMethodName returns a human-readable name for the authentication method
 func (a *Authenticator) GetMethodName() string {
+	if a.appKey != "" {
+		return "VTEX App Key"
+	}
 	return "VTEX CLI Token"
 }
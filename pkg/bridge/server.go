@@ -0,0 +1,291 @@
+package bridge
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// maxPairingAttempts is the number of wrong pairing codes after which pairing
+// is locked until the bridge is restarted
+const maxPairingAttempts = 5
+
+// UploadFunc uploads a local file under the given remote name
+type UploadFunc func(filePath, fileName string) (*client.UploadResult, error)
+
+// Server exposes a localhost HTTP API that a paired browser extension can use to
+// upload images with the local VTEX session
+type Server struct {
+	pairingCode    string
+	sessionToken   string
+	paired         bool
+	failedPairings int
+	mu             sync.Mutex
+
+	info       map[string]string
+	upload     UploadFunc
+	uploadMu   sync.Mutex // upload clients are not safe for concurrent use
+	httpClient *http.Client
+}
+
+// NewServer creates a bridge server with a fresh one-time pairing code
+func NewServer(info map[string]string, upload UploadFunc) (*Server, error) {
+	code, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		pairingCode: strings.ToUpper(code),
+		info:        info,
+		upload:      upload,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}, nil
+}
+
+// PairingCode returns the one-time code the extension must present to pair,
+// in groups of four characters for reading (e.g. "1A2B-3C4D-5E6F-7A8B")
+func (s *Server) PairingCode() string {
+	var groups []string
+	for i := 0; i < len(s.pairingCode); i += 4 {
+		groups = append(groups, s.pairingCode[i:min(i+4, len(s.pairingCode))])
+	}
+	return strings.Join(groups, "-")
+}
+
+// Handler returns the HTTP handler for the bridge API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pair", s.handlePair)
+	mux.HandleFunc("/status", s.requireSession(s.handleStatus))
+	mux.HandleFunc("/upload", s.requireSession(s.handleUpload))
+	return requireLocalHost(withCORS(mux))
+}
+
+// handlePair exchanges the one-time pairing code for a session token. Only
+// browser extensions can pair: web pages cannot send an extension Origin, and
+// the JSON content type makes browsers send a CORS preflight first.
+func (s *Server) handlePair(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !isExtensionOrigin(r.Header.Get("Origin")) {
+		writeError(w, http.StatusForbidden, "pairing is only allowed from a browser extension")
+		return
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "content type must be application/json")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paired {
+		writeError(w, http.StatusConflict, "bridge already paired; restart 'vfm bridge' to pair again")
+		return
+	}
+	if s.failedPairings >= maxPairingAttempts {
+		writeError(w, http.StatusTooManyRequests, "pairing locked after too many invalid codes; restart 'vfm bridge' to pair again")
+		return
+	}
+	code := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(req.Code))
+	if subtle.ConstantTimeCompare([]byte(code), []byte(s.pairingCode)) != 1 {
+		s.failedPairings++
+		writeError(w, http.StatusUnauthorized, "invalid pairing code")
+		return
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate session token")
+		return
+	}
+	s.sessionToken = token
+	s.paired = true
+
+	writeJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+// handleStatus reports the VTEX account the bridge uploads to
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.info)
+}
+
+// handleUpload downloads the image at the given URL and uploads it to VTEX
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		URL  string `json:"url"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	imageURL, err := neturl.Parse(req.URL)
+	if err != nil || (imageURL.Scheme != "https" && imageURL.Scheme != "http") {
+		writeError(w, http.StatusBadRequest, "url must be an http(s) URL")
+		return
+	}
+
+	fileName := req.Name
+	if fileName == "" {
+		fileName = path.Base(imageURL.Path)
+	}
+	fileName = filepath.Base(fileName)
+
+	tmpDir, err := os.MkdirTemp("", "vfm-bridge-")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create temporary directory")
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	localPath := filepath.Join(tmpDir, fileName)
+	if err := s.download(imageURL.String(), localPath); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	s.uploadMu.Lock()
+	result, err := s.upload(localPath, fileName)
+	s.uploadMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"fileName": result.FileName,
+		"fileUrl":  result.FileURL,
+	})
+}
+
+// download fetches a remote image into localPath, enforcing the upload size limit
+func (s *Server) download(rawURL, localPath string) error {
+	resp, err := s.httpClient.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	out, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer out.Close()
+
+	// Read one byte past the limit so oversized files fail validation
+	if _, err := io.Copy(out, io.LimitReader(resp.Body, client.FileSizeLimit()+1)); err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	return nil
+}
+
+// requireSession rejects requests that don't carry the paired session token
+func (s *Server) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		token := s.sessionToken
+		s.mu.Unlock()
+
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "not paired")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireLocalHost rejects requests whose Host header is not the loopback
+// address the bridge listens on, so DNS rebinding pages cannot reach it
+func requireLocalHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "127.0.0.1" && host != "localhost" {
+			writeError(w, http.StatusForbidden, "invalid host")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isExtensionOrigin reports whether origin belongs to a browser extension
+func isExtensionOrigin(origin string) bool {
+	return strings.HasPrefix(origin, "chrome-extension://") || strings.HasPrefix(origin, "moz-extension://")
+}
+
+// withCORS allows browser extensions to call the bridge
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if isExtensionOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultInjector simulates failures and latency locally so automation built on
+// top of vfm can exercise its retry and alerting logic without hitting VTEX
+type FaultInjector struct {
+	mu          sync.Mutex
+	failureRate float64
+	latency     time.Duration
+}
+
+// DefaultFaultInjector is shared by all clients created in this process
+var DefaultFaultInjector = &FaultInjector{}
+
+// Configure sets the fraction of requests that fail (0 to 1) and the latency added to every request
+func (f *FaultInjector) Configure(failureRate float64, latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failureRate = failureRate
+	f.latency = latency
+}
+
+// settings returns the current failure rate and latency
+func (f *FaultInjector) settings() (float64, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failureRate, f.latency
+}
+
+// chaosTransport applies a FaultInjector to outgoing requests
+type chaosTransport struct {
+	base     http.RoundTripper
+	injector *FaultInjector
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	failureRate, latency := t.injector.settings()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	// Injected failures never reach VTEX
+	if failureRate > 0 && rand.Float64() < failureRate {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return &http.Response{
+			Status:     "503 Service Unavailable",
+			StatusCode: http.StatusServiceUnavailable,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("injected failure (vfm fault injection)")),
+			Request:    req,
+		}, nil
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -1,23 +1,118 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	// MaxFileSize is the maximum file size allowed (5MB)
+	// MaxFileSize is the default maximum file size allowed (5MB)
 	MaxFileSize = 5 * 1024 * 1024
 )
 
+// maxFileSize is the maximum file size allowed, changed with SetMaxFileSize
+var maxFileSize atomic.Int64
+
+func init() {
+	maxFileSize.Store(MaxFileSize)
+}
+
+// SetMaxFileSize changes the maximum file size allowed, for accounts that
+// accept files larger than MaxFileSize. A size of 0 restores MaxFileSize.
+func SetMaxFileSize(size int64) {
+	if size <= 0 {
+		size = MaxFileSize
+	}
+	maxFileSize.Store(size)
+}
+
+// FileSizeLimit returns the maximum file size allowed
+func FileSizeLimit() int64 {
+	return maxFileSize.Load()
+}
+
+// FormatMB formats a byte count in whole or fractional megabytes, e.g. "5MB"
+func FormatMB(size int64) string {
+	return strconv.FormatFloat(float64(size)/(1024*1024), 'f', -1, 64) + "MB"
+}
+
+// ErrAuthFailed is wrapped by errors caused by an expired or invalid VTEX session
+var ErrAuthFailed = errors.New("authentication failed")
+
+// ErrRejected is matched by errors of files that VTEX or the upload checks
+// refuse, such as unsupported or oversized files. Sending the same file again
+// fails the same way.
+var ErrRejected = errors.New("file rejected")
+
+// rejectedError is an error message that matches ErrRejected
+type rejectedError struct {
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return e.message
+}
+
+func (e *rejectedError) Is(target error) bool {
+	return target == ErrRejected
+}
+
+// rejectedf formats an error that matches ErrRejected
+func rejectedf(format string, args ...interface{}) error {
+	return &rejectedError{message: fmt.Sprintf(format, args...)}
+}
+
+// StatusError is returned when VTEX answers an upload with an unexpected HTTP
+// status. Client errors (4xx) other than 401, 403 and 429 match ErrRejected.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("upload failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrRejected && e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusUnauthorized && e.StatusCode != http.StatusForbidden &&
+		e.StatusCode != http.StatusTooManyRequests
+}
+
 // UploadResult represents the result of a file upload operation
 type UploadResult struct {
-	FileName string
-	FileURL  string
-	Success  bool
-	Error    error
+	FileName    string
+	FilePath    string // local path of the uploaded file
+	FileURL     string
+	Success     bool
+	Skipped     bool          // true when the upload was intentionally skipped (e.g. file already exists)
+	RenamedFrom string        // requested name when the file was uploaded under a different name
+	Image       *ImageInfo    // image dimensions, nil for non-image files
+	Duration    time.Duration // time spent uploading, set by callers
+
+	// Attempts is the number of times the upload request was sent, retries included
+	Attempts int
+
+	// OriginalSize and UploadedSize are set by callers that transform the file
+	// before upload (e.g. conversion or optimization); both are 0 otherwise
+	OriginalSize int64
+	UploadedSize int64
+
+	// UploadedSHA256 is the hex SHA-256 digest of the transformed content,
+	// set with UploadedSize
+	UploadedSHA256 string
+
+	Error error
 }
 
 // ValidExtensions contains file extensions validated by testing
@@ -45,6 +140,73 @@ var ValidExtensions = map[string]bool{
 	".xml":  true, // CMS only
 }
 
+// methodExtensions holds the extensions accepted by an upload method ("cms" or
+// "graphql") whose list was changed with ConfigureExtensions. Methods without
+// an entry accept ValidExtensions.
+var (
+	methodExtensionsMu sync.RWMutex
+	methodExtensions   = map[string]map[string]bool{}
+)
+
+// ConfigureExtensions changes the extensions accepted by an upload method, so
+// formats VTEX starts accepting can be uploaded without a new release. only
+// replaces ValidExtensions as the starting list when not empty; add and remove
+// are then applied to it. Extensions are matched without regard to case, with
+// or without the leading dot.
+func ConfigureExtensions(method string, add, remove, only []string) {
+	allowed := map[string]bool{}
+	if len(only) > 0 {
+		for _, ext := range only {
+			allowed[normalizeExtension(ext)] = true
+		}
+	} else {
+		for ext := range ValidExtensions {
+			allowed[ext] = true
+		}
+	}
+	for _, ext := range add {
+		allowed[normalizeExtension(ext)] = true
+	}
+	for _, ext := range remove {
+		delete(allowed, normalizeExtension(ext))
+	}
+
+	methodExtensionsMu.Lock()
+	defer methodExtensionsMu.Unlock()
+	methodExtensions[method] = allowed
+}
+
+// ExtensionAllowed reports whether files with extension ext (e.g. ".png") can be
+// uploaded with method. An empty method accepts extensions allowed by either
+// method, for callers that select files before the method is known.
+func ExtensionAllowed(method, ext string) bool {
+	methodExtensionsMu.RLock()
+	defer methodExtensionsMu.RUnlock()
+
+	ext = strings.ToLower(ext)
+	if method == "" {
+		return extensionAllowed("cms", ext) || extensionAllowed("graphql", ext)
+	}
+	return extensionAllowed(method, ext)
+}
+
+// extensionAllowed checks ext against the list of method; the caller holds methodExtensionsMu
+func extensionAllowed(method, ext string) bool {
+	if allowed, ok := methodExtensions[method]; ok {
+		return allowed[ext]
+	}
+	return ValidExtensions[ext]
+}
+
+// normalizeExtension returns ext in lowercase with a leading dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
 // GetMIMEType returns the MIME type for a given file extension
 func GetMIMEType(ext string) string {
 	switch strings.ToLower(ext) {
@@ -73,12 +235,22 @@ func GetMIMEType(ext string) string {
 	case ".js":
 		return "application/javascript"
 	default:
+		// Extensions added in the config file are not known above
+		if mimeType := mime.TypeByExtension(strings.ToLower(ext)); mimeType != "" {
+			return mimeType
+		}
 		return "application/octet-stream"
 	}
 }
 
 // ValidateFile validates that a file exists and meets requirements for upload
 func ValidateFile(filePath string) error {
+	return validateFile(filePath, "", "")
+}
+
+// validateFile checks a local file for upload with method. When the content
+// type is forced, the extension is not checked.
+func validateFile(filePath, method, forcedType string) error {
 	// Check if file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -93,21 +265,78 @@ func ValidateFile(filePath string) error {
 		return fmt.Errorf("path is a directory, not a file: %s", filePath)
 	}
 
+	return validateContent(filePath, fileInfo.Size(), method, forcedType)
+}
+
+// ValidateContent checks the size and extension of content uploaded as name,
+// for uploads that do not come from a local file
+func ValidateContent(name string, size int64) error {
+	return validateContent(name, size, "", "")
+}
+
+// validateContent checks content uploaded as name with method, skipping the
+// extension check when the content type is forced
+func validateContent(name string, size int64, method, forcedType string) error {
 	// Check file size
-	if fileInfo.Size() > MaxFileSize {
-		return fmt.Errorf("file size (%d bytes) exceeds maximum allowed size (%d bytes / 5MB)",
-			fileInfo.Size(), MaxFileSize)
+	if limit := FileSizeLimit(); size > limit {
+		return rejectedf("file size (%d bytes) exceeds maximum allowed size (%d bytes / %s)",
+			size, limit, FormatMB(limit))
 	}
 
-	if fileInfo.Size() == 0 {
-		return fmt.Errorf("file is empty: %s", filePath)
+	if size == 0 {
+		return rejectedf("file is empty: %s", name)
+	}
+
+	// Untested extensions can be attempted with an explicit content type
+	if forcedType != "" {
+		return nil
 	}
 
 	// Check file extension (case-insensitive)
-	ext := strings.ToLower(filepath.Ext(filePath))
-	if !ValidExtensions[ext] {
-		return fmt.Errorf("unsupported file type: %s (images: jpg, jpeg, png, gif, svg, webp, bmp; docs: pdf, txt, json, xml; web: css, js)", ext)
+	ext := strings.ToLower(filepath.Ext(name))
+	if !ExtensionAllowed(method, ext) {
+		return rejectedf("unsupported file type: %s (images: jpg, jpeg, png, gif, svg, webp, bmp; docs: pdf, txt, json, xml; web: css, js; more can be allowed with \"extensions\" in the config file)", ext)
 	}
 
 	return nil
 }
+
+// readContent reads and validates content uploaded as name. A negative size
+// means the size is not known in advance.
+func readContent(r io.Reader, name string, size int64, method, forcedType string) ([]byte, error) {
+	limit := FileSizeLimit()
+	if size > limit {
+		return nil, validateContent(name, size, method, forcedType)
+	}
+
+	// Read one byte past the limit to detect oversized content of unknown size
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return nil, fmt.Errorf("content of %s is %d bytes, expected %d", name, len(data), size)
+	}
+	if err := validateContent(name, int64(len(data)), method, forcedType); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// mimeTypeOf returns the MIME type result is uploaded with: the forced content
+// type when set, otherwise the type of its extension
+func mimeTypeOf(result *UploadResult, forcedType string) string {
+	if forcedType != "" {
+		return forcedType
+	}
+	return GetMIMEType(filepath.Ext(contentName(result)))
+}
+
+// contentName returns the name whose extension sets the uploaded content type:
+// the local file when there is one, otherwise the remote file name
+func contentName(result *UploadResult) string {
+	if result.FilePath != "" {
+		return result.FilePath
+	}
+	return result.FileName
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RequestTokenDiagnostics describes a single read-only attempt to obtain the CMS requestToken
+type RequestTokenDiagnostics struct {
+	URL            string
+	FinalURL       string // URL after following redirects
+	StatusCode     int
+	Latency        time.Duration
+	BodySize       int
+	PageRendered   bool   // true when the admin upload page (not a login page) was returned
+	MatchedPattern string // name of the pattern that extracted the token, empty if none
+	Token          string
+	ExpiryHeaders  map[string]string // caching/expiry related response headers, if any
+	FetchedAt      time.Time
+}
+
+// DiagnoseRequestToken fetches the CMS admin page and reports how the requestToken
+// was obtained, without uploading anything or storing the token on the client
+func (c *CMSFilePickerClient) DiagnoseRequestToken() (*RequestTokenDiagnostics, error) {
+	diag := &RequestTokenDiagnostics{
+		URL:           c.requestTokenURL(c.fileTypeFor("diagnostics.png")), // as for an image upload
+		ExpiryHeaders: map[string]string{},
+		FetchedAt:     time.Now(),
+	}
+
+	req, err := http.NewRequest("POST", diag.URL, nil)
+	if err != nil {
+		return diag, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add authentication headers
+	c.authenticator.AddAuthHeaders(req)
+
+	// Execute request
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return diag, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	diag.Latency = time.Since(start)
+	if err != nil {
+		return diag, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	diag.StatusCode = resp.StatusCode
+	diag.FinalURL = resp.Request.URL.String()
+	diag.BodySize = len(body)
+	diag.PageRendered = resp.StatusCode >= 200 && resp.StatusCode < 300 &&
+		bytes.Contains(body, []byte("fileUploadRequestToken"))
+
+	for _, name := range []string{"Cache-Control", "Expires", "Age"} {
+		if value := resp.Header.Get(name); value != "" {
+			diag.ExpiryHeaders[name] = value
+		}
+	}
+
+	diag.Token, diag.MatchedPattern = extractRequestToken(body)
+
+	return diag, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"sync"
+)
+
+// endpoints holds the base URLs set with SetEndpoints; empty values use the
+// VTEX hosts
+var endpoints = struct {
+	sync.RWMutex
+	cms     string
+	graphql string
+}{}
+
+// SetEndpoints overrides the base URLs requests are sent to, e.g. for a mock
+// server or a region-specific host. cms replaces
+// https://{account}.vtexcommercestable.com.br for the FilePicker and VTEX ID
+// endpoints and graphql replaces https://{account}.myvtex.com. Both can use
+// the {account} and {workspace} placeholders. Empty values restore the VTEX
+// hosts.
+func SetEndpoints(cms, graphql string) error {
+	for _, base := range []string{cms, graphql} {
+		if base == "" {
+			continue
+		}
+		u, err := neturl.Parse(expandEndpoint(base, "account", "workspace"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid endpoint %q: must be an http or https URL", base)
+		}
+	}
+
+	endpoints.Lock()
+	defer endpoints.Unlock()
+	endpoints.cms = strings.TrimSuffix(cms, "/")
+	endpoints.graphql = strings.TrimSuffix(graphql, "/")
+	return nil
+}
+
+// cmsBaseURL returns the base URL of account's FilePicker and VTEX ID endpoints
+// in env (empty for the environment set with SetEnvironment)
+func cmsBaseURL(account, env string) string {
+	endpoints.RLock()
+	base := endpoints.cms
+	endpoints.RUnlock()
+
+	if base == "" {
+		return "https://" + commerceHost(account, env)
+	}
+	return expandEndpoint(base, account, "master")
+}
+
+// graphqlBaseURL returns the base URL of the GraphQL endpoint of account,
+// on the workspace host when workspace is not empty
+func graphqlBaseURL(account, workspace string) string {
+	endpoints.RLock()
+	base := endpoints.graphql
+	endpoints.RUnlock()
+
+	if base != "" {
+		if workspace == "" {
+			workspace = "master"
+		}
+		return expandEndpoint(base, account, workspace)
+	}
+	if workspace != "" {
+		return fmt.Sprintf("https://%s--%s.myvtex.com", workspace, account)
+	}
+	return fmt.Sprintf("https://%s.myvtex.com", account)
+}
+
+// expandEndpoint replaces the placeholders of an endpoint override
+func expandEndpoint(base, account, workspace string) string {
+	return strings.NewReplacer("{account}", account, "{workspace}", workspace).Replace(base)
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VTEX environments the admin and VTEX ID endpoints are served from
+const (
+	EnvironmentStable = "stable"
+	EnvironmentBeta   = "beta"
+)
+
+// environments holds the environment of each account, set with SetEnvironment.
+// Accounts without one use EnvironmentStable.
+var environments = struct {
+	sync.RWMutex
+	all       string            // applies to every account
+	byAccount map[string]string // per-account environments, used when all is empty
+}{byAccount: map[string]string{}}
+
+// SetEnvironment makes requests for account's admin and VTEX ID endpoints go
+// to the given environment ("stable" or "beta"). An empty account applies it
+// to every account.
+func SetEnvironment(account, env string) error {
+	if err := ValidateEnvironment(env); err != nil {
+		return err
+	}
+
+	environments.Lock()
+	defer environments.Unlock()
+	if account == "" {
+		environments.all = env
+	} else {
+		environments.byAccount[account] = env
+	}
+	return nil
+}
+
+// ValidateEnvironment checks a VTEX environment given by the user
+func ValidateEnvironment(env string) error {
+	if env != EnvironmentStable && env != EnvironmentBeta {
+		return fmt.Errorf("invalid environment: %s (must be 'stable' or 'beta')", env)
+	}
+	return nil
+}
+
+// commerceHost returns the vtexcommerce host of account in env, e.g.
+// "mystore.vtexcommercestable.com.br". An empty env uses the environment set
+// with SetEnvironment.
+func commerceHost(account, env string) string {
+	if env == "" {
+		environments.RLock()
+		env = environments.all
+		if env == "" {
+			env = environments.byAccount[account]
+		}
+		environments.RUnlock()
+	}
+
+	if env == "" {
+		env = EnvironmentStable
+	}
+	return fmt.Sprintf("%s.vtexcommerce%s.com.br", account, env)
+}
@@ -2,20 +2,24 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	neturl "net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/glinharesb/vtex-files-manager/pkg/auth"
 	"github.com/glinharesb/vtex-files-manager/pkg/logger"
 	"github.com/schollz/progressbar/v3"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // FilePickerUploadResponse represents the response from FilePicker upload
@@ -30,29 +34,126 @@ type CMSFilePickerClient struct {
 	workspace     string
 	authenticator *auth.Authenticator
 	httpClient    *http.Client
-	verbose       bool
+	logger        *slog.Logger
+	environment   string
 	requestToken  string
+	fileType      string
+	contentType   string
 }
 
+// CMS file areas (fileType) FilePicker uploads go into. The area decides the
+// CMS admin section that lists a file and, for CMSFilesArea, its public path.
+const (
+	// CMSImagesArea holds images
+	CMSImagesArea = "images"
+
+	// CMSOthersArea holds documents and other files that are not images
+	CMSOthersArea = "others"
+
+	// CMSFilesArea is served from the legacy /files path instead of /arquivos.
+	// The CMS Portal looks up some css, js and template files there, such as
+	// checkout customizations.
+	CMSFilesArea = "files"
+
+	// CMSAutoArea uploads images into CMSImagesArea and every other file into
+	// CMSOthersArea
+	CMSAutoArea = "auto"
+)
+
+// CMSFileTypes lists the file areas accepted by SetFileType
+var CMSFileTypes = []string{CMSAutoArea, CMSImagesArea, CMSOthersArea, CMSFilesArea}
+
+// DefaultCMSFileType is the CMS file area used when none is configured.
+// CMSAutoArea is opt-in, as it changes the URL of non-image files.
+const DefaultCMSFileType = CMSImagesArea
+
 // NewCMSFilePickerClient creates a new VTEX CMS FilePicker client
-func NewCMSFilePickerClient(account, workspace string, authenticator *auth.Authenticator, verbose bool) *CMSFilePickerClient {
+func NewCMSFilePickerClient(account, workspace string, authenticator *auth.Authenticator, opts ...Option) *CMSFilePickerClient {
+	o := applyOptions(opts)
 	return &CMSFilePickerClient{
 		account:       account,
 		workspace:     workspace,
 		authenticator: authenticator,
-		httpClient: &http.Client{
-			Timeout: 5 * 60 * 1000000000, // 5 minutes
-		},
-		verbose: verbose,
+		httpClient:    o.httpClient,
+		logger:        o.logger,
+		environment:   o.environment,
+		fileType:      DefaultCMSFileType,
+	}
+}
+
+// SetFileType sets the CMS file area (fileType) the upload token is requested for
+func (c *CMSFilePickerClient) SetFileType(fileType string) {
+	c.fileType = fileType
+}
+
+// SetContentType uploads files with the given MIME type instead of the one of
+// their extension, skipping the extension check, so untested
+// file types can be attempted
+func (c *CMSFilePickerClient) SetContentType(contentType string) {
+	c.contentType = contentType
+}
+
+// fileTypeFor returns the CMS file area a file named name is uploaded into
+func (c *CMSFilePickerClient) fileTypeFor(name string) string {
+	if c.fileType != CMSAutoArea {
+		return c.fileType
+	}
+	if strings.HasPrefix(mimeTypeOf(&UploadResult{FileName: name}, c.contentType), "image/") {
+		return CMSImagesArea
+	}
+	return CMSOthersArea
+}
+
+// ValidateCMSFileType checks a CMS file area given by the user
+func ValidateCMSFileType(fileType string) error {
+	for _, valid := range CMSFileTypes {
+		if fileType == valid {
+			return nil
+		}
 	}
+	return fmt.Errorf("invalid CMS file type: %s (must be one of %s)", fileType, strings.Join(CMSFileTypes, ", "))
 }
 
-// getRequestToken fetches the requestToken from the CMS admin page
-func (c *CMSFilePickerClient) getRequestToken() error {
+// requestTokenPatterns are tried in order to extract the requestToken from the CMS admin page
+var requestTokenPatterns = []struct {
+	Name string
+	Re   *regexp.Regexp
+}{
+	// Looking for: <input type="hidden" id="fileUploadRequestToken" value="TOKEN_HERE" />
+	{"id-before-value", regexp.MustCompile(`id="fileUploadRequestToken"\s+value="([^"]+)"`)},
+	// Alternative attribute order
+	{"value-before-id", regexp.MustCompile(`value="([^"]+)"\s+id="fileUploadRequestToken"`)},
+	// Any input with fileUploadRequestToken
+	{"loose", regexp.MustCompile(`fileUploadRequestToken[^>]*value="([^"]+)"`)},
+}
+
+// extractRequestToken returns the requestToken found in the admin page HTML and
+// the name of the pattern that matched, or empty strings if none matched
+func extractRequestToken(body []byte) (string, string) {
+	for _, p := range requestTokenPatterns {
+		if matches := p.Re.FindSubmatch(body); len(matches) >= 2 {
+			return string(matches[1]), p.Name
+		}
+	}
+	return "", ""
+}
+
+// requestTokenURL returns the CMS admin page URL that renders the requestToken
+// for uploads into the given file area
+func (c *CMSFilePickerClient) requestTokenURL(fileType string) string {
+	return fmt.Sprintf("%s/admin/a/PortalManagement/AddFile?fileType=%s", cmsBaseURL(c.account, c.environment), neturl.QueryEscape(fileType))
+}
+
+// getRequestToken fetches the requestToken for the given file area from the
+// CMS admin page
+func (c *CMSFilePickerClient) getRequestToken(ctx context.Context, fileType string) (err error) {
+	ctx, span := startSpan(ctx, "cms.request_token", attribute.String("vtex.cms.file_type", fileType))
+	defer func() { endSpan(span, err) }()
+
 	// URL to get the upload page that contains the requestToken
-	url := fmt.Sprintf("https://%s.vtexcommercestable.com.br/admin/a/PortalManagement/AddFile?fileType=images", c.account)
+	url := c.requestTokenURL(fileType)
 
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -60,9 +161,7 @@ func (c *CMSFilePickerClient) getRequestToken() error {
 	// Add authentication headers
 	c.authenticator.AddAuthHeaders(req)
 
-	if c.verbose {
-		fmt.Printf("Fetching requestToken from: %s\n", url)
-	}
+	c.logger.Debug("fetching requestToken", "url", url)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -80,72 +179,65 @@ func (c *CMSFilePickerClient) getRequestToken() error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// Check for authentication errors
 		if resp.StatusCode == 401 || resp.StatusCode == 403 {
-			return fmt.Errorf("authentication failed (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", resp.StatusCode)
+			return fmt.Errorf("%w (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", ErrAuthFailed, resp.StatusCode)
 		}
 		if resp.StatusCode == 302 {
-			return fmt.Errorf("authentication failed (redirect): your VTEX session has expired. Please run 'vtex login' and try again")
+			return fmt.Errorf("%w (redirect): your VTEX session has expired. Please run 'vtex login' and try again", ErrAuthFailed)
 		}
 		return fmt.Errorf("failed to fetch upload page with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	if c.verbose {
+	if c.logger.Enabled(ctx, slog.LevelDebug) {
 		maxLen := 2000
 		if len(body) < maxLen {
 			maxLen = len(body)
 		}
-		fmt.Printf("HTML Response (first %d chars):\n%s\n", maxLen, string(body[:maxLen]))
+		c.logger.Debug("upload page response", "status", resp.StatusCode, "body", string(body[:maxLen]), "truncated", len(body) > maxLen)
 	}
 
 	// Extract requestToken from HTML
-	// Looking for: <input type="hidden" id="fileUploadRequestToken" value="TOKEN_HERE" />
-	re := regexp.MustCompile(`id="fileUploadRequestToken"\s+value="([^"]+)"`)
-	matches := re.FindSubmatch(body)
+	token, _ := extractRequestToken(body)
 
-	if len(matches) < 2 {
-		// Try alternative pattern
-		re = regexp.MustCompile(`value="([^"]+)"\s+id="fileUploadRequestToken"`)
-		matches = re.FindSubmatch(body)
+	if token == "" {
+		c.logger.Debug("requestToken not found in upload page", "body", string(body))
+		return fmt.Errorf("%w: could not obtain upload token. Your VTEX session may have expired. Please run 'vtex login' and try again", ErrAuthFailed)
 	}
 
-	if len(matches) < 2 {
-		// Try searching for any input with fileUploadRequestToken
-		re = regexp.MustCompile(`fileUploadRequestToken[^>]*value="([^"]+)"`)
-		matches = re.FindSubmatch(body)
-	}
+	c.requestToken = token
 
-	if len(matches) < 2 {
-		if c.verbose {
-			fmt.Printf("Full HTML Response:\n%s\n", string(body))
-		}
-		return fmt.Errorf("authentication failed: could not obtain upload token. Your VTEX session may have expired. Please run 'vtex login' and try again")
-	}
-
-	c.requestToken = string(matches[1])
-
-	if c.verbose {
-		fmt.Printf("RequestToken obtained: %s\n", c.requestToken)
-	}
+	c.logger.Debug("requestToken obtained", "requestToken", c.requestToken)
 
 	return nil
 }
 
 // UploadFile uploads a single file using CMS FilePicker
-func (c *CMSFilePickerClient) UploadFile(filePath string, showProgress bool) (*UploadResult, error) {
+func (c *CMSFilePickerClient) UploadFile(ctx context.Context, filePath string, showProgress bool) (*UploadResult, error) {
+	return c.UploadFileAs(ctx, filePath, filepath.Base(filePath), showProgress)
+}
+
+// UploadFileAs uploads a single file using CMS FilePicker under the given remote file name.
+// Cancelling ctx aborts the requests in flight.
+func (c *CMSFilePickerClient) UploadFileAs(ctx context.Context, filePath, fileName string, showProgress bool) (*UploadResult, error) {
+	return c.uploadFile(ctx, filePath, fileName, showProgress, false)
+}
+
+// uploadFile uploads a single file, verifying the published file when verify is
+// set before the upload is logged
+func (c *CMSFilePickerClient) uploadFile(ctx context.Context, filePath, fileName string, showProgress, verify bool) (*UploadResult, error) {
 	result := &UploadResult{
-		FileName: filepath.Base(filePath),
+		FileName: fileName,
+		FilePath: filePath,
 	}
 
 	// Validate file
-	if err := ValidateFile(filePath); err != nil {
+	if err := validateFile(filePath, "cms", c.contentType); err != nil {
 		result.Error = err
 		return result, err
 	}
 
-	// ALWAYS get a fresh requestToken before each upload
-	// The token has a very short lifespan (seconds) and must be obtained immediately before upload
-	if err := c.getRequestToken(); err != nil {
-		result.Error = fmt.Errorf("failed to get requestToken: %w", err)
-		return result, result.Error
+	// Record image dimensions when the header can be decoded
+	if info, err := ReadImageInfo(filePath); err == nil {
+		result.Image = info
 	}
 
 	// Open file
@@ -163,56 +255,59 @@ func (c *CMSFilePickerClient) UploadFile(filePath string, showProgress bool) (*U
 		return result, result.Error
 	}
 
-	// Prepare multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	fileName := filepath.Base(filePath)
-
-	// Add requestToken field
-	if err := writer.WriteField("requestToken", c.requestToken); err != nil {
-		result.Error = fmt.Errorf("failed to write requestToken field: %w", err)
-		return result, result.Error
-	}
-
-	// Add the file itself (field name must be "FileData" with capital D)
-	// Set Content-Type based on file extension
-	ext := filepath.Ext(filePath)
-	mimeType := GetMIMEType(ext)
+	return c.upload(ctx, result, file, fileInfo.Size(), showProgress, verify)
+}
 
-	// Create part with explicit Content-Type
-	h := make(map[string][]string)
-	h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="FileData"; filename="%s"`, fileName)}
-	h["Content-Type"] = []string{mimeType}
+// UploadReader uploads size bytes read from r under the given remote file name,
+// e.g. generated or piped content that was never written to a file. The
+// content is buffered in memory, which the maximum file size keeps small.
+func (c *CMSFilePickerClient) UploadReader(ctx context.Context, r io.Reader, name string, size int64) (*UploadResult, error) {
+	result := &UploadResult{FileName: name}
 
-	part, err := writer.CreatePart(h)
+	data, err := readContent(r, name, size, "cms", c.contentType)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to create form file part: %w", err)
-		return result, result.Error
+		result.Error = err
+		return result, err
 	}
 
-	// Copy file content with optional progress bar
-	var fileReader io.Reader = file
-	if showProgress {
-		bar := progressbar.DefaultBytes(
-			fileInfo.Size(),
-			fmt.Sprintf("Uploading %s", fileName),
-		)
-		fileReader = io.TeeReader(file, bar)
+	// Record image dimensions when the header can be decoded
+	if info, err := readImageInfo(bytes.NewReader(data), name); err == nil {
+		result.Image = info
 	}
 
-	if _, err := io.Copy(part, fileReader); err != nil {
-		result.Error = fmt.Errorf("failed to copy file content: %w", err)
-		return result, result.Error
-	}
+	return c.upload(ctx, result, bytes.NewReader(data), int64(len(data)), false, false)
+}
 
-	if err := writer.Close(); err != nil {
-		result.Error = fmt.Errorf("failed to close multipart writer: %w", err)
+// upload sends size bytes of content as the file described by result. With
+// verify, the published file is compared with result.FilePath and the upload
+// is logged as failed when they differ.
+func (c *CMSFilePickerClient) upload(ctx context.Context, result *UploadResult, content io.Reader, size int64, showProgress, verify bool) (*UploadResult, error) {
+	fileName := result.FileName
+
+	ctx, span := startSpan(ctx, "cms.upload",
+		attribute.String("vtex.account", c.account),
+		attribute.String("vfm.file.name", fileName),
+		attribute.Int64("vfm.file.size", size))
+	defer func() { endSpan(span, result.Error) }()
+
+	// The form is rebuilt with a new requestToken for every attempt, so the
+	// content is kept in memory, which the maximum file size keeps small
+	data, err := io.ReadAll(content)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to read file content: %w", err)
 		return result, result.Error
 	}
 
 	// Upload via FilePicker
-	fileURL, err := c.uploadFilePicker(body, writer.FormDataContentType(), fileName)
+	fileURL, attempts, err := c.uploadFilePicker(ctx, result, data, showProgress)
+	result.Attempts = attempts
+	if err == nil {
+		result.FileURL = LinkURL(fileURL)
+		result.Success = true
+		if verify {
+			err = verifyUpload(ctx, c.httpClient, c.logger, result, result.FilePath)
+		}
+	}
 	if err != nil {
 		result.Error = err
 
@@ -220,88 +315,162 @@ func (c *CMSFilePickerClient) UploadFile(filePath string, showProgress bool) (*U
 		logger.LogUpload(logger.UploadLogEntry{
 			Timestamp: time.Now(),
 			File:      fileName,
-			Path:      filePath,
-			Size:      fileInfo.Size(),
+			Path:      result.FilePath,
+			Size:      size,
 			Method:    "cms",
 			Account:   c.account,
 			Workspace: c.workspace,
 			Status:    "failed",
+			Image:     logImageInfo(result.Image),
 			Error:     err.Error(),
 		})
 
 		return result, result.Error
 	}
 
-	result.FileURL = fileURL
-	result.Success = true
-
 	// Log successful upload
 	logger.LogUpload(logger.UploadLogEntry{
 		Timestamp: time.Now(),
 		File:      fileName,
-		Path:      filePath,
-		Size:      fileInfo.Size(),
+		Path:      result.FilePath,
+		Size:      size,
 		Method:    "cms",
 		Account:   c.account,
 		Workspace: c.workspace,
 		Status:    "success",
-		URL:       fileURL,
+		Image:     logImageInfo(result.Image),
+		URL:       result.FileURL,
 	})
 
 	return result, nil
 }
 
-// uploadFilePicker performs the FilePicker upload request
-func (c *CMSFilePickerClient) uploadFilePicker(body *bytes.Buffer, contentType, fileName string) (string, error) {
-	// Build FilePicker endpoint URL
-	url := fmt.Sprintf("https://%s.vtexcommercestable.com.br/admin/a/FilePicker/UploadFile", c.account)
+// buildUploadForm writes the requestToken and the file content into the
+// multipart form sent to FilePicker and returns it with its content type
+func (c *CMSFilePickerClient) buildUploadForm(ctx context.Context, result *UploadResult, content io.Reader, size int64, showProgress bool) (body *bytes.Buffer, contentType string, err error) {
+	_, span := startSpan(ctx, "multipart.build")
+	defer func() { endSpan(span, err) }()
 
-	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body.Bytes()))
+	fileName := result.FileName
+
+	// Prepare multipart form
+	body = &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	// Add requestToken field
+	if err := writer.WriteField("requestToken", c.requestToken); err != nil {
+		return nil, "", fmt.Errorf("failed to write requestToken field: %w", err)
+	}
+
+	// Add the file itself (field name must be "FileData" with capital D)
+	// Set Content-Type based on file extension, unless forced
+	mimeType := mimeTypeOf(result, c.contentType)
+
+	// Create part with explicit Content-Type
+	h := make(map[string][]string)
+	h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="FileData"; filename="%s"`, fileName)}
+	h["Content-Type"] = []string{mimeType}
+
+	part, err := writer.CreatePart(h)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, "", fmt.Errorf("failed to create form file part: %w", err)
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	// Copy file content with optional progress bar
+	var fileReader io.Reader = content
+	if showProgress {
+		bar := progressbar.DefaultBytes(
+			size,
+			fmt.Sprintf("Uploading %s", fileName),
+		)
+		fileReader = io.TeeReader(content, bar)
+	}
 
-	// Add authentication headers
-	c.authenticator.AddAuthHeaders(req)
+	if _, err := io.Copy(part, fileReader); err != nil {
+		return nil, "", fmt.Errorf("failed to copy file content: %w", err)
+	}
 
-	if c.verbose {
-		fmt.Printf("FilePicker Endpoint: %s\n", url)
-		fmt.Printf("Auth method: %s\n", c.authenticator.GetMethodName())
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	// Execute request
-	resp, err := c.httpClient.Do(req)
+	return body, writer.FormDataContentType(), nil
+}
+
+// uploadFilePicker performs the FilePicker upload request for the file
+// described by result with data as its content. Throttled and failed (5xx)
+// uploads are retried here rather than by the HTTP client, so every attempt
+// is sent with a fresh requestToken. It also returns the number of upload
+// requests sent.
+func (c *CMSFilePickerClient) uploadFilePicker(ctx context.Context, result *UploadResult, data []byte, showProgress bool) (string, int, error) {
+	fileName := result.FileName
+	uploadCtx, attempts := withAttemptCounter(ctx)
+
+	// Build FilePicker endpoint URL
+	url := fmt.Sprintf("%s/admin/a/FilePicker/UploadFile", cmsBaseURL(c.account, c.environment))
+
+	first := true
+	resp, err := DefaultRetryPolicy.do(uploadCtx, c.httpClient, func(attemptCtx context.Context) (*http.Request, error) {
+		// ALWAYS get a fresh requestToken before each upload
+		// The token has a very short lifespan (seconds) and must be obtained immediately before upload
+		if err := c.getRequestToken(ctx, c.fileTypeFor(contentName(result))); err != nil {
+			return nil, fmt.Errorf("failed to get requestToken: %w", err)
+		}
+
+		body, contentType, err := c.buildUploadForm(ctx, result, bytes.NewReader(data), int64(len(data)), showProgress && first)
+		if err != nil {
+			return nil, err
+		}
+		first = false
+
+		// Create request
+		req, err := http.NewRequestWithContext(attemptCtx, "POST", url, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "*/*")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+		// Add authentication headers
+		c.authenticator.AddAuthHeaders(req)
+
+		c.logger.Debug("uploading to FilePicker", "url", url, "file", fileName, "auth", c.authenticator.GetMethodName())
+		return req, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return "", int(*attempts), err
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", int(*attempts), fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Response Status: %d\n", resp.StatusCode)
-		fmt.Printf("Response Body: %s\n", string(respBody))
-	}
+	c.logger.Debug("FilePicker response", "status", resp.StatusCode, "body", string(respBody))
 
 	// Check response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// Check for authentication errors
 		if resp.StatusCode == 401 || resp.StatusCode == 403 {
-			return "", fmt.Errorf("authentication failed (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", resp.StatusCode)
+			return "", int(*attempts), fmt.Errorf("%w (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", ErrAuthFailed, resp.StatusCode)
 		}
-		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
+		return "", int(*attempts), &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
+	fileURL, err := c.parseUploadResponse(ctx, respBody)
+	return fileURL, int(*attempts), err
+}
+
+// parseUploadResponse returns the URL of the file inserted by a FilePicker upload
+func (c *CMSFilePickerClient) parseUploadResponse(ctx context.Context, respBody []byte) (fileURL string, err error) {
+	_, span := startSpan(ctx, "cms.parse_response")
+	defer func() { endSpan(span, err) }()
+
 	// Parse JSON response
 	var uploadResp FilePickerUploadResponse
 	if err := json.Unmarshal(respBody, &uploadResp); err != nil {
@@ -310,44 +479,73 @@ func (c *CMSFilePickerClient) uploadFilePicker(body *bytes.Buffer, contentType,
 
 	// Check if upload was successful
 	if uploadResp.FileNameInserted == "" {
-		return "", fmt.Errorf("upload failed: %s", uploadResp.Mensagem)
+		return "", rejectedf("upload failed: %s", uploadResp.Mensagem)
 	}
 
-	// Build the file URL for /arquivos path
-	// FilePicker uploads go to: https://{account}.vtexassets.com/arquivos/{filename}
-	// Use URL encoding for filenames with spaces or special characters
-	encodedFileName := neturl.PathEscape(uploadResp.FileNameInserted)
-	fileURL := fmt.Sprintf("https://%s.vtexassets.com/arquivos/%s", c.account, encodedFileName)
+	// Build the file URL for the /arquivos or /files path of the file area
+	fileURL = publicFileURL(c.account, c.environment, c.fileTypeFor(uploadResp.FileNameInserted), uploadResp.FileNameInserted)
 
-	if c.verbose {
-		fmt.Printf("Upload successful! Message: %s\n", uploadResp.Mensagem)
-		fmt.Printf("File URL: %s\n", fileURL)
-	}
+	c.logger.Debug("upload successful", "message", uploadResp.Mensagem, "url", fileURL)
 
 	return fileURL, nil
 }
 
 // CheckFileExists verifies if a file already exists in VTEX FilePicker
-func (c *CMSFilePickerClient) CheckFileExists(fileName string) (bool, error) {
-	url := fmt.Sprintf("https://%s.vtexcommercestable.com.br/admin/a/FilePicker/FileExists?changedFileName=", c.account)
+func (c *CMSFilePickerClient) CheckFileExists(ctx context.Context, fileName string) (bool, error) {
+	existing, err := c.ExistingFiles(ctx, []string{fileName})
+	if err != nil {
+		return false, err
+	}
+	return existing[fileName], nil
+}
 
-	// Prepare multipart form
+// maxFileExistsNames bounds the number of names sent in one FileExists request
+const maxFileExistsNames = 500
+
+// ExistingFiles returns which of the given names already exist in VTEX
+// FilePicker. FilePicker has no listing endpoint, but FileExists accepts any
+// number of names and answers with the existing ones, so a whole batch is
+// checked with one request per maxFileExistsNames names.
+func (c *CMSFilePickerClient) ExistingFiles(ctx context.Context, names []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(names); start += maxFileExistsNames {
+		chunk := names[start:min(start+maxFileExistsNames, len(names))]
+		found, err := c.fileExists(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range chunk {
+			if _, ok := found[name]; ok {
+				existing[name] = true
+			}
+		}
+	}
+	return existing, nil
+}
+
+// fileExists sends one FileExists request for names and returns the response,
+// which maps each existing name to its stored name
+func (c *CMSFilePickerClient) fileExists(ctx context.Context, names []string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/admin/a/FilePicker/FileExists?changedFileName=", cmsBaseURL(c.account, c.environment))
+
+	// Prepare multipart form with one field per name
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	// Add filename field
-	if err := writer.WriteField(fileName, fileName); err != nil {
-		return false, fmt.Errorf("failed to write field: %w", err)
+	for _, name := range names {
+		if err := writer.WriteField(name, name); err != nil {
+			return nil, fmt.Errorf("failed to write field: %w", err)
+		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return false, fmt.Errorf("failed to close writer: %w", err)
+		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body.Bytes()))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body.Bytes()))
 	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -357,34 +555,58 @@ func (c *CMSFilePickerClient) CheckFileExists(fileName string) (bool, error) {
 	// Add authentication headers
 	c.authenticator.AddAuthHeaders(req)
 
-	if c.verbose {
-		fmt.Printf("Checking if file exists: %s\n", fileName)
-	}
+	c.logger.Debug("checking if files exist", "files", len(names))
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return false, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if c.verbose {
-		fmt.Printf("FileExists response: %s\n", string(respBody))
-	}
+	c.logger.Debug("FileExists response", "status", resp.StatusCode, "body", string(respBody))
 
-	// Parse JSON response
+	// Parse JSON response; a file exists if the map contains its name as key
 	var result map[string]string
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return false, fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result, nil
+}
+
+// maxRenameAttempts bounds the number of suffixed names tried by FindAvailableName
+const maxRenameAttempts = 100
+
+// FindAvailableName returns fileName if it does not exist in FilePicker, otherwise the
+// first "name-N.ext" (N >= 2) that neither exists remotely nor is reported as taken
+// by the caller (e.g. names reserved by other files in the same batch)
+func (c *CMSFilePickerClient) FindAvailableName(ctx context.Context, fileName string, taken func(string) bool) (string, error) {
+	ext := filepath.Ext(fileName)
+	stem := strings.TrimSuffix(fileName, ext)
+
+	for n := 1; n <= maxRenameAttempts; n++ {
+		candidate := fileName
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		}
+		if n > 1 && taken != nil && taken(candidate) {
+			continue
+		}
+
+		exists, err := c.CheckFileExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
 	}
 
-	// File exists if the map contains the filename as key
-	_, exists := result[fileName]
-	return exists, nil
+	return "", fmt.Errorf("no available name found for %s after %d attempts", fileName, maxRenameAttempts)
 }
@@ -2,9 +2,11 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -14,15 +16,24 @@ import (
 	"github.com/glinharesb/vtex-files-manager/pkg/auth"
 	"github.com/glinharesb/vtex-files-manager/pkg/logger"
 	"github.com/schollz/progressbar/v3"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// DefaultGraphQLBucket is the bucket used when none is configured
+const DefaultGraphQLBucket = "images"
+
 // GraphQLClient represents a VTEX GraphQL API client
 type GraphQLClient struct {
 	account       string
 	workspace     string
 	authenticator *auth.Authenticator
 	httpClient    *http.Client
-	verbose       bool
+	logger        *slog.Logger
+	bucket        string
+	contentType   string
+
+	// useWorkspaceEndpoint sends uploads to the workspace host instead of master
+	useWorkspaceEndpoint bool
 }
 
 // GraphQLUploadResult represents the result of a GraphQL file upload
@@ -35,36 +46,73 @@ type GraphQLUploadResult struct {
 		} `json:"uploadFile"`
 	} `json:"data"`
 	Errors []struct {
-		Message string `json:"message"`
+		Message string   `json:"message"`
 		Path    []string `json:"path"`
 	} `json:"errors"`
 }
 
 // NewGraphQLClient creates a new VTEX GraphQL API client
-func NewGraphQLClient(account, workspace string, authenticator *auth.Authenticator, verbose bool) *GraphQLClient {
+func NewGraphQLClient(account, workspace string, authenticator *auth.Authenticator, opts ...Option) *GraphQLClient {
+	o := applyOptions(opts)
 	return &GraphQLClient{
 		account:       account,
 		workspace:     workspace,
 		authenticator: authenticator,
-		httpClient: &http.Client{
-			Timeout: 5 * 60 * 1000000000, // 5 minutes
-		},
-		verbose: verbose,
+		httpClient:    o.httpClient,
+		logger:        o.logger,
+		bucket:        DefaultGraphQLBucket,
 	}
 }
 
+// SetBucket sets the bucket files are uploaded to
+func (c *GraphQLClient) SetBucket(bucket string) {
+	c.bucket = bucket
+}
+
+// SetWorkspaceEndpoint sends uploads to the {workspace}--{account} host so they
+// land in the client's workspace instead of master
+func (c *GraphQLClient) SetWorkspaceEndpoint(enabled bool) {
+	c.useWorkspaceEndpoint = enabled
+}
+
+// SetContentType uploads files with the given MIME type instead of the one of
+// their extension, skipping the extension check, so untested
+// file types can be attempted
+func (c *GraphQLClient) SetContentType(contentType string) {
+	c.contentType = contentType
+}
+
 // UploadFile uploads a single file using GraphQL mutation
-func (c *GraphQLClient) UploadFile(filePath string, showProgress bool) (*UploadResult, error) {
+func (c *GraphQLClient) UploadFile(ctx context.Context, filePath string, showProgress bool) (*UploadResult, error) {
+	return c.UploadFileAs(ctx, filePath, filepath.Base(filePath), showProgress)
+}
+
+// UploadFileAs uploads a single file using GraphQL mutation, sending fileName as the
+// multipart file name (GraphQL still generates the final URL). Cancelling ctx aborts
+// the request in flight.
+func (c *GraphQLClient) UploadFileAs(ctx context.Context, filePath, fileName string, showProgress bool) (*UploadResult, error) {
+	return c.uploadFile(ctx, filePath, fileName, showProgress, false)
+}
+
+// uploadFile uploads a single file, verifying the published file when verify is
+// set before the upload is logged
+func (c *GraphQLClient) uploadFile(ctx context.Context, filePath, fileName string, showProgress, verify bool) (*UploadResult, error) {
 	result := &UploadResult{
-		FileName: filepath.Base(filePath),
+		FileName: fileName,
+		FilePath: filePath,
 	}
 
 	// Validate file
-	if err := ValidateFile(filePath); err != nil {
+	if err := validateFile(filePath, "graphql", c.contentType); err != nil {
 		result.Error = err
 		return result, err
 	}
 
+	// Record image dimensions when the header can be decoded
+	if info, err := ReadImageInfo(filePath); err == nil {
+		result.Image = info
+	}
+
 	// Open file
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -80,8 +128,105 @@ func (c *GraphQLClient) UploadFile(filePath string, showProgress bool) (*UploadR
 		return result, result.Error
 	}
 
+	return c.upload(ctx, result, file, fileInfo.Size(), showProgress, verify)
+}
+
+// UploadReader uploads size bytes read from r under the given remote file name,
+// e.g. generated or piped content that was never written to a file. The
+// content is buffered in memory, which the maximum file size keeps small.
+func (c *GraphQLClient) UploadReader(ctx context.Context, r io.Reader, name string, size int64) (*UploadResult, error) {
+	result := &UploadResult{FileName: name}
+
+	data, err := readContent(r, name, size, "graphql", c.contentType)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	// Record image dimensions when the header can be decoded
+	if info, err := readImageInfo(bytes.NewReader(data), name); err == nil {
+		result.Image = info
+	}
+
+	return c.upload(ctx, result, bytes.NewReader(data), int64(len(data)), false, false)
+}
+
+// upload sends size bytes of content as the file described by result. With
+// verify, the published file is compared with result.FilePath and the upload
+// is logged as failed when they differ.
+func (c *GraphQLClient) upload(ctx context.Context, result *UploadResult, content io.Reader, size int64, showProgress, verify bool) (*UploadResult, error) {
+	fileName := result.FileName
+
+	ctx, span := startSpan(ctx, "graphql.upload",
+		attribute.String("vtex.account", c.account),
+		attribute.String("vfm.file.name", fileName),
+		attribute.Int64("vfm.file.size", size))
+	defer func() { endSpan(span, result.Error) }()
+
+	body, contentType, err := c.buildUploadForm(ctx, result, content, size, showProgress)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Upload with GraphQL
+	ctx, attempts := withAttemptCounter(ctx)
+	fileURL, err := c.uploadGraphQL(ctx, body, contentType)
+	result.Attempts = int(*attempts)
+	if err == nil {
+		result.FileURL = LinkURL(fileURL)
+		result.Success = true
+		if verify {
+			err = verifyUpload(ctx, c.httpClient, c.logger, result, result.FilePath)
+		}
+	}
+	if err != nil {
+		result.Error = err
+
+		// Log failed upload
+		logger.LogUpload(logger.UploadLogEntry{
+			Timestamp: time.Now(),
+			File:      fileName,
+			Path:      result.FilePath,
+			Size:      size,
+			Method:    "graphql",
+			Account:   c.account,
+			Workspace: c.workspace,
+			Status:    "failed",
+			Image:     logImageInfo(result.Image),
+			Error:     err.Error(),
+		})
+
+		return result, result.Error
+	}
+
+	// Log successful upload
+	logger.LogUpload(logger.UploadLogEntry{
+		Timestamp: time.Now(),
+		File:      fileName,
+		Path:      result.FilePath,
+		Size:      size,
+		Method:    "graphql",
+		Account:   c.account,
+		Workspace: c.workspace,
+		Status:    "success",
+		Image:     logImageInfo(result.Image),
+		URL:       result.FileURL,
+	})
+
+	return result, nil
+}
+
+// buildUploadForm writes the GraphQL operation and the file content into a
+// multipart request and returns it with its content type
+func (c *GraphQLClient) buildUploadForm(ctx context.Context, result *UploadResult, content io.Reader, size int64, showProgress bool) (body *bytes.Buffer, contentType string, err error) {
+	_, span := startSpan(ctx, "multipart.build")
+	defer func() { endSpan(span, err) }()
+
+	fileName := result.FileName
+
 	// Prepare GraphQL multipart request
-	body := &bytes.Buffer{}
+	body = &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	// 1. Add operations (GraphQL query)
@@ -95,19 +240,17 @@ func (c *GraphQLClient) UploadFile(filePath string, showProgress bool) (*UploadR
 		}`,
 		"variables": map[string]interface{}{
 			"file":   nil, // Will be mapped from the file part
-			"bucket": "images",
+			"bucket": c.bucket,
 		},
 	}
 
 	operationsJSON, err := json.Marshal(operations)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to marshal operations: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to marshal operations: %w", err)
 	}
 
 	if err := writer.WriteField("operations", string(operationsJSON)); err != nil {
-		result.Error = fmt.Errorf("failed to write operations field: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to write operations field: %w", err)
 	}
 
 	// 2. Add map (file mapping)
@@ -117,98 +260,60 @@ func (c *GraphQLClient) UploadFile(filePath string, showProgress bool) (*UploadR
 
 	mapJSON, err := json.Marshal(fileMap)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to marshal map: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to marshal map: %w", err)
 	}
 
 	if err := writer.WriteField("map", string(mapJSON)); err != nil {
-		result.Error = fmt.Errorf("failed to write map field: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to write map field: %w", err)
 	}
 
 	// 3. Add the file itself with proper Content-Type
 	h := make(map[string][]string)
-	h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="0"; filename="%s"`, filepath.Base(filePath))}
+	h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="0"; filename="%s"`, fileName)}
 
-	// Set Content-Type based on file extension
-	ext := filepath.Ext(filePath)
-	mimeType := GetMIMEType(ext)
+	// Set Content-Type based on file extension, unless forced
+	mimeType := mimeTypeOf(result, c.contentType)
 	h["Content-Type"] = []string{mimeType}
 
 	part, err := writer.CreatePart(h)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to create form file: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to create form file: %w", err)
 	}
 
 	// Copy file content with optional progress bar
-	var fileReader io.Reader = file
+	var fileReader io.Reader = content
 	if showProgress {
 		bar := progressbar.DefaultBytes(
-			fileInfo.Size(),
-			fmt.Sprintf("Uploading %s", filepath.Base(filePath)),
+			size,
+			fmt.Sprintf("Uploading %s", fileName),
 		)
-		fileReader = io.TeeReader(file, bar)
+		fileReader = io.TeeReader(content, bar)
 	}
 
 	if _, err := io.Copy(part, fileReader); err != nil {
-		result.Error = fmt.Errorf("failed to copy file content: %w", err)
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to copy file content: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		result.Error = fmt.Errorf("failed to close multipart writer: %w", err)
-		return result, result.Error
-	}
-
-	// Upload with GraphQL
-	fileURL, err := c.uploadGraphQL(body, writer.FormDataContentType())
-	if err != nil {
-		result.Error = err
-
-		// Log failed upload
-		logger.LogUpload(logger.UploadLogEntry{
-			Timestamp: time.Now(),
-			File:      filepath.Base(filePath),
-			Path:      filePath,
-			Size:      fileInfo.Size(),
-			Method:    "graphql",
-			Account:   c.account,
-			Workspace: c.workspace,
-			Status:    "failed",
-			Error:     err.Error(),
-		})
-
-		return result, result.Error
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	result.FileURL = fileURL
-	result.Success = true
-
-	// Log successful upload
-	logger.LogUpload(logger.UploadLogEntry{
-		Timestamp: time.Now(),
-		File:      filepath.Base(filePath),
-		Path:      filePath,
-		Size:      fileInfo.Size(),
-		Method:    "graphql",
-		Account:   c.account,
-		Workspace: c.workspace,
-		Status:    "success",
-		URL:       fileURL,
-	})
-
-	return result, nil
+	return body, writer.FormDataContentType(), nil
 }
 
 // uploadGraphQL performs the GraphQL upload request
-func (c *GraphQLClient) uploadGraphQL(body *bytes.Buffer, contentType string) (string, error) {
+func (c *GraphQLClient) uploadGraphQL(ctx context.Context, body *bytes.Buffer, contentType string) (string, error) {
 	// Build GraphQL endpoint URL
 	// Use the account-specific endpoint
-	url := fmt.Sprintf("https://%s.myvtex.com/_v/private/graphql/v1", c.account)
+	workspace := ""
+	if c.useWorkspaceEndpoint {
+		workspace = c.workspace
+	}
+	url := fmt.Sprintf("%s/_v/private/graphql/v1", graphqlBaseURL(c.account, workspace))
 
-	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body.Bytes()))
+	// Create request. An upload that failed on the network may still have
+	// created the asset, so only throttled and failed (5xx) uploads are retried.
+	req, err := http.NewRequestWithContext(withRetryMode(ctx, retryStatus), "POST", url, bytes.NewReader(body.Bytes()))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -220,10 +325,7 @@ func (c *GraphQLClient) uploadGraphQL(body *bytes.Buffer, contentType string) (s
 	// Add authentication headers
 	c.authenticator.AddAuthHeaders(req)
 
-	if c.verbose {
-		fmt.Printf("GraphQL Endpoint: %s\n", url)
-		fmt.Printf("Auth method: %s\n", c.authenticator.GetMethodName())
-	}
+	c.logger.Debug("uploading to GraphQL", "url", url, "auth", c.authenticator.GetMethodName())
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -238,20 +340,25 @@ func (c *GraphQLClient) uploadGraphQL(body *bytes.Buffer, contentType string) (s
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Response Status: %d\n", resp.StatusCode)
-		fmt.Printf("Response Body: %s\n", string(respBody))
-	}
+	c.logger.Debug("GraphQL response", "status", resp.StatusCode, "body", string(respBody))
 
 	// Check response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// Check for authentication errors
 		if resp.StatusCode == 401 || resp.StatusCode == 403 {
-			return "", fmt.Errorf("authentication failed (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", resp.StatusCode)
+			return "", fmt.Errorf("%w (HTTP %d): your VTEX session has expired. Please run 'vtex login' and try again", ErrAuthFailed, resp.StatusCode)
 		}
-		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
+	return c.parseUploadResponse(ctx, respBody)
+}
+
+// parseUploadResponse returns the file URL from a GraphQL uploadFile response
+func (c *GraphQLClient) parseUploadResponse(ctx context.Context, respBody []byte) (fileURL string, err error) {
+	_, span := startSpan(ctx, "graphql.parse_response")
+	defer func() { endSpan(span, err) }()
+
 	// Parse GraphQL response
 	var gqlResult GraphQLUploadResult
 	if err := json.Unmarshal(respBody, &gqlResult); err != nil {
@@ -261,18 +368,16 @@ func (c *GraphQLClient) uploadGraphQL(body *bytes.Buffer, contentType string) (s
 	// Check for GraphQL errors
 	if len(gqlResult.Errors) > 0 {
 		errMsg := gqlResult.Errors[0].Message
-		return "", fmt.Errorf("GraphQL error: %s", errMsg)
+		return "", rejectedf("GraphQL error: %s", errMsg)
 	}
 
 	// Get file URL from response
-	fileURL := gqlResult.Data.UploadFile.FileURL
+	fileURL = gqlResult.Data.UploadFile.FileURL
 	if fileURL == "" {
 		return "", fmt.Errorf("no fileUrl in response")
 	}
 
-	if c.verbose {
-		fmt.Printf("Upload successful! File URL: %s\n", fileURL)
-	}
+	c.logger.Debug("upload successful", "url", fileURL)
 
 	return fileURL, nil
 }
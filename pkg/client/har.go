@@ -0,0 +1,439 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// harRedactedHeaders carry credentials and are never written to HAR files
+var harRedactedHeaders = map[string]bool{
+	"authorization":            true,
+	"cookie":                   true,
+	"set-cookie":               true,
+	"vtexidclientautcookie":    true,
+	"x-vtex-api-appkey":        true,
+	"x-vtex-api-apptoken":      true,
+	"proxy-authorization":      true,
+	"x-vtex-credential":        true,
+	"x-vtex-session":           true,
+	"x-vtex-identity-identity": true,
+}
+
+// harRedactedFields are query parameters and JSON or form fields that carry
+// credentials, matched case-insensitively; their values are never written
+var harRedactedFields = map[string]bool{
+	"appkey":                true,
+	"apptoken":              true,
+	"token":                 true,
+	"authcookie":            true,
+	"vtexidclientautcookie": true,
+	"access_token":          true,
+	"password":              true,
+	"requesttoken":          true,
+}
+
+const (
+	// harMaxBodySize is the largest body kept in a HAR entry; larger bodies
+	// are replaced by a note with their size
+	harMaxBodySize = 64 << 10
+
+	// harMaxTotalBodySize bounds the bodies kept for a whole run, so long
+	// batches don't hold every response in memory; later bodies are omitted
+	harMaxTotalBodySize = 16 << 20
+)
+
+// HARRecorder captures the requests sent to VTEX, and their responses, so a
+// run can be replayed in a HAR viewer or shared with VTEX support
+type HARRecorder struct {
+	mu        sync.Mutex
+	enabled   bool
+	entries   []harEntry
+	bodyBytes int // size of the bodies kept so far
+}
+
+// DefaultHARRecorder is shared by all clients created in this process
+var DefaultHARRecorder = &HARRecorder{}
+
+// Enable starts recording requests
+func (r *HARRecorder) Enable() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = true
+}
+
+// isEnabled reports whether requests are being recorded
+func (r *HARRecorder) isEnabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enabled
+}
+
+// add appends a completed entry
+func (r *HARRecorder) add(entry harEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// keepBody reserves room for a body of size bytes and reports false when the
+// bodies kept so far leave no room for it
+func (r *HARRecorder) keepBody(size int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bodyBytes+size > harMaxTotalBodySize {
+		return false
+	}
+	r.bodyBytes += size
+	return true
+}
+
+// WriteFile writes the recorded requests as a HAR 1.2 file. Credentials in
+// headers, query strings and JSON, form or multipart bodies are redacted, as
+// is the CMS requestToken, and uploaded file contents are omitted.
+func (r *HARRecorder) WriteFile(path, creatorVersion string) error {
+	r.mu.Lock()
+	entries := append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	har := map[string]interface{}{
+		"log": map[string]interface{}{
+			"version": "1.2",
+			"creator": map[string]string{"name": "vfm", "version": creatorVersion},
+			"entries": entries,
+		},
+	}
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of recorded requests
+func (r *HARRecorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+type harEntry struct {
+	StartedDateTime time.Time          `json:"startedDateTime"`
+	Time            float64            `json:"time"`
+	Request         harRequest         `json:"request"`
+	Response        harResponse        `json:"response"`
+	Cache           struct{}           `json:"cache"`
+	Timings         map[string]float64 `json:"timings"`
+	Comment         string             `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// harHeaders converts headers to HAR name/value pairs, redacting credentials
+func harHeaders(h http.Header) []harNameValue {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := []harNameValue{}
+	for _, name := range names {
+		for _, value := range h[name] {
+			if harRedactedHeaders[strings.ToLower(name)] {
+				value = "REDACTED"
+			}
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// harBodyText returns body as HAR text with credentials redacted, or a note
+// when it is binary, too large or past the recorder's total body budget
+func (r *HARRecorder) harBodyText(body []byte, size int, contentType string) (text, note string) {
+	switch {
+	case size > harMaxBodySize:
+		return "", fmt.Sprintf("%d bytes omitted (larger than %d)", size, harMaxBodySize)
+	case !utf8.Valid(body):
+		return "", fmt.Sprintf("%d bytes of binary data omitted", size)
+	case !r.keepBody(size):
+		return "", fmt.Sprintf("%d bytes omitted (over %d bytes of bodies recorded)", size, harMaxTotalBodySize)
+	}
+	return redactBody(body, contentType), ""
+}
+
+// redactBody replaces the values of credential fields in JSON, form and
+// multipart bodies and the CMS requestToken in admin pages
+func redactBody(body []byte, contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		return redactMultipart(body, contentType)
+	case strings.Contains(contentType, "json") || json.Valid(body):
+		var value interface{}
+		if json.Unmarshal(body, &value) != nil {
+			return string(body)
+		}
+		redacted, err := json.Marshal(redactJSON(value))
+		if err != nil {
+			return string(body)
+		}
+		return string(redacted)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := neturl.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		return redactValues(values).Encode()
+	}
+	return redactRequestToken(string(body))
+}
+
+// redactMultipart rewrites a multipart form with the values of credential
+// fields redacted and the content of file parts omitted, as uploaded files
+// are not needed to debug a request. Bodies that cannot be parsed are
+// omitted as a whole, since they may carry credentials.
+func redactMultipart(body []byte, contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["boundary"] == "" {
+		return fmt.Sprintf("[%d bytes of multipart data omitted]", len(body))
+	}
+
+	var out bytes.Buffer
+	writer := multipart.NewWriter(&out)
+	if err := writer.SetBoundary(params["boundary"]); err != nil {
+		return fmt.Sprintf("[%d bytes of multipart data omitted]", len(body))
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Sprintf("[%d bytes of multipart data omitted]", len(body))
+		}
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Sprintf("[%d bytes of multipart data omitted]", len(body))
+		}
+
+		switch {
+		case part.FileName() != "":
+			value = []byte(fmt.Sprintf("[%d bytes of file content omitted]", len(value)))
+		case harRedactedFields[strings.ToLower(part.FormName())]:
+			value = []byte("REDACTED")
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return fmt.Sprintf("[%d bytes of multipart data omitted]", len(body))
+		}
+		w.Write(value)
+	}
+	writer.Close()
+	return out.String()
+}
+
+// redactRequestToken replaces the requestToken rendered in the CMS admin
+// page that FilePicker uploads are authorized with
+func redactRequestToken(html string) string {
+	for _, p := range requestTokenPatterns {
+		html = p.Re.ReplaceAllStringFunc(html, func(match string) string {
+			token := p.Re.FindStringSubmatch(match)[1]
+			return strings.Replace(match, token, "REDACTED", 1)
+		})
+	}
+	return html
+}
+
+// redactJSON replaces the values of credential fields in a decoded JSON value
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if harRedactedFields[strings.ToLower(key)] {
+				v[key] = "REDACTED"
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactValues replaces the values of credential fields in a query or form
+func redactValues(values neturl.Values) neturl.Values {
+	for key, list := range values {
+		if harRedactedFields[strings.ToLower(key)] {
+			for i := range list {
+				list[i] = "REDACTED"
+			}
+		}
+	}
+	return values
+}
+
+// harURL returns u with the credentials in its query string redacted
+func harURL(u *neturl.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = redactValues(u.Query()).Encode()
+	return redacted.String()
+}
+
+// harTransport records every request attempt in a HARRecorder
+type harTransport struct {
+	base     http.RoundTripper
+	recorder *HARRecorder
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.recorder.isEnabled() {
+		return t.base.RoundTrip(req)
+	}
+
+	entry := harEntry{
+		StartedDateTime: time.Now(),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         harURL(req.URL),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+	}
+	for name, values := range redactValues(req.URL.Query()) {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	// Read a copy of the body so the request itself is left untouched
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			// Only read what can be kept; the size of larger bodies is known upfront
+			data, _ := io.ReadAll(io.LimitReader(body, harMaxBodySize+1))
+			body.Close()
+			size := len(data)
+			if req.ContentLength > int64(size) {
+				size = int(req.ContentLength)
+			}
+			text, note := t.recorder.harBodyText(data, size, req.Header.Get("Content-Type"))
+			if note != "" {
+				text = "[" + note + "]"
+			}
+			entry.Request.BodySize = size
+			entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: text}
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	elapsed := float64(time.Since(entry.StartedDateTime).Microseconds()) / 1000
+	entry.Time = elapsed
+	entry.Timings = map[string]float64{"send": 0, "wait": elapsed, "receive": 0}
+
+	if err != nil {
+		entry.Comment = err.Error()
+		entry.Response = harResponse{
+			Headers: []harNameValue{},
+			Cookies: []harNameValue{},
+			Content: harContent{MimeType: "x-unknown"},
+			// HAR viewers treat status 0 as a failed request
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		t.recorder.add(entry)
+		return resp, err
+	}
+
+	// Buffer the response so it can be both recorded and read by the caller
+	data, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	text, note := t.recorder.harBodyText(data, len(data), resp.Header.Get("Content-Type"))
+	if readErr != nil {
+		note = fmt.Sprintf("body read failed: %v", readErr)
+	}
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Headers:     harHeaders(resp.Header),
+		Cookies:     []harNameValue{},
+		Content:     harContent{Size: len(data), MimeType: resp.Header.Get("Content-Type"), Text: text, Comment: note},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(data),
+	}
+	t.recorder.add(entry)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// slowRequestThreshold is the duration after which a request counts as a
+// sign of congestion even when it succeeds
+const slowRequestThreshold = 30 * time.Second
+
+// HealthMonitor counts responses that signal VTEX is overloaded: HTTP 429,
+// 5xx statuses, network errors and very slow requests
+type HealthMonitor struct {
+	mu        sync.Mutex
+	requests  int64
+	congested int64
+}
+
+// DefaultHealthMonitor is shared by all clients created in this process
+var DefaultHealthMonitor = &HealthMonitor{}
+
+// record registers the outcome of a request
+func (h *HealthMonitor) record(resp *http.Response, err error, elapsed time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests++
+	if err != nil || elapsed > slowRequestThreshold || IsCongestionStatus(resp.StatusCode) {
+		h.congested++
+	}
+}
+
+// Snapshot returns the number of requests made and how many of them signalled
+// congestion
+func (h *HealthMonitor) Snapshot() (requests, congested int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests, h.congested
+}
+
+// IsCongestionStatus reports whether an HTTP status means the server is
+// rate limiting or overloaded
+func IsCongestionStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// healthTransport records every response in a HealthMonitor
+type healthTransport struct {
+	base    http.RoundTripper
+	monitor *HealthMonitor
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.monitor.record(resp, err, time.Since(start))
+	return resp, err
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/logger"
+)
+
+// ImageInfo holds the dimensions of an image read from its header
+type ImageInfo struct {
+	Width    int
+	Height   int
+	Animated bool // true for GIF and WebP images with more than one frame
+}
+
+// ReadImageInfo decodes the header of a jpg, png, gif, webp or bmp file to
+// read its dimensions without decoding the full image
+func ReadImageInfo(filePath string) (*ImageInfo, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readImageInfo(file, filePath)
+}
+
+// readImageInfo decodes an image header from r, using the extension of name
+// to select the format
+func readImageInfo(file io.Reader, name string) (*ImageInfo, error) {
+	ext := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+	switch ext {
+	case "jpg", "jpeg", "png":
+		cfg, _, err := image.DecodeConfig(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image header: %w", err)
+		}
+		return &ImageInfo{Width: cfg.Width, Height: cfg.Height}, nil
+	case "gif":
+		// Frames are only counted for GIFs, which are limited by the maximum file size
+		anim, err := gif.DecodeAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image header: %w", err)
+		}
+		return &ImageInfo{
+			Width:    anim.Config.Width,
+			Height:   anim.Config.Height,
+			Animated: len(anim.Image) > 1,
+		}, nil
+	case "webp":
+		return readWebPInfo(file)
+	case "bmp":
+		return readBMPInfo(file)
+	default:
+		return nil, fmt.Errorf("dimensions are not available for .%s files", ext)
+	}
+}
+
+// readWebPInfo parses the RIFF container of a WebP image (VP8, VP8L or VP8X)
+func readWebPInfo(r io.Reader) (*ImageInfo, error) {
+	header := make([]byte, 30)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read webp header: %w", err)
+	}
+	if !bytes.Equal(header[0:4], []byte("RIFF")) || !bytes.Equal(header[8:12], []byte("WEBP")) {
+		return nil, fmt.Errorf("not a webp file")
+	}
+
+	chunk := header[12:16]
+	data := header[20:]
+	switch string(chunk) {
+	case "VP8 ":
+		// Lossy: frame tag (3 bytes), start code (3 bytes), then 14-bit dimensions
+		return &ImageInfo{
+			Width:  int(binary.LittleEndian.Uint16(data[6:8]) & 0x3fff),
+			Height: int(binary.LittleEndian.Uint16(data[8:10]) & 0x3fff),
+		}, nil
+	case "VP8L":
+		// Lossless: signature byte, then 14-bit width-1 and height-1
+		bits := binary.LittleEndian.Uint32(data[1:5])
+		return &ImageInfo{
+			Width:  int(bits&0x3fff) + 1,
+			Height: int((bits>>14)&0x3fff) + 1,
+		}, nil
+	case "VP8X":
+		// Extended: flags byte (bit 1 = animation), 3 reserved bytes, 24-bit canvas width-1 and height-1
+		return &ImageInfo{
+			Width:    int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1,
+			Height:   int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1,
+			Animated: data[0]&0x02 != 0,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown webp chunk %q", chunk)
+	}
+}
+
+// readBMPInfo reads the dimensions from a BMP info header
+func readBMPInfo(r io.Reader) (*ImageInfo, error) {
+	header := make([]byte, 26)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read bmp header: %w", err)
+	}
+	if !bytes.Equal(header[0:2], []byte("BM")) {
+		return nil, fmt.Errorf("not a bmp file")
+	}
+
+	// Height is negative for top-down bitmaps
+	height := int(int32(binary.LittleEndian.Uint32(header[22:26])))
+	if height < 0 {
+		height = -height
+	}
+	return &ImageInfo{
+		Width:  int(int32(binary.LittleEndian.Uint32(header[18:22]))),
+		Height: height,
+	}, nil
+}
+
+// logImageInfo converts image dimensions to their log representation
+func logImageInfo(info *ImageInfo) *logger.ImageMeta {
+	if info == nil {
+		return nil
+	}
+	return &logger.ImageMeta{Width: info.Width, Height: info.Height, Animated: info.Animated}
+}
+
+// Dimensions formats the image size as "WIDTHxHEIGHT", or returns an empty
+// string when the dimensions are unknown
+func (r *UploadResult) Dimensions() string {
+	if r.Image == nil {
+		return ""
+	}
+	if r.Image.Animated {
+		return fmt.Sprintf("%dx%d, animated", r.Image.Width, r.Image.Height)
+	}
+	return fmt.Sprintf("%dx%d", r.Image.Width, r.Image.Height)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Option configures an upload client created by NewCMSFilePickerClient or NewGraphQLClient
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient  *http.Client
+	logger      *slog.Logger
+	environment string
+}
+
+// WithHTTPClient makes the client send every request with httpClient instead
+// of the shared pooled client. Retries, quota tracking and health monitoring
+// are not applied unless httpClient provides them.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTransport makes the client send requests through transport (e.g. to an
+// httptest server or an instrumented transport), keeping the retries, quota
+// tracking and health monitoring of the shared client.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.httpClient = NewHTTPClient(transport)
+	}
+}
+
+// WithLogger makes the client write its debug logs (endpoints, raw responses,
+// tokens) to logger instead of slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithEnvironment makes the client send its admin and VTEX ID requests to the
+// VTEX environment env ("stable" or "beta"), overriding SetEnvironment for
+// this client only
+func WithEnvironment(env string) Option {
+	return func(o *clientOptions) {
+		o.environment = env
+	}
+}
+
+// applyOptions returns the settings selected by opts, with the shared HTTP
+// client and the default logger when they are not set
+func applyOptions(opts []Option) clientOptions {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = newHTTPClient()
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+	return o
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// quotaWindow is the rolling window VTEX request quotas are expressed in
+const quotaWindow = time.Minute
+
+// quotaWarnRatio is the fraction of the quota at which a warning is emitted
+const quotaWarnRatio = 0.8
+
+// QuotaTracker counts requests per rolling minute and optionally throttles
+// them to stay below a configured per-minute quota
+type QuotaTracker struct {
+	mu         sync.Mutex
+	limit      int
+	throttle   bool
+	timestamps []time.Time
+	total      int64
+	peak       int
+	lastWarn   time.Time
+
+	// OnWarn is called when the request rate approaches the configured quota
+	OnWarn func(count, limit int)
+}
+
+// DefaultQuotaTracker is shared by all clients created in this process
+var DefaultQuotaTracker = &QuotaTracker{}
+
+// ConfigureQuota sets the per-minute request quota (0 disables warnings) and
+// whether requests should be delayed instead of exceeding it
+func (q *QuotaTracker) ConfigureQuota(limit int, throttle bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.throttle = throttle
+}
+
+// record registers a new request, waiting first if throttling is enabled and
+// the quota for the current window has been reached. It returns ctx's error
+// when ctx is done before the request can be sent.
+func (q *QuotaTracker) record(ctx context.Context) error {
+	q.mu.Lock()
+	for {
+		now := time.Now()
+		q.prune(now)
+
+		if !q.throttle || q.limit <= 0 || len(q.timestamps) < q.limit {
+			break
+		}
+
+		// Wait until the oldest request leaves the window
+		wait := q.timestamps[0].Add(quotaWindow).Sub(now)
+		q.mu.Unlock()
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
+
+	now := time.Now()
+	q.timestamps = append(q.timestamps, now)
+	q.total++
+	count := len(q.timestamps)
+	if count > q.peak {
+		q.peak = count
+	}
+
+	// Warn at most every 10 seconds when approaching the quota
+	var onWarn func(int, int)
+	limit := q.limit
+	if limit > 0 && float64(count) >= quotaWarnRatio*float64(limit) && now.Sub(q.lastWarn) > 10*time.Second {
+		q.lastWarn = now
+		onWarn = q.OnWarn
+	}
+	q.mu.Unlock()
+
+	if onWarn != nil {
+		onWarn(count, limit)
+	}
+	return nil
+}
+
+// prune drops requests older than the rolling window; callers must hold q.mu
+func (q *QuotaTracker) prune(now time.Time) {
+	cutoff := now.Add(-quotaWindow)
+	i := 0
+	for i < len(q.timestamps) && q.timestamps[i].Before(cutoff) {
+		i++
+	}
+	q.timestamps = q.timestamps[i:]
+}
+
+// Stats returns the requests in the current window, the peak per-minute
+// count and the total number of requests made
+func (q *QuotaTracker) Stats() (current, peak int, total int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.prune(time.Now())
+	return len(q.timestamps), q.peak, q.total
+}
+
+// quotaTransport records every request in a QuotaTracker
+type quotaTransport struct {
+	base    http.RoundTripper
+	tracker *QuotaTracker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.tracker.record(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Connection pool limits of the shared HTTP client. Batch workers all talk to
+// the same few VTEX hosts, so idle connections per host are kept well above
+// the net/http default of 2 to let every worker reuse one.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 32
+	idleConnTimeout     = 90 * time.Second
+)
+
+var (
+	sharedClient     *http.Client
+	sharedClientOnce sync.Once
+)
+
+// newHTTPClient returns the HTTP client used by upload clients. All clients
+// share one pooled client so keep-alive connections are reused across batch
+// workers; it is built on first use, after ConfigureTLS has run.
+func newHTTPClient() *http.Client {
+	sharedClientOnce.Do(func() {
+		var base http.RoundTripper = http.DefaultTransport
+		if t, ok := http.DefaultTransport.(*http.Transport); ok {
+			pooled := t.Clone()
+			pooled.MaxIdleConns = maxIdleConns
+			pooled.MaxIdleConnsPerHost = maxIdleConnsPerHost
+			pooled.IdleConnTimeout = idleConnTimeout
+			pooled.ForceAttemptHTTP2 = true
+			if vtexTLSConfig != nil {
+				pooled.TLSClientConfig = vtexTLSConfig
+			}
+			base = pooled
+		}
+		sharedClient = NewHTTPClient(base)
+	})
+	return sharedClient
+}
+
+// NewHTTPClient returns an HTTP client sending requests through base with the
+// retries, tracing, HAR capture, quota tracking and health monitoring of
+// upload clients. Pass it to WithHTTPClient to share one custom transport
+// between several clients. Each attempt of a request times out after 5
+// minutes; the waits between retries do not count.
+func NewHTTPClient(base http.RoundTripper) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			base: &tracingTransport{
+				base: &harTransport{
+					base: &quotaTransport{
+						base: &healthTransport{
+							base: &chaosTransport{
+								base:     base,
+								injector: DefaultFaultInjector,
+							},
+							monitor: DefaultHealthMonitor,
+						},
+						tracker: DefaultQuotaTracker,
+					},
+					recorder: DefaultHARRecorder,
+				},
+			},
+			policy: DefaultRetryPolicy,
+		},
+	}
+}
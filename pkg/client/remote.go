@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// urlDomains holds the domains published URLs are printed with instead of
+// {account}.vtexassets.com, set with SetURLDomain
+var urlDomains = struct {
+	sync.RWMutex
+	all       string            // applies to every account
+	byAccount map[string]string // per-account domains, used when all is empty
+}{byAccount: map[string]string{}}
+
+// SetURLDomain makes the published URLs of account's files use domain (e.g.
+// "mystore.vteximg.com.br" or "www.mystore.com") instead of
+// {account}.vtexassets.com. An empty account applies domain to every account;
+// an empty domain restores vtexassets.com.
+func SetURLDomain(account, domain string) {
+	urlDomains.Lock()
+	defer urlDomains.Unlock()
+	if account == "" {
+		urlDomains.all = domain
+	} else {
+		urlDomains.byAccount[account] = domain
+	}
+}
+
+// LinkURL returns fileURL on the domain set with SetURLDomain for its account.
+// URLs that are not on vtexassets.com are returned unchanged.
+func LinkURL(fileURL string) string {
+	u, err := neturl.Parse(fileURL)
+	if err != nil {
+		return fileURL
+	}
+	account, ok := strings.CutSuffix(u.Hostname(), ".vtexassets.com")
+	if !ok {
+		return fileURL
+	}
+
+	urlDomains.RLock()
+	domain := urlDomains.all
+	if domain == "" {
+		domain = urlDomains.byAccount[account]
+	}
+	urlDomains.RUnlock()
+
+	if domain == "" {
+		return fileURL
+	}
+	u.Host = domain
+	return u.String()
+}
+
+// PublicFileURL returns the public URL of a CMS file uploaded into the given
+// file area: /files for CMSFilesArea, /arquivos for every other area
+func PublicFileURL(account, fileType, fileName string) string {
+	return publicFileURL(account, "", fileType, fileName)
+}
+
+// publicFileURL returns the public URL of a CMS file, with /files URLs on the
+// host of env (empty for the environment set with SetEnvironment)
+func publicFileURL(account, env, fileType, fileName string) string {
+	if fileType == CMSFilesArea {
+		return fmt.Sprintf("https://%s/files/%s", commerceHost(account, env), neturl.PathEscape(fileName))
+	}
+	return fmt.Sprintf("https://%s.vtexassets.com/arquivos/%s", account, neturl.PathEscape(fileName))
+}
+
+// RemoteMatches reports whether the published CMS file already has the same
+// content as the local file. The public URL is checked with HEAD first: a
+// different Content-Length means the file changed, and an ETag holding the
+// MD5 or SHA-256 of the content is compared directly. Otherwise the file is
+// downloaded and hashed, which is still cheaper than uploading it again.
+func (c *CMSFilePickerClient) RemoteMatches(ctx context.Context, fileName, localPath string) (bool, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, err
+	}
+
+	url := publicFileURL(c.account, c.environment, c.fileTypeFor(fileName), fileName)
+	resp, err := c.fetch(ctx, http.MethodHead, url)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("HEAD %s returned status %d", url, resp.StatusCode)
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength != info.Size() {
+		return false, nil
+	}
+
+	md5Hex, sha256Hex, err := hashLocalFile(localPath)
+	if err != nil {
+		return false, err
+	}
+
+	etag := strings.ToLower(strings.Trim(strings.TrimPrefix(resp.Header.Get("ETag"), "W/"), `"`))
+	if etag == md5Hex || etag == sha256Hex {
+		return true, nil
+	}
+
+	c.logger.Debug("ETag is not a content hash, downloading to compare", "etag", etag, "url", url)
+
+	resp, err = c.fetch(ctx, http.MethodGet, url)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, resp.Body); err != nil {
+		return false, fmt.Errorf("failed to read remote file: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)) == sha256Hex, nil
+}
+
+// fetch sends a request without a body to a public URL
+func (c *CMSFilePickerClient) fetch(ctx context.Context, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+// hashLocalFile returns the hex MD5 and SHA-256 digests of a local file
+func hashLocalFile(path string) (string, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	md5Hasher := md5.New()
+	sha256Hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(md5Hasher, sha256Hasher), file); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(md5Hasher.Sum(nil)), hex.EncodeToString(sha256Hasher.Sum(nil)), nil
+}
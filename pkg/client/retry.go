@@ -0,0 +1,318 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxRetries is the number of times a transient failure is retried
+const DefaultMaxRetries = 3
+
+// maxRetryAfter caps the pause requested by a Retry-After header; longer
+// pauses fail the request instead of stalling the run
+const maxRetryAfter = 5 * time.Minute
+
+// attemptTimeout bounds each attempt of a request, from sending it to reading
+// the end of its response. Backoff and Retry-After pauses are not included,
+// so a request honoring a long pause still gets a full attempt afterwards.
+const attemptTimeout = 5 * time.Minute
+
+// RetryPolicy controls how requests failing with HTTP 429, 5xx or network
+// errors are retried: with exponential backoff and jitter, up to a maximum
+// number of retries
+type RetryPolicy struct {
+	mu         sync.Mutex
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	pauseUntil time.Time // set by Retry-After; holds back every request
+
+	// OnPause is called when a Retry-After header pauses all requests
+	OnPause func(delay time.Duration)
+
+	// OnRetry is called before waiting to retry a request
+	OnRetry func(req *http.Request, attempt int, delay time.Duration, reason string)
+}
+
+// DefaultRetryPolicy is shared by all clients created in this process
+var DefaultRetryPolicy = &RetryPolicy{
+	maxRetries: DefaultMaxRetries,
+	baseDelay:  time.Second,
+	maxDelay:   30 * time.Second,
+}
+
+// Configure sets the maximum number of retries per request (0 disables retries)
+func (p *RetryPolicy) Configure(maxRetries int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxRetries = maxRetries
+}
+
+// settings returns the current retry settings
+func (p *RetryPolicy) settings() (int, time.Duration, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxRetries, p.baseDelay, p.maxDelay
+}
+
+// pause holds back all requests for delay, as requested by a Retry-After header
+func (p *RetryPolicy) pause(delay time.Duration) {
+	p.mu.Lock()
+	until := time.Now().Add(delay)
+	extended := until.After(p.pauseUntil)
+	if extended {
+		p.pauseUntil = until
+	}
+	onPause := p.OnPause
+	p.mu.Unlock()
+
+	if extended && onPause != nil {
+		onPause(delay)
+	}
+}
+
+// wait blocks until a Retry-After pause is over or ctx is done
+func (p *RetryPolicy) wait(ctx context.Context) error {
+	p.mu.Lock()
+	delay := time.Until(p.pauseUntil)
+	p.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date, returning 0 when it is absent or invalid
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(0, time.Duration(seconds)*time.Second)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, time.Until(date))
+	}
+	return 0
+}
+
+// backoff returns the delay before the given retry (1-based): the base delay
+// doubled per retry, capped, with jitter so workers don't retry in lockstep
+func backoff(retry int, base, max time.Duration) time.Duration {
+	delay := base << (retry - 1)
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// attemptCounterKey is the context key of a request's attempt counter
+type attemptCounterKey struct{}
+
+// withAttemptCounter returns a context whose requests count their attempts
+// (the first try included) in the returned counter
+func withAttemptCounter(ctx context.Context) (context.Context, *int32) {
+	counter := new(int32)
+	return context.WithValue(ctx, attemptCounterKey{}, counter), counter
+}
+
+// retryModeKey is the context key of a request's retryMode
+type retryModeKey struct{}
+
+// retryMode limits which failures retryTransport retries for a request
+type retryMode int
+
+const (
+	// retryTransient retries HTTP 429, 5xx and network errors
+	retryTransient retryMode = iota
+
+	// retryStatus retries HTTP 429 and 5xx only. A network error may hide a
+	// request VTEX already processed, so non-idempotent uploads are not sent again.
+	retryStatus
+
+	// retryNever sends the request once; the caller retries it
+	retryNever
+)
+
+// withRetryMode returns a context whose requests are retried following mode
+func withRetryMode(ctx context.Context, mode retryMode) context.Context {
+	return context.WithValue(ctx, retryModeKey{}, mode)
+}
+
+// retryTransport retries transient failures following a RetryPolicy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy *RetryPolicy
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries, baseDelay, maxDelay := t.policy.settings()
+	counter, _ := req.Context().Value(attemptCounterKey{}).(*int32)
+	mode, _ := req.Context().Value(retryModeKey{}).(retryMode)
+	if mode == retryNever {
+		maxRetries = 0
+	}
+
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 {
+			// Requests with a body can only be retried when it can be rewound
+			attempt = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attempt.Body = body
+			}
+		}
+		if err := t.policy.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		if counter != nil {
+			atomic.AddInt32(counter, 1)
+		}
+
+		attemptCtx, cancel := context.WithTimeout(req.Context(), attemptTimeout)
+		resp, err := t.base.RoundTrip(attempt.WithContext(attemptCtx))
+		if err != nil {
+			cancel()
+		} else {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		}
+		reason := retryReason(resp, err)
+		if err != nil && mode == retryStatus {
+			reason = ""
+		}
+
+		// VTEX asks for a pause on 429/503: hold back every worker, not just this one
+		requested := retryAfter(resp)
+		if reason != "" && requested > maxRetryAfter {
+			return resp, err
+		}
+		if reason != "" && requested > 0 {
+			t.policy.pause(requested)
+		}
+
+		canRewind := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if reason == "" || retry >= maxRetries || !canRewind {
+			return resp, err
+		}
+
+		delay := backoff(retry+1, baseDelay, maxDelay)
+		if requested > 0 {
+			delay = requested
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if t.policy.OnRetry != nil {
+			t.policy.OnRetry(req, retry+2, delay, reason)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// cancelOnClose releases the timeout of an attempt once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// do sends the requests built by newRequest through httpClient until one gets
+// a response that is not HTTP 429 or 5xx, or the retries run out, waiting
+// between attempts like retryTransport. A new request is built for every
+// attempt, with ctx limited to a single try, for requests that cannot be
+// replayed as sent, such as CMS uploads whose requestToken expires within
+// seconds. Network errors are not retried, as VTEX may have processed the
+// request.
+func (p *RetryPolicy) do(ctx context.Context, httpClient *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	maxRetries, baseDelay, maxDelay := p.settings()
+	attemptCtx := withRetryMode(ctx, retryNever)
+
+	for retry := 0; ; retry++ {
+		req, err := newRequest(attemptCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		if !IsCongestionStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		// retryTransport already paused every worker for the Retry-After
+		// delay, or gave up on a longer one
+		requested := retryAfter(resp)
+		if requested > maxRetryAfter || retry >= maxRetries {
+			return resp, nil
+		}
+
+		delay := backoff(retry+1, baseDelay, maxDelay)
+		if requested > 0 {
+			delay = requested
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if p.OnRetry != nil {
+			p.OnRetry(req, retry+2, delay, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryReason describes why a response should be retried, or returns an
+// empty string when it should not
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return ""
+		}
+		return err.Error()
+	}
+	if IsCongestionStatus(resp.StatusCode) {
+		return fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return ""
+}
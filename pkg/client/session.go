@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/auth"
+)
+
+// VerifySession asks VTEX ID whether the user token of authenticator is still
+// accepted for account, so an expired session is reported before any upload.
+// App Keys are not user tokens; verify them with VerifyAppKey.
+// It returns the authenticated user. Errors wrap ErrAuthFailed when VTEX ID
+// rejects the credentials; other errors mean the check itself failed.
+func VerifySession(ctx context.Context, account string, authenticator *auth.Authenticator, opts ...Option) (string, error) {
+	o := applyOptions(opts)
+	url := fmt.Sprintf("%s/api/vtexid/pub/authenticated/user", cmsBaseURL(account, o.environment))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	authenticator.AddAuthHeaders(req)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return "", fmt.Errorf("%w (HTTP %d)", ErrAuthFailed, resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("VTEX ID returned status %d", resp.StatusCode)
+	}
+
+	// VTEX ID answers "null" for tokens it does not accept
+	if bytes.Equal(bytes.TrimSpace(body), []byte("null")) || len(bytes.TrimSpace(body)) == 0 {
+		return "", fmt.Errorf("%w: VTEX ID did not accept the credentials", ErrAuthFailed)
+	}
+
+	var user struct {
+		User string `json:"user"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("failed to parse VTEX ID response: %w", err)
+	}
+	return user.User, nil
+}
+
+// VerifyAppKey asks VTEX ID whether account accepts an App Key and App Token,
+// by exchanging them for a token as the App Key login does. Errors wrap
+// ErrAuthFailed when VTEX ID rejects the credentials; other errors mean the
+// check itself failed.
+func VerifyAppKey(ctx context.Context, account, appKey, appToken string, opts ...Option) error {
+	o := applyOptions(opts)
+	url := fmt.Sprintf("%s/api/vtexid/apptoken/login?an=%s", cmsBaseURL(account, o.environment), neturl.QueryEscape(account))
+
+	payload, err := json.Marshal(map[string]string{"appkey": appKey, "apptoken": appToken})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return fmt.Errorf("%w (HTTP %d)", ErrAuthFailed, resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("VTEX ID returned status %d", resp.StatusCode)
+	}
+
+	var login struct {
+		AuthStatus string `json:"authStatus"`
+	}
+	if err := json.Unmarshal(body, &login); err != nil {
+		return fmt.Errorf("failed to parse VTEX ID response: %w", err)
+	}
+	if login.AuthStatus != "Success" {
+		return fmt.Errorf("%w: VTEX ID answered %q", ErrAuthFailed, login.AuthStatus)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// vtexTLSConfig is the TLS configuration of the shared VTEX client, set by
+// ConfigureTLS
+var vtexTLSConfig *tls.Config
+
+// ConfigureTLS makes requests to VTEX trust the certificates in caCertFile in
+// addition to the system pool, or skip verification entirely when insecure is
+// set, for TLS-intercepting proxies. Only the shared client of the upload
+// clients uses it: updates, webhooks and remote sources keep verifying
+// certificates against the system pool. It must be called before the first
+// upload client is created.
+func ConfigureTLS(caCertFile string, insecure bool) error {
+	if caCertFile == "" && !insecure {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in %s", caCertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	vtexTLSConfig = tlsConfig
+	return nil
+}
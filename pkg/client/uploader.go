@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+)
+
+// UploadRequest describes a file to upload
+type UploadRequest struct {
+	FilePath     string // local file to upload
+	FileName     string // remote file name, defaults to the base name of FilePath
+	ShowProgress bool   // show a progress bar while sending the file
+
+	// Verify downloads the published file after the upload and fails the
+	// upload with ErrVerifyFailed when its content differs from the local file
+	Verify bool
+}
+
+// name returns the remote file name of the request
+func (r UploadRequest) name() string {
+	if r.FileName == "" {
+		return filepath.Base(r.FilePath)
+	}
+	return r.FileName
+}
+
+// Uploader uploads files with one VTEX API. It is implemented by
+// CMSFilePickerClient and GraphQLClient.
+type Uploader interface {
+	// Upload uploads a single file
+	Upload(ctx context.Context, req UploadRequest) (*UploadResult, error)
+
+	// Exists reports whether a file with the given name is already published
+	Exists(ctx context.Context, name string) (bool, error)
+}
+
+// ReaderUploader is an Uploader that can also upload content from an io.Reader
+type ReaderUploader interface {
+	Uploader
+
+	// UploadReader uploads size bytes read from r under the given remote file name
+	UploadReader(ctx context.Context, r io.Reader, name string, size int64) (*UploadResult, error)
+}
+
+var (
+	_ ReaderUploader = (*CMSFilePickerClient)(nil)
+	_ ReaderUploader = (*GraphQLClient)(nil)
+)
+
+// Upload uploads a single file using CMS FilePicker
+func (c *CMSFilePickerClient) Upload(ctx context.Context, req UploadRequest) (*UploadResult, error) {
+	return c.uploadFile(ctx, req.FilePath, req.name(), req.ShowProgress, req.Verify)
+}
+
+// Exists reports whether a file with the given name exists in FilePicker
+func (c *CMSFilePickerClient) Exists(ctx context.Context, name string) (bool, error) {
+	return c.CheckFileExists(ctx, name)
+}
+
+// Upload uploads a single file using GraphQL mutation
+func (c *GraphQLClient) Upload(ctx context.Context, req UploadRequest) (*UploadResult, error) {
+	return c.uploadFile(ctx, req.FilePath, req.name(), req.ShowProgress, req.Verify)
+}
+
+// Exists always reports false: GraphQL generates a unique URL for every
+// upload, so a file name never conflicts with a published file
+func (c *GraphQLClient) Exists(ctx context.Context, name string) (bool, error) {
+	return false, nil
+}
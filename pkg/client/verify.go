@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// verifyAttempts is how many times a published file that is not found yet
+	// is requested again, as new files can take a moment to be served
+	verifyAttempts = 3
+	verifyDelay    = 2 * time.Second
+)
+
+// ErrVerifyFailed is wrapped by errors of uploads whose published file does
+// not have the content of the local file
+var ErrVerifyFailed = errors.New("upload verification failed")
+
+// verifyUpload downloads the published file of result and compares its size
+// and SHA-256 digest with the local file, marking the result failed when they
+// differ. The URL gets a cache-busting query so the CDN does not answer with a
+// previous version of an overwritten file.
+func verifyUpload(ctx context.Context, httpClient *http.Client, logger *slog.Logger, result *UploadResult, localPath string) error {
+	err := compareWithPublished(ctx, httpClient, logger, result.FileURL, localPath)
+	if err != nil {
+		result.Success = false
+		result.Error = err
+		return err
+	}
+	logger.Debug("upload verified", "file", result.FileName, "url", result.FileURL)
+	return nil
+}
+
+// compareWithPublished checks that the file served at fileURL has the content
+// of the local file
+func compareWithPublished(ctx context.Context, httpClient *http.Client, logger *slog.Logger, fileURL, localPath string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to verify upload: %w", err)
+	}
+	_, localHash, err := hashLocalFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to verify upload: %w", err)
+	}
+
+	separator := "?"
+	if strings.Contains(fileURL, "?") {
+		separator = "&"
+	}
+	url := fileURL + separator + "v=" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to verify upload: %w", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to verify upload: request failed: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusNotFound && attempt < verifyAttempts {
+			resp.Body.Close()
+			logger.Debug("published file not found yet, retrying verification", "url", fileURL, "attempt", attempt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(verifyDelay):
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%w: GET %s returned status %d", ErrVerifyFailed, fileURL, resp.StatusCode)
+		}
+
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to verify upload: failed to read published file: %w", err)
+		}
+		if size != info.Size() {
+			return fmt.Errorf("%w: published file is %d bytes, local file is %d bytes", ErrVerifyFailed, size, info.Size())
+		}
+		if remoteHash := hex.EncodeToString(hasher.Sum(nil)); remoteHash != localHash {
+			return fmt.Errorf("%w: published file differs from local file (sha256 %s, expected %s)", ErrVerifyFailed, remoteHash, localHash)
+		}
+		return nil
+	}
+}
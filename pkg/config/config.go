@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/adrg/xdg"
+)
+
+const configFileName = "vtex-files-manager/config.json"
+
+// GoogleDriveConfig holds credentials for reading Google Drive shared links
+type GoogleDriveConfig struct {
+	APIKey string `json:"apiKey,omitempty"`
+}
+
+// DropboxConfig holds credentials for reading Dropbox shared links
+type DropboxConfig struct {
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// S3Config selects the AWS settings for s3:// sources; credentials come from
+// the AWS SDK default chain
+type S3Config struct {
+	Profile string `json:"profile,omitempty"` // shared config profile (default AWS_PROFILE or "default")
+	Region  string `json:"region,omitempty"`  // bucket region (default AWS_REGION, or looked up)
+}
+
+// GCSConfig selects the Google Cloud credentials for gs:// sources; without a
+// key file, Application Default Credentials are used
+type GCSConfig struct {
+	CredentialsFile string `json:"credentialsFile,omitempty"` // service account key file
+}
+
+// AzureConfig selects the storage account for az:// sources; credentials
+// come from the Azure SDK default chain
+type AzureConfig struct {
+	Account string `json:"account,omitempty"` // storage account name (default AZURE_STORAGE_ACCOUNT)
+}
+
+// SourcesConfig holds credentials for remote upload sources
+type SourcesConfig struct {
+	GoogleDrive GoogleDriveConfig `json:"googleDrive"`
+	Dropbox     DropboxConfig     `json:"dropbox"`
+	S3          S3Config          `json:"s3"`
+	GCS         GCSConfig         `json:"gcs"`
+	Azure       AzureConfig       `json:"azure"`
+}
+
+// QuotaConfig holds the VTEX request quota awareness settings
+type QuotaConfig struct {
+	RequestsPerMinute int  `json:"requestsPerMinute,omitempty"`
+	Throttle          bool `json:"throttle,omitempty"`
+}
+
+// RetryConfig holds the retry settings for transient failures
+type RetryConfig struct {
+	// MaxRetries is the number of retries per request; nil uses the default
+	MaxRetries *int `json:"maxRetries,omitempty"`
+}
+
+// EndpointsConfig overrides the base URLs of the VTEX APIs, e.g. for a mock
+// server; {account} and {workspace} are replaced in both
+type EndpointsConfig struct {
+	CMS     string `json:"cms,omitempty"`     // replaces https://{account}.vtexcommercestable.com.br
+	GraphQL string `json:"graphql,omitempty"` // replaces https://{account}.myvtex.com
+}
+
+// LimitsConfig holds upload limits
+type LimitsConfig struct {
+	// MaxFileSizeMB is the maximum file size in MB; 0 uses the default of 5MB
+	MaxFileSizeMB float64 `json:"maxFileSizeMB,omitempty"`
+}
+
+// ExtensionsConfig changes the file extensions an upload method accepts
+type ExtensionsConfig struct {
+	Add    []string `json:"add,omitempty"`    // extensions accepted in addition to the built-in list
+	Remove []string `json:"remove,omitempty"` // extensions no longer accepted
+	Only   []string `json:"only,omitempty"`   // replaces the built-in list
+}
+
+// ChatWebhookConfig holds a Slack or Microsoft Teams incoming webhook
+type ChatWebhookConfig struct {
+	WebhookURL string `json:"webhookUrl,omitempty"`
+	// OnlyOnFailure skips the message when every file was uploaded
+	OnlyOnFailure bool `json:"onlyOnFailure,omitempty"`
+}
+
+// NotificationsConfig holds the chat webhooks notified when uploads complete
+type NotificationsConfig struct {
+	Slack ChatWebhookConfig `json:"slack"`
+	Teams ChatWebhookConfig `json:"teams"`
+}
+
+// AccountConfig holds per-account defaults
+type AccountConfig struct {
+	Method string `json:"method,omitempty"` // default upload method: graphql or cms
+	Bucket string `json:"bucket,omitempty"` // default GraphQL bucket
+
+	// Environment is the VTEX environment of the account: stable or beta
+	Environment string `json:"environment,omitempty"`
+
+	// URLDomain replaces {account}.vtexassets.com in printed and logged URLs
+	URLDomain string `json:"urlDomain,omitempty"`
+
+	// RehearsalAccount is the staging account used by --rehearse for CMS uploads
+	RehearsalAccount string `json:"rehearsalAccount,omitempty"`
+}
+
+// ProfileConfig bundles connection settings selected together with --profile
+type ProfileConfig struct {
+	Account     string `json:"account,omitempty"`
+	Workspace   string `json:"workspace,omitempty"`
+	Method      string `json:"method,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+
+	// AppKey authenticates with an App Key instead of the VTEX CLI session; the
+	// App Token is read from the AppTokenEnv environment variable (default
+	// VTEX_APP_TOKEN) so it is never stored in the config file
+	AppKey      string `json:"appKey,omitempty"`
+	AppTokenEnv string `json:"appTokenEnv,omitempty"`
+}
+
+// Config represents the vfm configuration file
+type Config struct {
+	Accounts map[string]AccountConfig `json:"accounts,omitempty"`
+	Profiles map[string]ProfileConfig `json:"profiles,omitempty"`
+	Sources  SourcesConfig            `json:"sources"`
+	Quota    QuotaConfig              `json:"quota"`
+	Retry    RetryConfig              `json:"retry"`
+	Language string                   `json:"language,omitempty"` // language of messages: en, pt-BR or es
+	Limits   LimitsConfig             `json:"limits"`
+
+	Endpoints EndpointsConfig `json:"endpoints"`
+
+	// Extensions is keyed by upload method: "cms" or "graphql"
+	Extensions map[string]ExtensionsConfig `json:"extensions,omitempty"`
+
+	Notifications NotificationsConfig `json:"notifications"`
+
+	// UpdateCheck prints a notice after commands when a newer version of vfm
+	// is available, checking GitHub Releases at most once a day
+	UpdateCheck bool `json:"updateCheck,omitempty"`
+}
+
+// Load reads the configuration file. A missing file yields an empty configuration.
+// Environment variables take precedence over values from the file.
+func Load() (*Config, error) {
+	cfg := &Config{}
+
+	if path, err := xdg.SearchConfigFile(configFileName); err == nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if value := os.Getenv("VFM_GDRIVE_API_KEY"); value != "" {
+		cfg.Sources.GoogleDrive.APIKey = value
+	}
+	if value := os.Getenv("VFM_DROPBOX_TOKEN"); value != "" {
+		cfg.Sources.Dropbox.AccessToken = value
+	}
+	if value := os.Getenv("VFM_CMS_ENDPOINT"); value != "" {
+		cfg.Endpoints.CMS = value
+	}
+	if value := os.Getenv("VFM_GRAPHQL_ENDPOINT"); value != "" {
+		cfg.Endpoints.GraphQL = value
+	}
+	if value := os.Getenv("VFM_SLACK_WEBHOOK_URL"); value != "" {
+		cfg.Notifications.Slack.WebhookURL = value
+	}
+	if value := os.Getenv("VFM_TEAMS_WEBHOOK_URL"); value != "" {
+		cfg.Notifications.Teams.WebhookURL = value
+	}
+	if value, err := strconv.ParseBool(os.Getenv("VFM_UPDATE_CHECK")); err == nil {
+		cfg.UpdateCheck = value
+	}
+
+	return cfg, nil
+}
+
+// GetConfigPath returns the path to the configuration file
+func GetConfigPath() (string, error) {
+	return xdg.ConfigFile(configFileName)
+}
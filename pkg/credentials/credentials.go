@@ -0,0 +1,130 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name secrets are stored under in the OS keyring
+const keyringService = "vtex-files-manager"
+
+// indexFileName lists the stored accounts; secrets themselves stay in the keyring
+const indexFileName = "vtex-files-manager/credentials.json"
+
+// AppKey is an App Key/App Token pair stored for an account
+type AppKey struct {
+	AppKey   string `json:"appKey"`
+	AppToken string `json:"appToken"`
+}
+
+// Stored describes stored credentials without their secret
+type Stored struct {
+	Account string    `json:"account"`
+	AppKey  string    `json:"appKey"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// Save stores the App Key and App Token of account in the OS keyring
+// (Keychain, Windows Credential Manager or the Secret Service)
+func Save(account, appKey, appToken string) error {
+	secret, err := json.Marshal(AppKey{AppKey: appKey, AppToken: appToken})
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(keyringService, account, string(secret)); err != nil {
+		return fmt.Errorf("failed to store credentials in the OS keyring: %w", err)
+	}
+
+	index, err := List()
+	if err != nil {
+		return err
+	}
+	index = remove(index, account)
+	index = append(index, Stored{Account: account, AppKey: appKey, SavedAt: time.Now()})
+	return saveIndex(index)
+}
+
+// Load returns the credentials stored for account, or nil when there are none
+func Load(account string) (*AppKey, error) {
+	secret, err := keyring.Get(keyringService, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials from the OS keyring: %w", err)
+	}
+
+	creds := &AppKey{}
+	if err := json.Unmarshal([]byte(secret), creds); err != nil {
+		return nil, fmt.Errorf("failed to parse stored credentials for %s: %w", account, err)
+	}
+	return creds, nil
+}
+
+// Delete removes the credentials stored for account
+func Delete(account string) error {
+	err := keyring.Delete(keyringService, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("no credentials stored for %s", account)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete credentials from the OS keyring: %w", err)
+	}
+
+	index, err := List()
+	if err != nil {
+		return err
+	}
+	return saveIndex(remove(index, account))
+}
+
+// List returns the accounts with stored credentials, sorted by account
+func List() ([]Stored, error) {
+	path, err := xdg.SearchStateFile(indexFileName)
+	if err != nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials index: %w", err)
+	}
+
+	var index []Stored
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+func saveIndex(index []Stored) error {
+	path, err := xdg.StateFile(indexFileName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve credentials index path: %w", err)
+	}
+
+	sort.Slice(index, func(i, j int) bool { return index[i].Account < index[j].Account })
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// remove returns index without the entry for account
+func remove(index []Stored, account string) []Stored {
+	kept := index[:0]
+	for _, s := range index {
+		if s.Account != account {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
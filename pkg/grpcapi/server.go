@@ -0,0 +1,247 @@
+// Package grpcapi implements the FilesManager gRPC service defined in
+// proto/vfm/v1/files_manager.proto. The generated code is in package vfmv1.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/grpcapi/vfmv1"
+	"github.com/glinharesb/vtex-files-manager/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultLogsLimit is the number of log entries GetLogs returns without a limit
+const defaultLogsLimit = 50
+
+// messageOverhead is room for the rest of a request besides the file content
+const messageOverhead = 64 * 1024
+
+// UploadFunc uploads content under the given remote file name
+type UploadFunc func(ctx context.Context, content []byte, fileName string) (*client.UploadResult, error)
+
+// Server implements the FilesManager service
+type Server struct {
+	vfmv1.UnimplementedFilesManagerServer
+
+	token    string
+	account  string
+	upload   UploadFunc
+	uploadMu sync.Mutex // upload clients are not safe for concurrent use
+}
+
+// NewServer creates a FilesManager server for the given VTEX account. When
+// token is not empty, every call must carry it in an "authorization: Bearer
+// <token>" metadata entry. GetLogs only returns the log entries of account.
+func NewServer(upload UploadFunc, token, account string) *Server {
+	return &Server{upload: upload, token: token, account: account}
+}
+
+// GRPCServer returns a gRPC server with the FilesManager service and server
+// reflection registered, so tools like grpcurl can discover the API. Options
+// such as TLS credentials are passed on to grpc.NewServer.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		// Fit a file of the maximum upload size; the gRPC default of 4MB is
+		// smaller than the upload limit
+		grpc.MaxRecvMsgSize(int(client.FileSizeLimit()) + messageOverhead),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}, opts...)
+	server := grpc.NewServer(opts...)
+	vfmv1.RegisterFilesManagerServer(server, s)
+	reflection.Register(server)
+	return server
+}
+
+// authorize rejects calls that don't carry the server token
+func (s *Server) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		provided := strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+// Upload uploads one file
+func (s *Server) Upload(ctx context.Context, req *vfmv1.UploadRequest) (*vfmv1.UploadResponse, error) {
+	return s.uploadFile(ctx, req)
+}
+
+// BatchUpload uploads the files of the request stream in order, streaming an
+// event when each upload starts and ends
+func (s *Server) BatchUpload(stream vfmv1.FilesManager_BatchUploadServer) error {
+	var succeeded, failed int32
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		event := &vfmv1.BatchUploadEvent{
+			Index:     index,
+			FileName:  req.GetFileName(),
+			Status:    vfmv1.BatchUploadEvent_STATUS_STARTED,
+			Succeeded: succeeded,
+			Failed:    failed,
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+
+		result, err := s.uploadFile(stream.Context(), req)
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		if err != nil {
+			failed++
+			event.Status = vfmv1.BatchUploadEvent_STATUS_FAILED
+			event.Error = status.Convert(err).Message()
+		} else {
+			succeeded++
+			event.Status = vfmv1.BatchUploadEvent_STATUS_SUCCEEDED
+			event.Result = result
+		}
+		event.Succeeded, event.Failed = succeeded, failed
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+// GetLogs returns the most recent entries of the upload log for the account
+// of the server; uploads to other accounts are not exposed to its clients
+func (s *Server) GetLogs(ctx context.Context, req *vfmv1.GetLogsRequest) (*vfmv1.GetLogsResponse, error) {
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+	if method := req.GetMethod(); method != "" && method != "cms" && method != "graphql" {
+		return nil, status.Error(codes.InvalidArgument, "method must be 'cms' or 'graphql'")
+	}
+
+	entries, err := logger.ReadLogs()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read logs: %v", err)
+	}
+
+	var matched []*vfmv1.LogEntry
+	for _, entry := range entries {
+		if s.account != "" && entry.Account != s.account {
+			continue
+		}
+		if req.GetFailedOnly() && entry.Status != "failed" {
+			continue
+		}
+		if req.GetMethod() != "" && entry.Method != req.GetMethod() {
+			continue
+		}
+		matched = append(matched, &vfmv1.LogEntry{
+			Timestamp: timestamppb.New(entry.Timestamp),
+			File:      entry.File,
+			Size:      entry.Size,
+			Method:    entry.Method,
+			Account:   entry.Account,
+			Workspace: entry.Workspace,
+			Status:    entry.Status,
+			Url:       entry.URL,
+			Error:     entry.Error,
+		})
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultLogsLimit
+	}
+	if len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	return &vfmv1.GetLogsResponse{Entries: matched}, nil
+}
+
+// uploadStatus maps an upload error to a gRPC status. Codes that gRPC retry
+// policies retry (Unavailable, ResourceExhausted) are only used when VTEX
+// refused the upload or it never reached VTEX, so a retry cannot publish the
+// file twice.
+func uploadStatus(err error) error {
+	var statusErr *client.StatusError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, client.ErrAuthFailed):
+		// The server's VTEX session was rejected, not the caller's token
+		return status.Errorf(codes.FailedPrecondition, "VTEX session rejected: %v", err)
+	case errors.Is(err, client.ErrRejected):
+		return status.Errorf(codes.InvalidArgument, "upload rejected: %v", err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		return status.Errorf(codes.ResourceExhausted, "upload failed: %v", err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= 500:
+		return status.Errorf(codes.Unavailable, "upload failed: %v", err)
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		// The connection could not be opened, nothing was sent
+		return status.Errorf(codes.Unavailable, "upload failed: %v", err)
+	}
+	// The request may have been processed before it failed
+	return status.Errorf(codes.Unknown, "upload failed: %v", err)
+}
+
+// uploadFile validates and uploads the file of a request
+func (s *Server) uploadFile(ctx context.Context, req *vfmv1.UploadRequest) (*vfmv1.UploadResponse, error) {
+	name := req.GetFileName()
+	if name == "" || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file name %q: must be a file name, not a path", name)
+	}
+	if err := client.ValidateContent(name, int64(len(req.GetContent()))); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.uploadMu.Lock()
+	result, err := s.upload(ctx, req.GetContent(), name)
+	s.uploadMu.Unlock()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, uploadStatus(err)
+	}
+
+	resp := &vfmv1.UploadResponse{
+		FileName: result.FileName,
+		FileUrl:  result.FileURL,
+		Size:     int64(len(req.GetContent())),
+	}
+	if result.Image != nil {
+		resp.Width, resp.Height = int32(result.Image.Width), int32(result.Image.Height)
+	}
+	return resp, nil
+}
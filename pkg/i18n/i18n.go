@@ -0,0 +1,125 @@
+// Package i18n translates the messages vfm prints to the terminal. Messages
+// are looked up by their English text, so a message without a translation is
+// printed in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Languages with a message catalog
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+	Spanish    = "es"
+)
+
+// catalogs maps a language to its translations, keyed by the English message
+var catalogs = map[string]map[string]string{
+	Portuguese: portuguese,
+	Spanish:    spanish,
+}
+
+var (
+	mu       sync.RWMutex
+	language = English
+)
+
+// Supported returns the languages messages can be printed in
+func Supported() []string {
+	return []string{English, Portuguese, Spanish}
+}
+
+// SetLanguage selects the language of messages. It accepts language tags and
+// POSIX locales, e.g. "pt-BR", "pt", "pt_BR.UTF-8" or "es_MX".
+func SetLanguage(lang string) error {
+	normalized, ok := Normalize(lang)
+	if !ok {
+		return fmt.Errorf("unsupported language: %s (must be one of %s)", lang, strings.Join(Supported(), ", "))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	language = normalized
+	return nil
+}
+
+// Language returns the selected language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// Normalize returns the supported language matching a language tag or POSIX
+// locale, and false when there is none
+func Normalize(lang string) (string, bool) {
+	// Drop the encoding and modifier of POSIX locales (pt_BR.UTF-8@euro)
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	base, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(lang), "_", "-"), "-")
+
+	switch base {
+	case "en", "c", "posix":
+		return English, true
+	case "pt":
+		return Portuguese, true
+	case "es":
+		return Spanish, true
+	}
+	return "", false
+}
+
+// Detect returns the language of the user's locale, read from the LC_ALL,
+// LC_MESSAGES and LANG environment variables, or English when it is not
+// supported
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		// The first variable set decides, as in setlocale
+		if lang, ok := Normalize(value); ok {
+			return lang
+		}
+		return English
+	}
+	return English
+}
+
+// T returns the translation of msg in the selected language, or msg itself
+// when it has none
+func T(msg string) string {
+	lang := Language()
+	if translated, ok := catalogs[lang][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf returns an error with the translation of format. Like fmt.Errorf, it
+// wraps the argument of a %w verb.
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// IsYes reports whether answer accepts a yes/no prompt: "y" or "yes", or
+// "s", "sim", "si" and "sí" in Portuguese and Spanish
+func IsYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "s", "sim", "si", "sí":
+		return Language() != English
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package i18n
+
+// portuguese holds the Brazilian Portuguese (pt-BR) translations
+var portuguese = map[string]string{
+	"Account:       %s\n":                          "Conta:         %s\n",
+	"Workspace:     %s\n":                          "Workspace:     %s\n",
+	"User:          %s\n":                          "Usuário:       %s\n",
+	"Method:        %s\n":                          "Método:        %s\n",
+	"File:          %s → %s (%.2f KB)\n":           "Arquivo:       %s → %s (%.2f KB)\n",
+	"File:          %s (%.2f KB)\n":                "Arquivo:       %s (%.2f KB)\n",
+	"Destination:   %s\n":                          "Destino:       %s\n",
+	"Files:         %d\n":                          "Arquivos:      %d\n",
+	"Manifest:      %s\n":                          "Manifesto:     %s\n",
+	"Directory:     %s\n":                          "Diretório:     %s\n",
+	"Files found:   %d (%.2f MB total)\n":          "Encontrados:   %d (%.2f MB no total)\n",
+	"Concurrency:   up to %d workers (adaptive)\n": "Concorrência:  até %d workers (adaptativa)\n",
+	"Concurrency:   %d workers\n":                  "Concorrência:  %d workers\n",
+	"Content type:  %s (--force-type: untested file types may be rejected by VTEX)": "Tipo:          %s (--force-type: tipos de arquivo não testados podem ser recusados pela VTEX)",
+	"=== VTEX File Upload ===":  "=== Envio de Arquivo VTEX ===",
+	"=== VTEX Batch Upload ===": "=== Envio em Lote VTEX ===",
+	"=== Upload Summary ===":    "=== Resumo do Envio ===",
+	"\n⚠️  WARNING: File already exists and will be OVERWRITTEN!": "\n⚠️  ATENÇÃO: O arquivo já existe e será SOBRESCRITO!",
+	"Dry run: no files were uploaded.":                            "Simulação: nenhum arquivo foi enviado.",
+	"Proceed with upload?":                                        "Prosseguir com o envio?",
+	"File exists. Overwrite?":                                     "O arquivo já existe. Sobrescrever?",
+	"%d file(s) will be overwritten. Continue?":                   "%d arquivo(s) serão sobrescritos. Continuar?",
+	"%s [y/N]: ":                                             "%s [s/N]: ",
+	"Upload cancelled.":                                      "Envio cancelado.",
+	"\n✗ Upload failed: %v\n":                                "\n✗ Falha no envio: %v\n",
+	"Transformed %s: %.2f KB → %.2f KB\n":                    "Transformado %s: %.2f KB → %.2f KB\n",
+	"Skipping %s: the published file is identical":           "Ignorando %s: o arquivo publicado é idêntico",
+	"✓ Upload successful!":                                   "✓ Envio concluído!",
+	"File URL: %s\n":                                         "URL:      %s\n",
+	"Image:    %s\n":                                         "Imagem:   %s\n",
+	"one or more uploads failed":                             "um ou mais envios falharam",
+	"No image files found in %s":                             "Nenhum arquivo encontrado em %s",
+	"%d unchanged file(s) skipped (--incremental)":           "%d arquivo(s) sem alterações ignorados (--incremental)",
+	"✓ Everything is up to date.":                            "✓ Tudo está atualizado.",
+	"Files to upload:":                                       "Arquivos a enviar:",
+	"  ... (%d more)\n":                                      "  ... (mais %d)\n",
+	"  ... and %d more\n":                                    "  ... e mais %d\n",
+	"SKIPPED":                                                "IGNORADOS",
+	"RENAMED":                                                "RENOMEADOS",
+	"%d file(s) already exist and will be %s:":               "%d arquivo(s) já existem e serão %s:",
+	"Existing files will be checked and %s during upload.\n": "Os arquivos existentes serão verificados e %s durante o envio.\n",
+	"✗ %d file(s) already exist:":                            "✗ %d arquivo(s) já existem:",
+	"⚠️  WARNING: %d file(s) already exist and will be OVERWRITTEN:": "⚠️  ATENÇÃO: %d arquivo(s) já existem e serão SOBRESCRITOS:",
+	"[Worker %d] Uploading: %s\n":                                    "[Worker %d] Enviando: %s\n",
+	"[Worker %d] Skipping existing file: %s\n":                       "[Worker %d] Ignorando arquivo existente: %s\n",
+	"[Worker %d] Skipping identical file: %s\n":                      "[Worker %d] Ignorando arquivo idêntico: %s\n",
+	"[Worker %d] Renaming existing file: %s → %s\n":                  "[Worker %d] Renomeando arquivo existente: %s → %s\n",
+	"[Worker %d] Transformed %s: %.2f KB → %.2f KB\n":                "[Worker %d] Transformado %s: %.2f KB → %.2f KB\n",
+	"[Worker %d] ✗ Failed: %v":                                       "[Worker %d] ✗ Falhou: %v",
+	"  ✗ Failed: %v":                                                 "  ✗ Falhou: %v",
+	"  ✓ Success: %s (%s)":                                           "  ✓ Sucesso: %s (%s)",
+	"  ✓ Success: %s":                                                "  ✓ Sucesso: %s",
+	"✗ Your VTEX session has expired: %d uploads in a row were rejected (HTTP 401/403).\n": "✗ Sua sessão VTEX expirou: %d envios seguidos foram recusados (HTTP 401/403).\n",
+	"  Stopped before the %d remaining file(s). Run 'vtex login', then %s.\n\n":            "  Interrompido antes do(s) %d arquivo(s) restante(s). Execute 'vtex login' e depois %s.\n\n",
+	"re-run the upload":                               "execute o envio novamente",
+	"Total files:     %d\n":                           "Total:           %d\n",
+	"Successful:      %d":                             "Enviados:        %d",
+	"Skipped:         %d":                             "Ignorados:       %d",
+	"Not attempted:   %d":                             "Não tentados:    %d",
+	"Failed:          %d":                             "Falharam:        %d",
+	"Failed:          %d\n":                           "Falharam:        %d\n",
+	"Retried:         %d file(s)":                     "Repetidos:       %d arquivo(s)",
+	"Transferred:     %s in %s (%s/s)\n":              "Transferido:     %s em %s (%s/s)\n",
+	"Uploaded files:":                                 "Arquivos enviados:",
+	"Renamed uploads:":                                "Envios renomeados:",
+	"Failed uploads:":                                 "Envios com falha:",
+	"invalid method: %s (must be 'graphql' or 'cms')": "método inválido: %s (deve ser 'graphql' ou 'cms')",
+	"%d file(s) already exist (--on-conflict fail)":   "%d arquivo(s) já existem (--on-conflict fail)",
+	"failed to access file: %w":                       "falha ao acessar o arquivo: %w",
+	"failed to find files: %w":                        "falha ao procurar arquivos: %w",
+	"\n⚠️  WARNING: This will permanently delete all upload logs!": "\n⚠️  ATENÇÃO: Isto apagará permanentemente todos os logs de envio!",
+	"Log file: %s\n":                           "Arquivo de log: %s\n",
+	"Total entries: %d\n\n":                    "Total de entradas: %d\n\n",
+	"Are you sure you want to clear all logs?": "Tem certeza de que deseja apagar todos os logs?",
+	"Operation cancelled.":                     "Operação cancelada.",
+	"\n✓ Logs cleared successfully!":           "\n✓ Logs apagados com sucesso!",
+	"Do you want to update?":                   "Deseja atualizar?",
+	"Update cancelled":                         "Atualização cancelada",
+	"Upload %d asset(s) and rewrite the references in %d theme file(s)?": "Enviar %d arquivo(s) e reescrever as referências em %d arquivo(s) do tema?",
+	"%d published file(s) will be overwritten. Continue?":                "%d arquivo(s) publicado(s) serão sobrescritos. Continuar?",
+	"Deploy cancelled.":         "Implantação cancelada.",
+	"authentication failed: %w": "falha na autenticação: %w",
+	"authentication failed: %w. Please run 'vtex login' and try again":                                           "falha na autenticação: %w. Execute 'vtex login' e tente novamente",
+	"⚠️  Your VTEX session expires in %s. Run 'vtex login' to renew it before long uploads.":                     "⚠️  Sua sessão VTEX expira em %s. Execute 'vtex login' para renová-la antes de envios longos.",
+	"authentication failed: your VTEX session for %s has expired (%v). Please run 'vtex login %s' and try again": "falha na autenticação: sua sessão VTEX de %s expirou (%v). Execute 'vtex login %s' e tente novamente",
+	"⚠️  Could not verify the VTEX session: %v":                                                                  "⚠️  Não foi possível verificar a sessão VTEX: %v",
+
+	"⚠️  %s already exists and will be replaced by --emit-manifest": "⚠️  %s já existe e será substituído pelo --emit-manifest",
+	"⚠️  %s already exists and will be replaced by --hash-names":    "⚠️  %s já existe e será substituído pelo --hash-names",
+
+	"--resume takes no directory or --manifest":                                         "--resume não aceita diretório nem --manifest",
+	"specify either a directory argument or --manifest":                                 "informe um diretório ou --manifest",
+	"invalid --on-conflict value: %s (must be 'skip', 'overwrite', 'rename' or 'fail')": "valor inválido para --on-conflict: %s (deve ser 'skip', 'overwrite', 'rename' ou 'fail')",
+	"reading the file list from stdin requires --yes or --dry-run":                      "ler a lista de arquivos da entrada padrão requer --yes ou --dry-run",
+	"invalid --on-duplicate value: %s (must be 'warn', 'skip' or 'upload')":             "valor inválido para --on-duplicate: %s (deve ser 'warn', 'skip' ou 'upload')",
+	"--incremental requires a local directory":                                          "--incremental requer um diretório local",
+	"failed to hash %s: %w":                                                             "falha ao calcular o hash de %s: %w",
+	"--file-type requires --method cms":                                                 "--file-type requer --method cms",
+	"--skip-identical requires --method cms":                                            "--skip-identical requer --method cms",
+	"--bucket requires --method graphql":                                                "--bucket requer --method graphql",
+	"--on-conflict %s requires --method cms (GraphQL generates unique file names)":      "--on-conflict %s requer --method cms (o GraphQL gera nomes de arquivo únicos)",
+	"--rehearse and --dry-run cannot be used together":                                  "--rehearse e --dry-run não podem ser usados juntos",
+	"--as can only be used when uploading a single file":                                "--as só pode ser usado ao enviar um único arquivo",
+	"failed to create staging directory: %w":                                            "falha ao criar o diretório temporário: %w",
+	"the interrupted batch uploads to account %s, but the VTEX CLI session is for %s":   "o lote interrompido envia para a conta %s, mas a sessão do VTEX CLI é de %s",
+	"the staged files of the interrupted batch are gone (%s); run the batch again":      "os arquivos preparados do lote interrompido não existem mais (%s); execute o lote novamente",
+	"failed to access %s: %w":                                                           "falha ao acessar %s: %w",
+	"invalid glob pattern: %s":                                                          "padrão glob inválido: %s",
+	"batch aborted after first failure (--fail-fast): %w":                               "lote interrompido após a primeira falha (--fail-fast): %w",
+	"could not resolve a free name for %s: %w":                                          "não foi possível encontrar um nome livre para %s: %w",
+	"batch aborted after %d consecutive authentication failures: %w":                    "lote interrompido após %d falhas de autenticação seguidas: %w",
+
+	"⚠️  %d group(s) of files have identical content (%s):": "⚠️  %d grupo(s) de arquivos têm conteúdo idêntico (%s):",
+	"will all be uploaded":                          "todos serão enviados",
+	"only the first of each group will be uploaded": "só o primeiro de cada grupo será enviado",
+	"Fetching files from %s...\n":                   "Baixando arquivos de %s...\n",
+	"Skipping unsupported file: %s":                 "Ignorando arquivo não suportado: %s",
+	"✓ The last batch already finished.":            "✓ O último lote já terminou.",
+	"=== Resuming VTEX Batch Upload ===":            "=== Retomando Envio em Lote VTEX ===",
+	"REHEARSAL: real uploads to account %s, workspace %s. Production is not touched.": "ENSAIO: envios reais para a conta %s, workspace %s. A produção não é alterada.",
+	"Source:        %s\n":             "Origem:        %s\n",
+	"Started:       %s\n":             "Início:        %s\n",
+	"Remaining:     %d of %d files\n": "Restantes:     %d de %d arquivos\n",
+	"VTEX is throttling or failing requests: reducing concurrency to %d": "A VTEX está limitando ou recusando requisições: reduzindo a concorrência para %d",
+}
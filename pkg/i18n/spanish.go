@@ -0,0 +1,129 @@
+package i18n
+
+// spanish holds the Spanish (es) translations
+var spanish = map[string]string{
+	"Account:       %s\n":                          "Cuenta:        %s\n",
+	"Workspace:     %s\n":                          "Workspace:     %s\n",
+	"User:          %s\n":                          "Usuario:       %s\n",
+	"Method:        %s\n":                          "Método:        %s\n",
+	"File:          %s → %s (%.2f KB)\n":           "Archivo:       %s → %s (%.2f KB)\n",
+	"File:          %s (%.2f KB)\n":                "Archivo:       %s (%.2f KB)\n",
+	"Destination:   %s\n":                          "Destino:       %s\n",
+	"Files:         %d\n":                          "Archivos:      %d\n",
+	"Manifest:      %s\n":                          "Manifiesto:    %s\n",
+	"Directory:     %s\n":                          "Directorio:    %s\n",
+	"Files found:   %d (%.2f MB total)\n":          "Encontrados:   %d (%.2f MB en total)\n",
+	"Concurrency:   up to %d workers (adaptive)\n": "Concurrencia:  hasta %d workers (adaptativa)\n",
+	"Concurrency:   %d workers\n":                  "Concurrencia:  %d workers\n",
+	"Content type:  %s (--force-type: untested file types may be rejected by VTEX)": "Tipo:          %s (--force-type: VTEX puede rechazar tipos de archivo no probados)",
+	"=== VTEX File Upload ===":  "=== Subida de Archivo VTEX ===",
+	"=== VTEX Batch Upload ===": "=== Subida por Lotes VTEX ===",
+	"=== Upload Summary ===":    "=== Resumen de la Subida ===",
+	"\n⚠️  WARNING: File already exists and will be OVERWRITTEN!": "\n⚠️  ADVERTENCIA: ¡El archivo ya existe y será SOBRESCRITO!",
+	"Dry run: no files were uploaded.":                            "Simulación: no se subió ningún archivo.",
+	"Proceed with upload?":                                        "¿Continuar con la subida?",
+	"File exists. Overwrite?":                                     "El archivo ya existe. ¿Sobrescribir?",
+	"%d file(s) will be overwritten. Continue?":                   "Se sobrescribirán %d archivo(s). ¿Continuar?",
+	"%s [y/N]: ":                                             "%s [s/N]: ",
+	"Upload cancelled.":                                      "Subida cancelada.",
+	"\n✗ Upload failed: %v\n":                                "\n✗ Falló la subida: %v\n",
+	"Transformed %s: %.2f KB → %.2f KB\n":                    "Transformado %s: %.2f KB → %.2f KB\n",
+	"Skipping %s: the published file is identical":           "Omitiendo %s: el archivo publicado es idéntico",
+	"✓ Upload successful!":                                   "✓ ¡Subida exitosa!",
+	"File URL: %s\n":                                         "URL:      %s\n",
+	"Image:    %s\n":                                         "Imagen:   %s\n",
+	"one or more uploads failed":                             "una o más subidas fallaron",
+	"No image files found in %s":                             "No se encontraron archivos en %s",
+	"%d unchanged file(s) skipped (--incremental)":           "%d archivo(s) sin cambios omitidos (--incremental)",
+	"✓ Everything is up to date.":                            "✓ Todo está actualizado.",
+	"Files to upload:":                                       "Archivos a subir:",
+	"  ... (%d more)\n":                                      "  ... (%d más)\n",
+	"  ... and %d more\n":                                    "  ... y %d más\n",
+	"SKIPPED":                                                "OMITIDOS",
+	"RENAMED":                                                "RENOMBRADOS",
+	"%d file(s) already exist and will be %s:":               "%d archivo(s) ya existen y serán %s:",
+	"Existing files will be checked and %s during upload.\n": "Los archivos existentes se verificarán y serán %s durante la subida.\n",
+	"✗ %d file(s) already exist:":                            "✗ %d archivo(s) ya existen:",
+	"⚠️  WARNING: %d file(s) already exist and will be OVERWRITTEN:": "⚠️  ADVERTENCIA: %d archivo(s) ya existen y serán SOBRESCRITOS:",
+	"[Worker %d] Uploading: %s\n":                                    "[Worker %d] Subiendo: %s\n",
+	"[Worker %d] Skipping existing file: %s\n":                       "[Worker %d] Omitiendo archivo existente: %s\n",
+	"[Worker %d] Skipping identical file: %s\n":                      "[Worker %d] Omitiendo archivo idéntico: %s\n",
+	"[Worker %d] Renaming existing file: %s → %s\n":                  "[Worker %d] Renombrando archivo existente: %s → %s\n",
+	"[Worker %d] Transformed %s: %.2f KB → %.2f KB\n":                "[Worker %d] Transformado %s: %.2f KB → %.2f KB\n",
+	"[Worker %d] ✗ Failed: %v":                                       "[Worker %d] ✗ Falló: %v",
+	"  ✗ Failed: %v":                                                 "  ✗ Falló: %v",
+	"  ✓ Success: %s (%s)":                                           "  ✓ Éxito: %s (%s)",
+	"  ✓ Success: %s":                                                "  ✓ Éxito: %s",
+	"✗ Your VTEX session has expired: %d uploads in a row were rejected (HTTP 401/403).\n": "✗ Tu sesión de VTEX expiró: %d subidas seguidas fueron rechazadas (HTTP 401/403).\n",
+	"  Stopped before the %d remaining file(s). Run 'vtex login', then %s.\n\n":            "  Detenido antes de los %d archivo(s) restantes. Ejecuta 'vtex login' y luego %s.\n\n",
+	"re-run the upload":                               "vuelve a ejecutar la subida",
+	"Total files:     %d\n":                           "Total:           %d\n",
+	"Successful:      %d":                             "Exitosos:        %d",
+	"Skipped:         %d":                             "Omitidos:        %d",
+	"Not attempted:   %d":                             "No intentados:   %d",
+	"Failed:          %d":                             "Fallidos:        %d",
+	"Failed:          %d\n":                           "Fallidos:        %d\n",
+	"Retried:         %d file(s)":                     "Reintentados:    %d archivo(s)",
+	"Transferred:     %s in %s (%s/s)\n":              "Transferido:     %s en %s (%s/s)\n",
+	"Uploaded files:":                                 "Archivos subidos:",
+	"Renamed uploads:":                                "Subidas renombradas:",
+	"Failed uploads:":                                 "Subidas fallidas:",
+	"invalid method: %s (must be 'graphql' or 'cms')": "método inválido: %s (debe ser 'graphql' o 'cms')",
+	"%d file(s) already exist (--on-conflict fail)":   "%d archivo(s) ya existen (--on-conflict fail)",
+	"failed to access file: %w":                       "no se pudo acceder al archivo: %w",
+	"failed to find files: %w":                        "no se pudieron buscar los archivos: %w",
+	"\n⚠️  WARNING: This will permanently delete all upload logs!": "\n⚠️  ATENCIÓN: ¡Esto eliminará permanentemente todos los registros de envío!",
+	"Log file: %s\n":                           "Archivo de registro: %s\n",
+	"Total entries: %d\n\n":                    "Total de entradas: %d\n\n",
+	"Are you sure you want to clear all logs?": "¿Seguro que desea borrar todos los registros?",
+	"Operation cancelled.":                     "Operación cancelada.",
+	"\n✓ Logs cleared successfully!":           "\n✓ ¡Registros borrados correctamente!",
+	"Do you want to update?":                   "¿Desea actualizar?",
+	"Update cancelled":                         "Actualización cancelada",
+	"Upload %d asset(s) and rewrite the references in %d theme file(s)?": "¿Enviar %d archivo(s) y reescribir las referencias en %d archivo(s) del tema?",
+	"%d published file(s) will be overwritten. Continue?":                "Se sobrescribirán %d archivo(s) publicado(s). ¿Continuar?",
+	"Deploy cancelled.":         "Despliegue cancelado.",
+	"authentication failed: %w": "falló la autenticación: %w",
+	"authentication failed: %w. Please run 'vtex login' and try again":                                           "falló la autenticación: %w. Ejecute 'vtex login' e intente de nuevo",
+	"⚠️  Your VTEX session expires in %s. Run 'vtex login' to renew it before long uploads.":                     "⚠️  Su sesión de VTEX expira en %s. Ejecute 'vtex login' para renovarla antes de envíos largos.",
+	"authentication failed: your VTEX session for %s has expired (%v). Please run 'vtex login %s' and try again": "falló la autenticación: su sesión de VTEX de %s expiró (%v). Ejecute 'vtex login %s' e intente de nuevo",
+	"⚠️  Could not verify the VTEX session: %v":                                                                  "⚠️  No se pudo verificar la sesión de VTEX: %v",
+
+	"⚠️  %s already exists and will be replaced by --emit-manifest": "⚠️  %s ya existe y será reemplazado por --emit-manifest",
+	"⚠️  %s already exists and will be replaced by --hash-names":    "⚠️  %s ya existe y será reemplazado por --hash-names",
+
+	"--resume takes no directory or --manifest":                                         "--resume no admite directorio ni --manifest",
+	"specify either a directory argument or --manifest":                                 "indica un directorio o --manifest",
+	"invalid --on-conflict value: %s (must be 'skip', 'overwrite', 'rename' or 'fail')": "valor no válido para --on-conflict: %s (debe ser 'skip', 'overwrite', 'rename' o 'fail')",
+	"reading the file list from stdin requires --yes or --dry-run":                      "leer la lista de archivos de la entrada estándar requiere --yes o --dry-run",
+	"invalid --on-duplicate value: %s (must be 'warn', 'skip' or 'upload')":             "valor no válido para --on-duplicate: %s (debe ser 'warn', 'skip' o 'upload')",
+	"--incremental requires a local directory":                                          "--incremental requiere un directorio local",
+	"failed to hash %s: %w":                                                             "error al calcular el hash de %s: %w",
+	"--file-type requires --method cms":                                                 "--file-type requiere --method cms",
+	"--skip-identical requires --method cms":                                            "--skip-identical requiere --method cms",
+	"--bucket requires --method graphql":                                                "--bucket requiere --method graphql",
+	"--on-conflict %s requires --method cms (GraphQL generates unique file names)":      "--on-conflict %s requiere --method cms (GraphQL genera nombres de archivo únicos)",
+	"--rehearse and --dry-run cannot be used together":                                  "--rehearse y --dry-run no se pueden usar juntos",
+	"--as can only be used when uploading a single file":                                "--as solo se puede usar al subir un único archivo",
+	"failed to create staging directory: %w":                                            "error al crear el directorio temporal: %w",
+	"the interrupted batch uploads to account %s, but the VTEX CLI session is for %s":   "el lote interrumpido sube a la cuenta %s, pero la sesión de VTEX CLI es de %s",
+	"the staged files of the interrupted batch are gone (%s); run the batch again":      "los archivos preparados del lote interrumpido ya no existen (%s); vuelve a ejecutar el lote",
+	"failed to access %s: %w":                                                           "error al acceder a %s: %w",
+	"invalid glob pattern: %s":                                                          "patrón glob no válido: %s",
+	"batch aborted after first failure (--fail-fast): %w":                               "lote cancelado tras el primer error (--fail-fast): %w",
+	"could not resolve a free name for %s: %w":                                          "no se pudo encontrar un nombre libre para %s: %w",
+	"batch aborted after %d consecutive authentication failures: %w":                    "lote cancelado tras %d errores de autenticación seguidos: %w",
+
+	"⚠️  %d group(s) of files have identical content (%s):": "⚠️  %d grupo(s) de archivos tienen contenido idéntico (%s):",
+	"will all be uploaded":                          "se subirán todos",
+	"only the first of each group will be uploaded": "solo se subirá el primero de cada grupo",
+	"Fetching files from %s...\n":                   "Descargando archivos de %s...\n",
+	"Skipping unsupported file: %s":                 "Omitiendo archivo no compatible: %s",
+	"✓ The last batch already finished.":            "✓ El último lote ya terminó.",
+	"=== Resuming VTEX Batch Upload ===":            "=== Reanudando Subida por Lotes VTEX ===",
+	"REHEARSAL: real uploads to account %s, workspace %s. Production is not touched.": "ENSAYO: subidas reales a la cuenta %s, workspace %s. Producción no se modifica.",
+	"Source:        %s\n":             "Origen:        %s\n",
+	"Started:       %s\n":             "Inicio:        %s\n",
+	"Remaining:     %d of %d files\n": "Restantes:     %d de %d archivos\n",
+	"VTEX is throttling or failing requests: reducing concurrency to %d": "VTEX está limitando o rechazando solicitudes: reduciendo la concurrencia a %d",
+}
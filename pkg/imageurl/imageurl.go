@@ -0,0 +1,108 @@
+package imageurl
+
+import (
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strings"
+)
+
+// MaxDimension is the largest width or height the VTEX image server resizes to
+const MaxDimension = 4000
+
+// idsSegment matches the /arquivos/ids/{id} path segment of catalog images,
+// optionally already carrying a -{width}-{height} suffix
+var idsSegment = regexp.MustCompile(`^(\d+)(-\d+-\d+)?$`)
+
+// Options describes an on-the-fly transformation of an uploaded image
+type Options struct {
+	Width  int  // target width in pixels, 0 to derive it from the height
+	Height int  // target height in pixels, 0 to derive it from the width
+	Aspect bool // keep the aspect ratio instead of cropping to Width x Height
+}
+
+// Validate checks the options against what the VTEX image server supports
+func (o Options) Validate() error {
+	if o.Width == 0 && o.Height == 0 {
+		return fmt.Errorf("width or height is required")
+	}
+	if o.Width < 0 || o.Height < 0 {
+		return fmt.Errorf("width and height must be positive")
+	}
+	if o.Width > MaxDimension || o.Height > MaxDimension {
+		return fmt.Errorf("width and height must be at most %d pixels", MaxDimension)
+	}
+	if !o.Aspect && (o.Width == 0 || o.Height == 0) {
+		return fmt.Errorf("cropping requires both width and height")
+	}
+	return nil
+}
+
+// Transform returns the URL of an uploaded VTEX asset resized or cropped on
+// the fly. Catalog URLs (/arquivos/ids/{id}/...) get the {id}-{width}-{height}
+// form; other vtexassets.com URLs get width, height and aspect query parameters.
+func Transform(rawURL string, opts Options) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid URL: %s", rawURL)
+	}
+	if !isVTEXHost(u.Hostname()) {
+		return "", fmt.Errorf("not a VTEX asset URL: %s", rawURL)
+	}
+
+	// Catalog images encode the size in the path
+	segments := strings.Split(u.Path, "/")
+	for i := 0; i+2 < len(segments); i++ {
+		if segments[i] != "arquivos" || segments[i+1] != "ids" {
+			continue
+		}
+		match := idsSegment.FindStringSubmatch(segments[i+2])
+		if match == nil {
+			break
+		}
+		if opts.Width == 0 || opts.Height == 0 {
+			return "", fmt.Errorf("catalog image URLs require both width and height")
+		}
+		segments[i+2] = fmt.Sprintf("%s-%d-%d", match[1], opts.Width, opts.Height)
+		u.Path = strings.Join(segments, "/")
+		u.RawPath = ""
+		return u.String(), nil
+	}
+
+	query := u.Query()
+	query.Del("width")
+	query.Del("height")
+	query.Del("aspect")
+	params := []string{
+		"width=" + dimension(opts.Width),
+		"height=" + dimension(opts.Height),
+		fmt.Sprintf("aspect=%t", opts.Aspect),
+	}
+	if encoded := query.Encode(); encoded != "" {
+		params = append([]string{encoded}, params...)
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.String(), nil
+}
+
+// dimension formats a size parameter, using "auto" for a derived one
+func dimension(pixels int) string {
+	if pixels == 0 {
+		return "auto"
+	}
+	return fmt.Sprint(pixels)
+}
+
+// isVTEXHost reports whether host serves VTEX assets
+func isVTEXHost(host string) bool {
+	for _, suffix := range []string{".vtexassets.com", ".vteximg.com.br", ".myvtex.com"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
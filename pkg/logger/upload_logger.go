@@ -13,16 +13,24 @@ const logFileName = "vtex-files-manager/uploads.jsonl"
 
 // UploadLogEntry represents a single upload operation in the log
 type UploadLogEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	File      string    `json:"file"`
-	Path      string    `json:"path,omitempty"`
-	Size      int64     `json:"size"`
-	Method    string    `json:"method"` // "cms" or "graphql"
-	Account   string    `json:"account"`
-	Workspace string    `json:"workspace"`
-	Status    string    `json:"status"` // "success" or "failed"
-	URL       string    `json:"url,omitempty"`
-	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+	File      string     `json:"file"`
+	Path      string     `json:"path,omitempty"`
+	Size      int64      `json:"size"`
+	Method    string     `json:"method"` // "cms" or "graphql"
+	Account   string     `json:"account"`
+	Workspace string     `json:"workspace"`
+	Status    string     `json:"status"` // "success" or "failed"
+	URL       string     `json:"url,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Image     *ImageMeta `json:"image,omitempty"`
+}
+
+// ImageMeta holds image dimensions recorded for an upload
+type ImageMeta struct {
+	Width    int  `json:"width"`
+	Height   int  `json:"height"`
+	Animated bool `json:"animated,omitempty"`
 }
 
 // LogUpload appends an upload entry to the log file
@@ -0,0 +1,148 @@
+// Package metrics counts uploads made by long-running vfm commands and exposes
+// them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the upload duration histogram
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Registry holds upload counters and durations per upload method
+type Registry struct {
+	mu        sync.Mutex
+	uploads   map[uploadKey]uint64
+	bytes     map[string]uint64
+	durations map[string]*histogram
+}
+
+type uploadKey struct {
+	method string
+	result string // success or failure
+}
+
+type histogram struct {
+	buckets []uint64 // cumulative counts per durationBuckets entry
+	count   uint64
+	sum     float64
+}
+
+// Default is shared by all commands of this process
+var Default = NewRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		uploads:   map[uploadKey]uint64{},
+		bytes:     map[string]uint64{},
+		durations: map[string]*histogram{},
+	}
+}
+
+// RecordUpload registers an upload of size bytes made with the given method.
+// Bytes are only counted for successful uploads.
+func (r *Registry) RecordUpload(method string, size int64, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	} else if size > 0 {
+		r.bytes[method] += uint64(size)
+	}
+	r.uploads[uploadKey{method, result}]++
+
+	h, ok := r.durations[method]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(durationBuckets))}
+		r.durations[method] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Handler serves the metrics in the Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	r.mu.Lock()
+	b.WriteString("# HELP vfm_uploads_total Uploads completed, by upload method and result.\n")
+	b.WriteString("# TYPE vfm_uploads_total counter\n")
+	keys := make([]uploadKey, 0, len(r.uploads))
+	for key := range r.uploads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].result < keys[j].result
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "vfm_uploads_total{method=%q,result=%q} %d\n", key.method, key.result, r.uploads[key])
+	}
+
+	b.WriteString("# HELP vfm_uploaded_bytes_total Bytes of successfully uploaded files, by upload method.\n")
+	b.WriteString("# TYPE vfm_uploaded_bytes_total counter\n")
+	for _, method := range sortedKeys(r.bytes) {
+		fmt.Fprintf(&b, "vfm_uploaded_bytes_total{method=%q} %d\n", method, r.bytes[method])
+	}
+
+	b.WriteString("# HELP vfm_upload_duration_seconds Time taken by uploads, retries included, by upload method.\n")
+	b.WriteString("# TYPE vfm_upload_duration_seconds histogram\n")
+	for _, method := range sortedKeys(r.durations) {
+		h := r.durations[method]
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&b, "vfm_upload_duration_seconds_bucket{method=%q,le=\"%g\"} %d\n", method, bound, h.buckets[i])
+		}
+		fmt.Fprintf(&b, "vfm_upload_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", method, h.count)
+		fmt.Fprintf(&b, "vfm_upload_duration_seconds_sum{method=%q} %g\n", method, h.sum)
+		fmt.Fprintf(&b, "vfm_upload_duration_seconds_count{method=%q} %d\n", method, h.count)
+	}
+	r.mu.Unlock()
+
+	requests, congested := client.DefaultHealthMonitor.Snapshot()
+	b.WriteString("# HELP vfm_vtex_requests_total HTTP requests sent to VTEX, retries included.\n")
+	b.WriteString("# TYPE vfm_vtex_requests_total counter\n")
+	fmt.Fprintf(&b, "vfm_vtex_requests_total %d\n", requests)
+	b.WriteString("# HELP vfm_vtex_congested_requests_total Requests answered with HTTP 429 or 5xx, failed or slower than 30s.\n")
+	b.WriteString("# TYPE vfm_vtex_congested_requests_total counter\n")
+	fmt.Fprintf(&b, "vfm_vtex_congested_requests_total %d\n", congested)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
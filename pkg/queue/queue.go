@@ -0,0 +1,192 @@
+// Package queue stores files waiting to be uploaded by 'vfm daemon' in a
+// durable on-disk queue, one JSON file per job, so pending uploads survive
+// crashes and restarts.
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// dirName is the queue directory inside the XDG state directory
+const dirName = "vtex-files-manager/queue"
+
+// lockFileName is the file locked by the worker draining the queue
+const lockFileName = ".daemon.lock"
+
+// corruptSuffix is appended to the name of job files that cannot be parsed
+const corruptSuffix = ".corrupt"
+
+// ErrLocked is returned by Lock when another process drains the queue
+var ErrLocked = errors.New("the upload queue is already being drained by another vfm daemon")
+
+// Job is a file waiting to be uploaded
+type Job struct {
+	ID         string    `json:"id"`
+	Path       string    `json:"path"`             // absolute path of the local file
+	Name       string    `json:"name"`             // remote file name
+	Method     string    `json:"method,omitempty"` // empty uses the account default
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+
+	// Account and Workspace are the destination of the upload, from the
+	// session the file was enqueued with; jobs queued by older versions have
+	// none and use the current session
+	Account   string `json:"account,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+
+	Attempts      int       `json:"attempts,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitempty"`
+	LastError     string    `json:"lastError,omitempty"`
+
+	// Failed is set when the worker gave up on the job; it is kept until
+	// retried or cleared
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Due reports whether a pending job can be attempted at now
+func (j Job) Due(now time.Time) bool {
+	return !j.Failed && !now.Before(j.NextAttemptAt)
+}
+
+// Queue is a directory of job files
+type Queue struct {
+	dir string
+}
+
+// Open returns the queue in the XDG state directory, creating it if needed
+func Open() (*Queue, error) {
+	dir := filepath.Join(xdg.StateHome, dirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return &Queue{dir: dir}, nil
+}
+
+// Dir returns the directory job files are stored in
+func (q *Queue) Dir() string {
+	return q.dir
+}
+
+// Add stores a new job and returns it with its ID set. IDs sort in enqueue
+// order, so jobs are attempted first in, first out.
+func (q *Queue) Add(job Job) (Job, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return job, err
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now()
+	}
+	job.ID = fmt.Sprintf("%019d-%s", job.EnqueuedAt.UnixNano(), hex.EncodeToString(suffix))
+	return job, q.Update(job)
+}
+
+// Update saves job, replacing its previous state atomically
+func (q *Queue) Update(job Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated job
+	tmp, err := os.CreateTemp(q.dir, ".job-*")
+	if err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), q.path(job.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a job, typically once it was uploaded
+func (q *Queue) Remove(id string) error {
+	if err := os.Remove(q.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove job: %w", err)
+	}
+	return nil
+}
+
+// Lock takes the lock of the queue for the worker draining it and returns a
+// function releasing it, or ErrLocked when another process holds it. The
+// operating system releases the lock when the process exits, even on a crash.
+func (q *Queue) Lock() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(q.dir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, ErrLocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock queue: %w", err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// List returns every job in enqueue order. Job files that cannot be parsed
+// are renamed with a .corrupt suffix and left out, so one bad file does not
+// stop the queue.
+func (q *Queue) List() ([]Job, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	var jobs []Job
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // removed by the worker meanwhile
+		}
+		if err != nil {
+			slog.Warn("could not read queued job", "file", name, "error", err)
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			path := filepath.Join(q.dir, name)
+			if renameErr := os.Rename(path, path+corruptSuffix); renameErr != nil {
+				slog.Warn("could not quarantine corrupt job", "file", path, "error", renameErr)
+			} else {
+				slog.Warn("quarantined corrupt job", "file", path+corruptSuffix, "error", err)
+			}
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID < jobs[k].ID })
+	return jobs, nil
+}
+
+// path returns the file a job is stored in
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
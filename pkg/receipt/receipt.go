@@ -0,0 +1,204 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	keyFileName = "vtex-files-manager/receipt.key"
+
+	// Version is the current receipt format version
+	Version = 1
+)
+
+// ErrInvalidSignature is returned when a receipt was modified after signing or
+// signed by a different key than expected
+var ErrInvalidSignature = errors.New("invalid receipt signature")
+
+// File is a single uploaded file recorded in a receipt
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url"`
+}
+
+// Receipt is a signed record of the files published in one vfm run
+type Receipt struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Account   string    `json:"account"`
+	Workspace string    `json:"workspace"`
+	Operator  string    `json:"operator"`
+	Files     []File    `json:"files"`
+	PublicKey string    `json:"publicKey,omitempty"` // base64 ed25519 public key
+	Signature string    `json:"signature,omitempty"` // base64 ed25519 signature of the unsigned receipt
+}
+
+// New creates an empty receipt for the given account and operator
+func New(account, workspace, operator string) *Receipt {
+	return &Receipt{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Account:   account,
+		Workspace: workspace,
+		Operator:  operator,
+		Files:     []File{},
+	}
+}
+
+// AddFile hashes a local file and records it under its remote name and URL
+func (r *Receipt) AddFile(localPath, name, url string) error {
+	hash, size, err := HashFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	r.Files = append(r.Files, File{Name: name, Size: size, SHA256: hash, URL: url})
+	return nil
+}
+
+// HashFile returns the hex SHA-256 digest and size of a local file
+func HashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// payload returns the bytes covered by the signature
+func (r *Receipt) payload() ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Sign signs the receipt with the given private key, embedding its public key
+func (r *Receipt) Sign(key ed25519.PrivateKey) error {
+	r.PublicKey = PublicKey(key)
+
+	payload, err := r.payload()
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+
+	r.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// Verify checks the receipt signature. When expectedKey is not empty, the
+// receipt must also have been signed by that base64 public key.
+func (r *Receipt) Verify(expectedKey string) error {
+	if r.Signature == "" || r.PublicKey == "" {
+		return fmt.Errorf("%w: receipt is not signed", ErrInvalidSignature)
+	}
+	if expectedKey != "" && strings.TrimSpace(expectedKey) != r.PublicKey {
+		return fmt.Errorf("%w: signed by a different key", ErrInvalidSignature)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(r.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed public key", ErrInvalidSignature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	payload, err := r.payload()
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return fmt.Errorf("%w: receipt content does not match signature", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// Write saves the receipt as indented JSON
+func (r *Receipt) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return nil
+}
+
+// Load reads a receipt from a JSON file
+func Load(path string) (*Receipt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt: %w", err)
+	}
+
+	var r Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt: %w", err)
+	}
+	return &r, nil
+}
+
+// LoadOrCreateKey returns the local signing key, generating it on first use
+func LoadOrCreateKey() (ed25519.PrivateKey, error) {
+	if path, err := xdg.SearchConfigFile(keyFileName); err == nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("signing key %s is malformed", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+
+	path, err := xdg.ConfigFile(keyFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve signing key path: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	// Only the owner may read the private key
+	encoded := base64.StdEncoding.EncodeToString(key.Seed())
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to save signing key: %w", err)
+	}
+
+	return key, nil
+}
+
+// PublicKey returns the base64 public key of a signing key, for sharing with counterparts
+func PublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
@@ -0,0 +1,118 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+)
+
+// AzureSource reads blobs from an Azure Blob Storage container, under a name
+// prefix or a single blob name
+type AzureSource struct {
+	account   string
+	container string
+	prefix    string
+	client    *azblob.Client
+}
+
+func isAzureURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "az://")
+}
+
+// newAzureSource creates a source for an az://container/prefix URL in the
+// configured storage account (or AZURE_STORAGE_ACCOUNT). Credentials come from
+// the Azure SDK default chain (service principal environment variables,
+// workload and managed identities, Azure CLI and Azure Developer CLI logins).
+func newAzureSource(rawURL string, cfg config.AzureConfig) (*AzureSource, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid Azure Blob Storage URL: %s (expected az://container/prefix)", rawURL)
+	}
+
+	account := cfg.Account
+	if account == "" {
+		account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	if account == "" {
+		return nil, fmt.Errorf("Azure storage account not configured (set sources.azure.account in the vfm config or AZURE_STORAGE_ACCOUNT)")
+	}
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w", err)
+	}
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	client, err := azblob.NewClient(serviceURL, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob Storage client: %w", err)
+	}
+	return &AzureSource{account: account, container: u.Host, prefix: strings.TrimPrefix(u.Path, "/"), client: client}, nil
+}
+
+// Name returns a human-readable name for the source
+func (s *AzureSource) Name() string {
+	return "Azure Blob Storage (" + s.account + ": az://" + path.Join(s.container, s.prefix) + ")"
+}
+
+// List returns the blobs under the prefix, with paths relative to it. A
+// prefix naming a single blob lists just that blob.
+func (s *AzureSource) List(ctx context.Context) ([]File, error) {
+	var files []File
+	pager := s.client.NewListBlobsFlatPager(s.container, &azblob.ListBlobsFlatOptions{Prefix: &s.prefix})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, describeAzureError(err)
+		}
+		for _, blob := range page.Segment.BlobItems {
+			if blob.Name == nil {
+				continue
+			}
+			relPath, ok := objectRelPath(s.prefix, *blob.Name)
+			if !ok {
+				continue
+			}
+			var size int64
+			if blob.Properties != nil && blob.Properties.ContentLength != nil {
+				size = *blob.Properties.ContentLength
+			}
+			files = append(files, File{ID: *blob.Name, RelPath: relPath, Size: size})
+		}
+	}
+	return files, nil
+}
+
+// Open streams the content of a blob
+func (s *AzureSource) Open(ctx context.Context, f File) (io.ReadCloser, error) {
+	resp, err := s.client.DownloadStream(ctx, s.container, f.ID, nil)
+	if err != nil {
+		return nil, describeAzureError(err)
+	}
+	return resp.Body, nil
+}
+
+// describeAzureError points authorization failures to the Azure credentials
+func describeAzureError(err error) error {
+	var credErr *azidentity.AuthenticationFailedError
+	var respErr *azcore.ResponseError
+	switch {
+	case errors.As(err, &credErr),
+		errors.As(err, &respErr) && (respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden):
+		return fmt.Errorf("Azure Blob Storage authentication failed: check your Azure credentials (az login, AZURE_CLIENT_ID, managed identity) and that they have the Storage Blob Data Reader role: %w", err)
+	case bloberror.HasCode(err, bloberror.ContainerNotFound):
+		return fmt.Errorf("Azure Blob Storage container not found: %w", err)
+	}
+	return fmt.Errorf("Azure Blob Storage request failed: %w", err)
+}
@@ -0,0 +1,172 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"strings"
+)
+
+const (
+	dropboxAPI     = "https://api.dropboxapi.com/2"
+	dropboxContent = "https://content.dropboxapi.com/2"
+)
+
+// dropboxEntry represents file or folder metadata from the Dropbox API
+type dropboxEntry struct {
+	Tag  string `json:".tag"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// DropboxSource reads files from a Dropbox shared file or folder link
+type DropboxSource struct {
+	link        string
+	accessToken string
+	httpClient  *http.Client
+}
+
+func isDropboxURL(rawURL string) bool {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == "www.dropbox.com" || u.Host == "dropbox.com"
+}
+
+func newDropboxSource(rawURL, accessToken string) (*DropboxSource, error) {
+	if accessToken == "" {
+		return nil, fmt.Errorf("Dropbox access token not configured (set sources.dropbox.accessToken in the vfm config or VFM_DROPBOX_TOKEN)")
+	}
+
+	return &DropboxSource{
+		link:        rawURL,
+		accessToken: accessToken,
+		httpClient:  newHTTPClient(),
+	}, nil
+}
+
+// Name returns a human-readable name for the source
+func (s *DropboxSource) Name() string {
+	return "Dropbox"
+}
+
+// List returns all files behind the shared link, recursing into subfolders
+func (s *DropboxSource) List(ctx context.Context) ([]File, error) {
+	var root dropboxEntry
+	if err := s.rpc(ctx, "/sharing/get_shared_link_metadata", map[string]interface{}{"url": s.link}, &root); err != nil {
+		return nil, err
+	}
+
+	if root.Tag == "file" {
+		// The file is addressed by the link itself, so no path is needed
+		return []File{{ID: "", RelPath: root.Name, Size: root.Size}}, nil
+	}
+	return s.listFolder(ctx, "")
+}
+
+// listFolder lists a folder inside the shared link with paths relative to its root
+func (s *DropboxSource) listFolder(ctx context.Context, folder string) ([]File, error) {
+	var files []File
+
+	var page struct {
+		Entries []dropboxEntry `json:"entries"`
+		Cursor  string         `json:"cursor"`
+		HasMore bool           `json:"has_more"`
+	}
+	args := map[string]interface{}{
+		"path":        folder,
+		"shared_link": map[string]string{"url": s.link},
+	}
+	if err := s.rpc(ctx, "/files/list_folder", args, &page); err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, e := range page.Entries {
+			relPath := strings.TrimPrefix(path.Join(folder, e.Name), "/")
+			switch e.Tag {
+			case "folder":
+				children, err := s.listFolder(ctx, "/"+relPath)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, children...)
+			case "file":
+				files = append(files, File{ID: "/" + relPath, RelPath: relPath, Size: e.Size})
+			}
+		}
+
+		if !page.HasMore {
+			return files, nil
+		}
+		cursor := page.Cursor
+		page.Entries = nil
+		if err := s.rpc(ctx, "/files/list_folder/continue", map[string]string{"cursor": cursor}, &page); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Open streams the contents of a file inside the shared link
+func (s *DropboxSource) Open(ctx context.Context, f File) (io.ReadCloser, error) {
+	args := map[string]string{"url": s.link}
+	if f.ID != "" {
+		args["path"] = f.ID
+	}
+	argJSON, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", dropboxContent+"/sharing/get_shared_link_file", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.accessToken)
+	req.Header.Set("Dropbox-API-Arg", string(argJSON))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if err := checkResponse(resp, "Dropbox"); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// rpc performs a Dropbox RPC-style request and decodes the JSON response
+func (s *DropboxSource) rpc(ctx context.Context, endpoint string, args interface{}, out interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", dropboxAPI+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "Dropbox"); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Dropbox response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCSSource reads objects from a Google Cloud Storage bucket, under a name
+// prefix or a single object name
+type GCSSource struct {
+	bucket string
+	prefix string
+	client *storage.Client
+}
+
+func isGCSURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "gs://")
+}
+
+// newGCSSource creates a source for a gs://bucket/prefix URL. Credentials come
+// from Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud
+// auth application-default login, the metadata server) unless a service
+// account key file is configured.
+func newGCSSource(ctx context.Context, rawURL string, cfg config.GCSConfig) (*GCSSource, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid Cloud Storage URL: %s (expected gs://bucket/prefix)", rawURL)
+	}
+
+	var options []option.ClientOption
+	if cfg.CredentialsFile != "" {
+		options = append(options, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+	client, err := storage.NewClient(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client (check GOOGLE_APPLICATION_CREDENTIALS or run 'gcloud auth application-default login'): %w", err)
+	}
+	return &GCSSource{bucket: u.Host, prefix: strings.TrimPrefix(u.Path, "/"), client: client}, nil
+}
+
+// Name returns a human-readable name for the source
+func (s *GCSSource) Name() string {
+	return "Cloud Storage (gs://" + path.Join(s.bucket, s.prefix) + ")"
+}
+
+// List returns the objects under the prefix, with paths relative to it. A
+// prefix naming a single object lists just that object.
+func (s *GCSSource) List(ctx context.Context) ([]File, error) {
+	var files []File
+	it := s.client.Bucket(s.bucket).Objects(ctx, &storage.Query{Prefix: s.prefix})
+	for {
+		object, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, describeGCSError(err)
+		}
+
+		relPath, ok := objectRelPath(s.prefix, object.Name)
+		if !ok {
+			continue
+		}
+		files = append(files, File{ID: object.Name, RelPath: relPath, Size: object.Size})
+	}
+	return files, nil
+}
+
+// Open streams the content of an object
+func (s *GCSSource) Open(ctx context.Context, f File) (io.ReadCloser, error) {
+	// Objects stored with Content-Encoding: gzip (gsutil cp -z) are
+	// decompressed, so the original bytes are uploaded
+	reader, err := s.client.Bucket(s.bucket).Object(f.ID).NewReader(ctx)
+	if err != nil {
+		return nil, describeGCSError(err)
+	}
+	return reader, nil
+}
+
+// Close releases the connections of the Cloud Storage client
+func (s *GCSSource) Close() error {
+	return s.client.Close()
+}
+
+// describeGCSError points authorization failures to the Google Cloud credentials
+func describeGCSError(err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && (apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden) {
+		return fmt.Errorf("Cloud Storage authentication failed (status %d): check your Google Cloud credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud auth application-default login): %w", apiErr.Code, err)
+	}
+	if errors.Is(err, storage.ErrBucketNotExist) {
+		return fmt.Errorf("Cloud Storage bucket not found: %w", err)
+	}
+	return fmt.Errorf("Cloud Storage request failed: %w", err)
+}
@@ -0,0 +1,188 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	googleDriveAPI         = "https://www.googleapis.com/drive/v3/files"
+	googleFolderMIME       = "application/vnd.google-apps.folder"
+	googleNativeMIMEPrefix = "application/vnd.google-apps."
+)
+
+var (
+	// Matches /file/d/{id}/... and /drive/folders/{id} style links
+	googleDrivePathID = regexp.MustCompile(`/(?:file/d|drive/(?:u/\d+/)?folders)/([A-Za-z0-9_-]+)`)
+)
+
+// googleDriveFile represents a file resource from the Drive v3 API
+type googleDriveFile struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+	Size     string `json:"size"`
+}
+
+// GoogleDriveSource reads files from a Google Drive shared file or folder link
+type GoogleDriveSource struct {
+	id         string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func isGoogleDriveURL(rawURL string) bool {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == "drive.google.com"
+}
+
+func newGoogleDriveSource(rawURL, apiKey string) (*GoogleDriveSource, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("Google Drive API key not configured (set sources.googleDrive.apiKey in the vfm config or VFM_GDRIVE_API_KEY)")
+	}
+
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Google Drive URL: %w", err)
+	}
+
+	// Links look like /file/d/{id}/view, /drive/folders/{id} or /open?id={id}
+	id := u.Query().Get("id")
+	if matches := googleDrivePathID.FindStringSubmatch(u.Path); len(matches) == 2 {
+		id = matches[1]
+	}
+	if id == "" {
+		return nil, fmt.Errorf("could not find a file or folder ID in Google Drive URL: %s", rawURL)
+	}
+
+	return &GoogleDriveSource{
+		id:         id,
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// Name returns a human-readable name for the source
+func (s *GoogleDriveSource) Name() string {
+	return "Google Drive"
+}
+
+// List returns all files behind the shared link, recursing into subfolders
+func (s *GoogleDriveSource) List(ctx context.Context) ([]File, error) {
+	var root googleDriveFile
+	params := neturl.Values{"fields": {"id,name,mimeType,size"}}
+	if err := s.getJSON(ctx, googleDriveAPI+"/"+neturl.PathEscape(s.id), params, &root); err != nil {
+		return nil, err
+	}
+
+	if root.MimeType != googleFolderMIME {
+		return []File{s.toFile(root, root.Name)}, nil
+	}
+	return s.listFolder(ctx, root.ID, "")
+}
+
+// listFolder lists a folder's files with paths relative to the shared root
+func (s *GoogleDriveSource) listFolder(ctx context.Context, folderID, prefix string) ([]File, error) {
+	var files []File
+	pageToken := ""
+
+	for {
+		params := neturl.Values{
+			"q":                         {fmt.Sprintf("'%s' in parents and trashed = false", folderID)},
+			"fields":                    {"nextPageToken,files(id,name,mimeType,size)"},
+			"pageSize":                  {"1000"},
+			"includeItemsFromAllDrives": {"true"},
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			NextPageToken string            `json:"nextPageToken"`
+			Files         []googleDriveFile `json:"files"`
+		}
+		if err := s.getJSON(ctx, googleDriveAPI, params, &page); err != nil {
+			return nil, err
+		}
+
+		for _, f := range page.Files {
+			relPath := path.Join(prefix, f.Name)
+			switch {
+			case f.MimeType == googleFolderMIME:
+				children, err := s.listFolder(ctx, f.ID, relPath)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, children...)
+			case strings.HasPrefix(f.MimeType, googleNativeMIMEPrefix):
+				// Native Docs/Sheets/Slides cannot be downloaded as-is
+				continue
+			default:
+				files = append(files, s.toFile(f, relPath))
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Open streams the contents of a Drive file
+func (s *GoogleDriveSource) Open(ctx context.Context, f File) (io.ReadCloser, error) {
+	params := neturl.Values{"alt": {"media"}, "key": {s.apiKey}}
+	req, err := http.NewRequestWithContext(ctx, "GET", googleDriveAPI+"/"+neturl.PathEscape(f.ID)+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if err := checkResponse(resp, "Google Drive"); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *GoogleDriveSource) toFile(f googleDriveFile, relPath string) File {
+	size, _ := strconv.ParseInt(f.Size, 10, 64)
+	return File{ID: f.ID, RelPath: relPath, Size: size}
+}
+
+// getJSON performs an authenticated GET against the Drive API and decodes the response
+func (s *GoogleDriveSource) getJSON(ctx context.Context, endpoint string, params neturl.Values, out interface{}) error {
+	params.Set("key", s.apiKey)
+	params.Set("supportsAllDrives", "true")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "Google Drive"); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Google Drive response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+)
+
+// S3Source reads objects from an S3 bucket, under a key prefix or a single key
+type S3Source struct {
+	bucket string
+	prefix string
+	client *s3.Client
+}
+
+func isS3URL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "s3://")
+}
+
+// newS3Source creates a source for an s3://bucket/prefix URL. Credentials come
+// from the AWS SDK default chain (environment, shared config and credentials
+// files, SSO, container and instance roles). Without a configured region, the
+// region of the bucket is looked up.
+func newS3Source(ctx context.Context, rawURL string, cfg config.S3Config) (*S3Source, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 URL: %s (expected s3://bucket/prefix)", rawURL)
+	}
+	bucket, prefix := u.Host, strings.TrimPrefix(u.Path, "/")
+
+	var options []func(*awsconfig.LoadOptions) error
+	if cfg.Profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(cfg.Profile))
+	}
+	if cfg.Region != "" {
+		options = append(options, awsconfig.WithRegion(cfg.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	if awsCfg.Region == "" {
+		awsCfg.Region = "us-east-1"
+		region, err := manager.GetBucketRegion(ctx, s3.NewFromConfig(awsCfg), bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the region of S3 bucket %s (set AWS_REGION or sources.s3.region in the vfm config): %w", bucket, err)
+		}
+		awsCfg.Region = region
+	}
+
+	// Objects uploaded without checksums are common in older libraries; don't
+	// warn about each of them
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.DisableLogOutputChecksumValidationSkipped = true
+	})
+	return &S3Source{bucket: bucket, prefix: prefix, client: client}, nil
+}
+
+// Name returns a human-readable name for the source
+func (s *S3Source) Name() string {
+	return "S3 (s3://" + path.Join(s.bucket, s.prefix) + ")"
+}
+
+// List returns the objects under the prefix, with paths relative to it. A
+// prefix naming a single object lists just that object.
+func (s *S3Source) List(ctx context.Context) ([]File, error) {
+	var files []File
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, describeS3Error(err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			relPath, ok := objectRelPath(s.prefix, key)
+			if !ok {
+				continue
+			}
+			files = append(files, File{ID: key, RelPath: relPath, Size: aws.ToInt64(object.Size)})
+		}
+	}
+	return files, nil
+}
+
+// Open streams the content of an object
+func (s *S3Source) Open(ctx context.Context, f File) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(f.ID),
+	})
+	if err != nil {
+		return nil, describeS3Error(err)
+	}
+	return out.Body, nil
+}
+
+// describeS3Error points authorization failures to the AWS credentials
+func describeS3Error(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken":
+			return fmt.Errorf("S3 authentication failed (%s): check your AWS credentials (AWS_PROFILE, AWS_ACCESS_KEY_ID, ...): %w", apiErr.ErrorCode(), err)
+		}
+	}
+	return fmt.Errorf("S3 request failed: %w", err)
+}
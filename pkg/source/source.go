@@ -0,0 +1,166 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/config"
+)
+
+// File represents a file available from a remote source
+type File struct {
+	ID      string // source-specific identifier used to download the file
+	RelPath string // path relative to the shared folder (or file name)
+	Size    int64
+}
+
+// Source enumerates and streams files from a remote location such as a shared link
+type Source interface {
+	// Name returns a human-readable name for the source
+	Name() string
+	// List returns all files available from the source
+	List(ctx context.Context) ([]File, error)
+	// Open streams the contents of a file returned by List
+	Open(ctx context.Context, f File) (io.ReadCloser, error)
+}
+
+// newHTTPClient returns the HTTP client used by source adapters
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+}
+
+// IsRemote reports whether the argument refers to a remote source rather than a local path
+func IsRemote(arg string) bool {
+	return strings.HasPrefix(arg, "https://") || strings.HasPrefix(arg, "http://") || isS3URL(arg) || isGCSURL(arg) || isAzureURL(arg)
+}
+
+// Resolve returns the source adapter able to read the given URL. Cancelling
+// ctx aborts the setup some adapters do, such as the S3 bucket region lookup.
+func Resolve(ctx context.Context, rawURL string, cfg *config.Config) (Source, error) {
+	switch {
+	case isGoogleDriveURL(rawURL):
+		return newGoogleDriveSource(rawURL, cfg.Sources.GoogleDrive.APIKey)
+	case isDropboxURL(rawURL):
+		return newDropboxSource(rawURL, cfg.Sources.Dropbox.AccessToken)
+	case isS3URL(rawURL):
+		return newS3Source(ctx, rawURL, cfg.Sources.S3)
+	case isGCSURL(rawURL):
+		return newGCSSource(ctx, rawURL, cfg.Sources.GCS)
+	case isAzureURL(rawURL):
+		return newAzureSource(rawURL, cfg.Sources.Azure)
+	default:
+		return nil, fmt.Errorf("unsupported source URL: %s (supported: Google Drive and Dropbox shared links, s3://, gs:// and az:// URLs)", rawURL)
+	}
+}
+
+// objectRelPath returns the path of a bucket object relative to a key prefix,
+// and false when the object is not under it or is a folder placeholder. The
+// prefix is a folder ("images" covers images/..., not images2/...) or the name
+// of a single object, which keeps its base name.
+func objectRelPath(prefix, name string) (string, bool) {
+	dir := prefix
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	switch {
+	case strings.HasSuffix(name, "/"):
+		return "", false
+	case name == prefix:
+		return path.Base(name), true
+	case strings.HasPrefix(name, dir):
+		return strings.TrimPrefix(name, dir), true
+	}
+	return "", false
+}
+
+// Download streams every file accepted by filter into dir, preserving relative
+// paths, and returns the local paths of the downloaded files. Files whose
+// relative path would escape dir (absolute, or with ".." elements) are skipped,
+// as remote names are not trusted. So are files named like a folder of other
+// files (an S3 object "a/b" next to "a/b/c"), which cannot both be written.
+// Cancelling ctx stops the download.
+func Download(ctx context.Context, src Source, dir string, filter func(relPath string) bool) ([]string, error) {
+	files, err := src.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s files: %w", src.Name(), err)
+	}
+
+	var wanted []File
+	folders := map[string]bool{}
+	for _, f := range files {
+		if !filepath.IsLocal(filepath.FromSlash(f.RelPath)) {
+			slog.Warn("skipping remote file with an unsafe path", "source", src.Name(), "path", f.RelPath)
+			continue
+		}
+		if filter != nil && !filter(f.RelPath) {
+			continue
+		}
+		wanted = append(wanted, f)
+		for folder := path.Dir(path.Clean(f.RelPath)); folder != "."; folder = path.Dir(folder) {
+			folders[folder] = true
+		}
+	}
+
+	var paths []string
+	for _, f := range wanted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if folders[path.Clean(f.RelPath)] {
+			slog.Warn("skipping remote file named like a folder of other files", "source", src.Name(), "path", f.RelPath)
+			continue
+		}
+
+		localPath := filepath.Join(dir, filepath.FromSlash(f.RelPath))
+		if err := downloadFile(ctx, src, f, localPath); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", f.RelPath, err)
+		}
+		paths = append(paths, localPath)
+	}
+
+	return paths, nil
+}
+
+// downloadFile copies a single remote file to localPath
+func downloadFile(ctx context.Context, src Source, f File, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+
+	reader, err := src.Open(ctx, f)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	out, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, reader)
+	return err
+}
+
+// checkResponse returns an error describing a non-2xx response
+func checkResponse(resp *http.Response, service string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return fmt.Errorf("%s authentication failed (HTTP %d): check the credentials in your vfm config: %s", service, resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("%s request failed with status %d: %s", service, resp.StatusCode, string(body))
+}
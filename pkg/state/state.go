@@ -0,0 +1,124 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/receipt"
+)
+
+// FileName is the name of the state file kept in each uploaded directory
+const FileName = ".vfm-state.json"
+
+// Entry records a previously uploaded file
+type Entry struct {
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"modTime"`
+	SHA256     string    `json:"sha256"`
+	Account    string    `json:"account"`
+	Method     string    `json:"method"`
+	RemoteName string    `json:"remoteName"`
+	URL        string    `json:"url,omitempty"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// State is the upload state of a directory, keyed by slash-separated path
+// relative to the directory
+type State struct {
+	path  string
+	Files map[string]Entry `json:"files"`
+}
+
+// Load reads the state file of a directory. A missing file yields an empty state.
+func Load(dir string) (*State, error) {
+	s := &State{
+		path:  filepath.Join(dir, FileName),
+		Files: map[string]Entry{},
+	}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	if s.Files == nil {
+		s.Files = map[string]Entry{}
+	}
+	return s, nil
+}
+
+// Path returns the location of the state file
+func (s *State) Path() string {
+	return s.path
+}
+
+// Unchanged reports whether a file was already uploaded to the same account and
+// remote name with the same content. Size and modification time are compared
+// first; the content hash is only computed when the modification time differs.
+func (s *State) Unchanged(relPath, localPath, account, method, remoteName string) (bool, error) {
+	entry, ok := s.Files[filepath.ToSlash(relPath)]
+	if !ok || entry.Account != account || entry.Method != method || entry.RemoteName != remoteName {
+		return false, nil
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != entry.Size {
+		return false, nil
+	}
+	if info.ModTime().Equal(entry.ModTime) {
+		return true, nil
+	}
+
+	hash, _, err := receipt.HashFile(localPath)
+	if err != nil {
+		return false, err
+	}
+	return hash == entry.SHA256, nil
+}
+
+// Record stores a successful upload
+func (s *State) Record(relPath, localPath, account, method, remoteName, url string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	hash, _, err := receipt.HashFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	s.Files[filepath.ToSlash(relPath)] = Entry{
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		SHA256:     hash,
+		Account:    account,
+		Method:     method,
+		RemoteName: remoteName,
+		URL:        url,
+		UploadedAt: time.Now(),
+	}
+	return nil
+}
+
+// Save writes the state file
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+// Package vfm uploads files to VTEX from Go programs, without the vfm command
+// line or any terminal output.
+//
+//	session, err := vfm.LoadSession()
+//	if err != nil {
+//		return err
+//	}
+//	c, err := vfm.New(session, vfm.Options{Method: vfm.MethodCMS})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := c.Upload(ctx, "banner.png")
+//
+// Uploads are recorded in the same log as the vfm command, and are retried on
+// throttling and server errors like the command does.
+package vfm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/glinharesb/vtex-files-manager/pkg/auth"
+	"github.com/glinharesb/vtex-files-manager/pkg/client"
+	"github.com/glinharesb/vtex-files-manager/pkg/logger"
+	"github.com/glinharesb/vtex-files-manager/pkg/vtexcli"
+)
+
+// Method selects the VTEX API files are uploaded with
+type Method string
+
+const (
+	// MethodGraphQL uploads images through the GraphQL API (URLs are generated by VTEX)
+	MethodGraphQL Method = "graphql"
+	// MethodCMS uploads through the CMS FilePicker, keeping file names in /arquivos URLs
+	MethodCMS Method = "cms"
+)
+
+// DefaultConcurrency is the number of parallel uploads used by Batch when
+// Options.Concurrency is not set
+const DefaultConcurrency = 3
+
+// ErrUnsupported is returned by operations the selected method cannot perform
+var ErrUnsupported = errors.New("not supported by this upload method")
+
+// ErrAuthFailed is wrapped by errors caused by an expired or invalid token
+var ErrAuthFailed = client.ErrAuthFailed
+
+// ErrVerifyFailed is wrapped by errors of uploads checked with
+// Options.VerifyUploads whose published file differs from the local file
+var ErrVerifyFailed = client.ErrVerifyFailed
+
+// Session identifies the VTEX account, workspace and token uploads are made with
+type Session struct {
+	Account   string
+	Workspace string
+	Token     string
+
+	// AppKey and AppToken authenticate instead of Token when set
+	AppKey   string
+	AppToken string
+}
+
+// Options configure a Client; zero values select the defaults
+type Options struct {
+	Method      Method // defaults to MethodGraphQL
+	Bucket      string // GraphQL bucket, defaults to "images"
+	FileType    string // CMS file area, defaults to "images"; "auto" puts non-image files in "others"
+	Concurrency int    // parallel uploads in Batch, defaults to DefaultConcurrency
+	Environment string // VTEX environment of the account: "stable" (default) or "beta"
+
+	// VerifyUploads downloads each uploaded file and fails the upload with
+	// ErrVerifyFailed when it differs from the local file
+	VerifyUploads bool
+
+	// Transport, when set, sends every request instead of the default pooled
+	// transport, e.g. to test against an httptest server or add instrumentation.
+	// Retries still apply.
+	Transport http.RoundTripper
+}
+
+// Result is the outcome of a single upload
+type Result = client.UploadResult
+
+// LogEntry is an upload recorded in the local upload log
+type LogEntry = logger.UploadLogEntry
+
+// Client uploads files to one VTEX account. It is safe for concurrent use.
+type Client struct {
+	session       Session
+	opts          Options
+	authenticator *auth.Authenticator
+	clientOpts    []client.Option
+}
+
+// LoadSession returns the session of the VTEX CLI ('vtex login')
+func LoadSession() (Session, error) {
+	s, err := vtexcli.LoadSession()
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{Account: s.Account, Workspace: s.Workspace, Token: s.Token}, nil
+}
+
+// New creates a client uploading with the given session and options
+func New(session Session, opts Options) (*Client, error) {
+	if session.Account == "" {
+		return nil, fmt.Errorf("session has no account")
+	}
+	vs := vtexcli.VTEXSession{Account: session.Account, Workspace: session.Workspace, Token: session.Token, AppKey: session.AppKey, AppToken: session.AppToken}
+	if err := vs.ValidateToken(); err != nil {
+		return nil, err
+	}
+	if session.Workspace == "" {
+		session.Workspace = "master"
+	}
+
+	if opts.Method == "" {
+		opts.Method = MethodGraphQL
+	}
+	if opts.Method != MethodGraphQL && opts.Method != MethodCMS {
+		return nil, fmt.Errorf("invalid method: %s (must be 'graphql' or 'cms')", opts.Method)
+	}
+	if opts.Bucket == "" {
+		opts.Bucket = client.DefaultGraphQLBucket
+	}
+	if opts.FileType == "" {
+		opts.FileType = client.DefaultCMSFileType
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Environment != "" {
+		if err := client.ValidateEnvironment(opts.Environment); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Client{
+		session:       session,
+		opts:          opts,
+		authenticator: auth.NewAuthenticator(session.Token),
+	}
+	if session.AppKey != "" {
+		c.authenticator = auth.NewAppKeyAuthenticator(session.AppKey, session.AppToken)
+	}
+	if opts.Transport != nil {
+		// Built once so every upload shares the transport's connections
+		c.clientOpts = append(c.clientOpts, client.WithHTTPClient(client.NewHTTPClient(opts.Transport)))
+	}
+	if opts.Environment != "" {
+		// Kept on the client, so clients of other accounts or environments in
+		// the same process are not affected
+		c.clientOpts = append(c.clientOpts, client.WithEnvironment(opts.Environment))
+	}
+	return c, nil
+}
+
+// uploader returns an uploader for a single operation; CMS clients hold a
+// per-upload request token, so they are not shared between goroutines
+func (c *Client) uploader() client.ReaderUploader {
+	if c.opts.Method == MethodCMS {
+		cms := client.NewCMSFilePickerClient(c.session.Account, c.session.Workspace, c.authenticator, c.clientOpts...)
+		cms.SetFileType(c.opts.FileType)
+		return cms
+	}
+	graphql := client.NewGraphQLClient(c.session.Account, c.session.Workspace, c.authenticator, c.clientOpts...)
+	graphql.SetBucket(c.opts.Bucket)
+	return graphql
+}
+
+// Verify checks with VTEX ID that the session is still valid and returns the
+// authenticated user, or the App Key for App Key sessions. The error wraps
+// ErrAuthFailed when the session expired or the App Key was rejected.
+func (c *Client) Verify(ctx context.Context) (string, error) {
+	if c.session.AppKey != "" {
+		if err := client.VerifyAppKey(ctx, c.session.Account, c.session.AppKey, c.session.AppToken, c.clientOpts...); err != nil {
+			return "", err
+		}
+		return c.session.AppKey, nil
+	}
+	return client.VerifySession(ctx, c.session.Account, c.authenticator, c.clientOpts...)
+}
+
+// Upload uploads the file at path under its base name
+func (c *Client) Upload(ctx context.Context, path string) (*Result, error) {
+	return c.UploadAs(ctx, path, filepath.Base(path))
+}
+
+// UploadAs uploads the file at path under the given remote name. With
+// MethodGraphQL the name is only a hint; VTEX generates the final URL.
+func (c *Client) UploadAs(ctx context.Context, path, name string) (*Result, error) {
+	return c.uploader().Upload(ctx, client.UploadRequest{FilePath: path, FileName: name, Verify: c.opts.VerifyUploads})
+}
+
+// UploadReader uploads size bytes read from r under the given remote name,
+// without writing them to a file first. Pass a negative size when it is not
+// known in advance.
+func (c *Client) UploadReader(ctx context.Context, r io.Reader, name string, size int64) (*Result, error) {
+	return c.uploader().UploadReader(ctx, r, name, size)
+}
+
+// Batch uploads files in parallel and returns their results in the order of
+// paths. Failed uploads are reported in their Result; the returned error is
+// only set when ctx is cancelled, in which case files not uploaded yet have a
+// nil result.
+func (c *Client) Batch(ctx context.Context, paths []string) ([]*Result, error) {
+	results := make([]*Result, len(paths))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.opts.Concurrency && w < len(paths); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := c.Upload(ctx, paths[i])
+				if !errors.Is(err, context.Canceled) {
+					results[i] = result
+				}
+			}
+		}()
+	}
+
+send:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// Exists reports whether a file with the given name is already published.
+// Only MethodCMS keeps file names, so other methods return ErrUnsupported.
+func (c *Client) Exists(ctx context.Context, name string) (bool, error) {
+	if c.opts.Method != MethodCMS {
+		return false, ErrUnsupported
+	}
+	return c.uploader().Exists(ctx, name)
+}
+
+// Logs returns the uploads recorded locally for the client's account, oldest first
+func (c *Client) Logs() ([]LogEntry, error) {
+	entries, err := logger.ReadLogs()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []LogEntry
+	for _, entry := range entries {
+		if entry.Account == c.session.Account {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
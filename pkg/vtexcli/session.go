@@ -1,10 +1,13 @@
 package vtexcli
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // SessionData represents VTEX CLI session data from session.json
@@ -27,6 +30,56 @@ type VTEXSession struct {
 	Login     string
 	Token     string
 	Workspace string
+
+	// AppKey and AppToken are set instead of Token for App Key sessions
+	AppKey   string
+	AppToken string
+
+	// ExpiresAt is the expiry decoded from the token, zero when unknown
+	ExpiresAt time.Time
+}
+
+// tokenExpiry returns the "exp" claim of a JWT token, or the zero time when
+// the token is not a JWT or has no expiry
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// NewAppKeySession returns a session authenticated with a VTEX App Key and App Token
+// instead of a VTEX CLI login. The workspace defaults to master.
+func NewAppKeySession(account, workspace, appKey, appToken string) (*VTEXSession, error) {
+	if account == "" {
+		return nil, fmt.Errorf("an account is required when using an App Key (--account or VTEX_ACCOUNT)")
+	}
+	if appKey == "" || appToken == "" {
+		return nil, fmt.Errorf("both an App Key and an App Token are required")
+	}
+	if workspace == "" {
+		workspace = "master"
+	}
+
+	return &VTEXSession{
+		Account:   account,
+		Login:     appKey,
+		Workspace: workspace,
+		AppKey:    appKey,
+		AppToken:  appToken,
+	}, nil
 }
 
 // getVTEXSessionPath returns the path to VTEX CLI session directory
@@ -90,12 +143,73 @@ func LoadSession() (*VTEXSession, error) {
 		Login:     sessionData.Login,
 		Token:     sessionData.Token,
 		Workspace: workspaceData.CurrentWorkspace,
+		ExpiresAt: tokenExpiry(sessionData.Token),
 	}, nil
 }
 
+// LoadSessionForAccount loads the VTEX CLI session for account, which may differ
+// from the current one: the toolbelt keeps the tokens of every account logged
+// into in tokens.json, so no 'vtex switch' is needed. Sessions for other
+// accounts use the master workspace.
+func LoadSessionForAccount(account string) (*VTEXSession, error) {
+	session, err := LoadSession()
+	if err != nil {
+		return nil, err
+	}
+	if account == "" || account == session.Account {
+		return session, nil
+	}
+
+	tokens, err := loadTokenCache()
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[account]
+	if token == "" {
+		return nil, fmt.Errorf("no VTEX CLI token found for account %s. Please run 'vtex login %s' first", account, account)
+	}
+
+	return &VTEXSession{
+		Account:   account,
+		Login:     session.Login,
+		Token:     token,
+		Workspace: "master",
+		ExpiresAt: tokenExpiry(token),
+	}, nil
+}
+
+// loadTokenCache reads the tokens the VTEX CLI stores per account
+func loadTokenCache() (map[string]string, error) {
+	sessionPath, err := getVTEXSessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(sessionPath, "tokens.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file: %w", err)
+	}
+	return tokens, nil
+}
+
 // ValidateToken performs basic validation on the authentication token
 // Returns an error if the token appears to be invalid
 func (s *VTEXSession) ValidateToken() error {
+	if s.AppKey != "" {
+		if s.AppToken == "" {
+			return fmt.Errorf("no App Token found for App Key %s", s.AppKey)
+		}
+		return nil
+	}
+
 	if s.Token == "" {
 		return fmt.Errorf("no authentication token found")
 	}
@@ -106,5 +220,9 @@ func (s *VTEXSession) ValidateToken() error {
 		return fmt.Errorf("authentication token appears to be invalid (too short)")
 	}
 
+	if !s.ExpiresAt.IsZero() && time.Now().After(s.ExpiresAt) {
+		return fmt.Errorf("authentication token expired at %s", s.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+
 	return nil
 }